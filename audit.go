@@ -0,0 +1,12 @@
+package k8senv
+
+import "github.com/giantswarm/k8senv/internal/audit"
+
+// AuditEvent is a kube-apiserver audit event (audit.k8s.io/v1 Event) as
+// returned by Instance.AuditEvents. Fields mirror the upstream type; the
+// request and response objects are kept as raw JSON.
+type AuditEvent = audit.Event
+
+// AuditObjectReference identifies the object an AuditEvent refers to
+// (audit.k8s.io/v1 ObjectReference).
+type AuditObjectReference = audit.ObjectReference
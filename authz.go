@@ -0,0 +1,30 @@
+package k8senv
+
+import (
+	"github.com/giantswarm/k8senv/internal/authz"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// SubjectAccessReview is the authorization.k8s.io/v1 request kube-apiserver
+// sends to the authorization webhook configured by WithAuthorizer.
+type SubjectAccessReview = authorizationv1.SubjectAccessReview
+
+// SubjectAccessReviewStatus is the decision returned for a
+// SubjectAccessReview. Set Allowed to allow the request, Denied to deny it,
+// or neither to express no opinion (the request is then allowed by the
+// AlwaysAllow authorizer that follows the webhook). Reason is reported to
+// the client on denial.
+type SubjectAccessReviewStatus = authorizationv1.SubjectAccessReviewStatus
+
+// AuthorizerFunc adapts a function to the authorization webhook protocol. It
+// implements http.Handler and can be passed to WithAuthorizer:
+//
+//	k8senv.WithAuthorizer(k8senv.AuthorizerFunc(func(r *k8senv.SubjectAccessReview) k8senv.SubjectAccessReviewStatus {
+//		if attrs := r.Spec.ResourceAttributes; attrs != nil && attrs.Resource == "secrets" {
+//			return k8senv.SubjectAccessReviewStatus{Denied: true, Reason: "secrets are off limits"}
+//		}
+//		return k8senv.SubjectAccessReviewStatus{} // no opinion
+//	}))
+//
+// The function is called concurrently from HTTP handler goroutines.
+type AuthorizerFunc = authz.Func
@@ -0,0 +1,37 @@
+package k8senv
+
+import (
+	"os/exec"
+
+	"github.com/giantswarm/k8senv/internal/binstore"
+	"github.com/giantswarm/k8senv/internal/core"
+)
+
+// withStoreBinaries returns cfg with kine and kube-apiserver taken from the
+// default binaries store (see package binaries) when they are left at their
+// default names and are not on $PATH. Binaries the store does not hold are
+// left as they are, so Initialize reports them as missing from $PATH.
+func withStoreBinaries(cfg core.ManagerConfig) core.ManagerConfig {
+	cfg.KineBinary = storeBinary(cfg.KineBinary, DefaultKineBinary, binstore.DefaultKineVersion)
+	cfg.KubeAPIServerBinary = storeBinary(
+		cfg.KubeAPIServerBinary, DefaultKubeAPIServerBinary, binstore.DefaultKubeAPIServerVersion)
+	return cfg
+}
+
+// storeBinary returns the path of the pinned version of the binary name in
+// the default store if binary is name and name is not on $PATH, and binary
+// otherwise.
+func storeBinary(binary, name, version string) string {
+	if binary != name {
+		return binary
+	}
+	if _, err := exec.LookPath(name); err == nil {
+		return binary
+	}
+	path, err := binstore.Path(binstore.DefaultDir(), name, version)
+	if err != nil {
+		return binary
+	}
+	core.Logger().Debug("using binary from store", "binary", name, "version", version, "path", path)
+	return path
+}
@@ -0,0 +1,38 @@
+// Package binaries installs pinned versions of kine and kube-apiserver into a
+// content-addressed local store, so every developer and CI job runs the same
+// binaries without a manual go install or curl.
+//
+// Binaries are fetched from a mirror: a base URL, either file:// or
+// http(s)://, that serves a manifest.json listing each artifact with its
+// name, version, platform, path and SHA-256 checksum:
+//
+//	{
+//	  "binaries": [
+//	    {
+//	      "name": "kube-apiserver",
+//	      "version": "v1.35.2",
+//	      "os": "linux",
+//	      "arch": "amd64",
+//	      "path": "v1.35.2/linux/amd64/kube-apiserver",
+//	      "sha256": "3b4c..."
+//	    }
+//	  ]
+//	}
+//
+// Store.Install downloads an artifact, verifies its checksum and stores it
+// under its digest. k8senv.NewManager uses DefaultKineVersion and
+// DefaultKubeAPIServerVersion from the default store when kine or
+// kube-apiserver is left at its default name and is not on $PATH, so
+// installing the pinned versions is all the setup needed. For other versions
+// or another store, Store.ManagerOptions turns installed versions into
+// k8senv.WithKineBinary and k8senv.WithKubeAPIServerBinary options:
+//
+//	store := binaries.NewStore("/opt/k8senv")
+//	opts, err := store.ManagerOptions("v0.14.11", "v1.34.1")
+//	if err != nil {
+//		log.Fatalf("k8senv binaries not installed (run k8senv install): %v", err)
+//	}
+//	mgr := k8senv.NewManager(opts...)
+//
+// The k8senv command (cmd/k8senv) installs binaries from the command line.
+package binaries
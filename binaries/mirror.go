@@ -0,0 +1,114 @@
+package binaries
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// ManifestFile is the name of the manifest at the root of a mirror.
+const ManifestFile = "manifest.json"
+
+// Manifest lists the artifacts a mirror serves.
+type Manifest struct {
+	Binaries []Artifact `json:"binaries"`
+}
+
+// Artifact is one binary served by a mirror.
+type Artifact struct {
+	// Name is the binary name, e.g. "kine" or "kube-apiserver".
+	Name string `json:"name"`
+	// Version is the version the binary reports, e.g. "v1.35.2".
+	Version string `json:"version"`
+	// OS and Arch are the platform in GOOS/GOARCH terms.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	// Path is the location of the binary relative to the mirror base URL.
+	Path string `json:"path"`
+	// SHA256 is the hex-encoded SHA-256 digest of the binary.
+	SHA256 string `json:"sha256"`
+}
+
+// Find returns the artifact for name at version built for the current
+// platform.
+//
+// Returns ErrNotInMirror if the manifest has no such artifact.
+func (m *Manifest) Find(name, version string) (Artifact, error) {
+	for _, a := range m.Binaries {
+		if a.Name == name && a.Version == version && a.OS == runtime.GOOS && a.Arch == runtime.GOARCH {
+			return a, nil
+		}
+	}
+	return Artifact{}, fmt.Errorf("%w: %s %s for %s/%s", ErrNotInMirror, name, version, runtime.GOOS, runtime.GOARCH)
+}
+
+// Mirror fetches a manifest and artifacts from a base URL. The zero Client
+// uses http.DefaultClient for http(s) URLs; file:// URLs are read directly.
+type Mirror struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// Manifest fetches and decodes the mirror's manifest.json.
+func (m Mirror) Manifest(ctx context.Context) (*Manifest, error) {
+	body, err := m.open(ctx, ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+
+	var manifest Manifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", ManifestFile, err)
+	}
+	return &manifest, nil
+}
+
+// open returns the content at rel, relative to the base URL. The caller
+// closes it.
+func (m Mirror) open(ctx context.Context, rel string) (io.ReadCloser, error) {
+	base, err := url.Parse(m.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse mirror URL %q: %w", m.BaseURL, err)
+	}
+	if path.IsAbs(rel) || strings.Contains(rel, "..") {
+		return nil, fmt.Errorf("invalid artifact path %q", rel)
+	}
+	base.Path = path.Join(base.Path, rel)
+
+	switch base.Scheme {
+	case "file":
+		f, err := os.Open(base.Path)
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", base.Path, err)
+		}
+		return f, nil
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		client := m.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("get %s: %w", base, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("get %s: %s", base, resp.Status)
+		}
+		return resp.Body, nil
+	default:
+		return nil, fmt.Errorf("unsupported mirror URL scheme %q (want file, http or https)", base.Scheme)
+	}
+}
@@ -0,0 +1,221 @@
+package binaries
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/internal/binstore"
+	"github.com/giantswarm/k8senv/internal/sentinel"
+)
+
+// Binary names installed by the store.
+const (
+	Kine          = binstore.Kine
+	KubeAPIServer = binstore.KubeAPIServer
+)
+
+// Versions pinned by this release of k8senv, installed by default by the
+// k8senv command and used by k8senv.NewManager when kine or kube-apiserver
+// is not on $PATH.
+const (
+	DefaultKineVersion          = binstore.DefaultKineVersion
+	DefaultKubeAPIServerVersion = binstore.DefaultKubeAPIServerVersion
+)
+
+// Environment variables read by the k8senv command and NewStore.
+const (
+	// StoreDirEnv overrides the default store directory.
+	StoreDirEnv = binstore.DirEnv
+
+	// MirrorEnv is the default mirror base URL of the k8senv command.
+	MirrorEnv = "K8SENV_MIRROR"
+)
+
+// ErrNotInstalled is returned when a binary version is not in the store.
+const ErrNotInstalled = binstore.ErrNotInstalled
+
+// ErrInvalidVersion is returned for a version that is empty or contains a
+// path separator or "..".
+const ErrInvalidVersion = binstore.ErrInvalidVersion
+
+// ErrNotInMirror is returned when a mirror's manifest lists no artifact for
+// the requested binary, version and platform.
+const ErrNotInMirror = sentinel.Error("binary not in mirror")
+
+// ErrChecksumMismatch is returned when a downloaded binary does not match the
+// SHA-256 digest in the manifest. Nothing is stored in that case.
+const ErrChecksumMismatch = sentinel.Error("checksum mismatch")
+
+// Store is a content-addressed directory of binaries. Each binary is stored
+// once under its digest, at sha256/<digest>/<name>, and refs/<name>/<version>/
+// <os>-<arch> holds the digest installed for a version, so different
+// versions, and the same version from different mirrors, never overwrite one
+// another.
+type Store struct {
+	Dir string
+}
+
+// NewStore returns the store in dir. An empty dir selects $K8SENV_STORE_DIR
+// if set, and otherwise k8senv/binaries in the user cache directory (e.g.
+// ~/.cache/k8senv/binaries on Linux).
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultStoreDir()
+	}
+	return &Store{Dir: dir}
+}
+
+// DefaultStoreDir returns the store directory NewStore uses for an empty dir.
+func DefaultStoreDir() string {
+	return binstore.DefaultDir()
+}
+
+// Path returns the path of the installed binary name at version for the
+// current platform.
+//
+// Returns ErrInvalidVersion if version is not a valid path element, and
+// ErrNotInstalled if it has not been installed.
+func (s *Store) Path(name, version string) (string, error) {
+	return binstore.Path(s.Dir, name, version)
+}
+
+// Install installs binary name at version from the mirror, unless the store
+// already holds it with the digest the manifest lists, and returns its path.
+// The download is written to a temporary file, verified against the
+// manifest digest and then renamed into place, so an interrupted or
+// corrupted download never leaves a usable binary behind.
+//
+// Returns ErrInvalidVersion if version is not a valid path element,
+// ErrNotInMirror if the manifest lists no such artifact for the current
+// platform, and ErrChecksumMismatch if the download does not match.
+func (s *Store) Install(ctx context.Context, mirror Mirror, name, version string) (string, error) {
+	if _, err := binstore.RefPath(s.Dir, name, version); err != nil {
+		return "", err
+	}
+	manifest, err := mirror.Manifest(ctx)
+	if err != nil {
+		return "", err
+	}
+	artifact, err := manifest.Find(name, version)
+	if err != nil {
+		return "", err
+	}
+	return s.install(ctx, mirror, artifact)
+}
+
+// install stores artifact and points its ref at it.
+func (s *Store) install(ctx context.Context, mirror Mirror, artifact Artifact) (string, error) {
+	digest := strings.ToLower(artifact.SHA256)
+	if sum, err := hex.DecodeString(digest); err != nil || len(sum) != sha256.Size {
+		return "", fmt.Errorf("invalid sha256 %q for %s %s", artifact.SHA256, artifact.Name, artifact.Version)
+	}
+	ref, err := binstore.RefPath(s.Dir, artifact.Name, artifact.Version)
+	if err != nil {
+		return "", err
+	}
+	path := binstore.ObjectPath(s.Dir, digest, artifact.Name)
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := s.download(ctx, mirror, artifact, digest, path); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	if err := writeFileAtomic(ref, []byte(digest+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("write ref for %s %s: %w", artifact.Name, artifact.Version, err)
+	}
+	return path, nil
+}
+
+// download fetches artifact into path, verifying it against digest.
+func (s *Store) download(ctx context.Context, mirror Mirror, artifact Artifact, digest, path string) error {
+	body, err := mirror.open(ctx, artifact.Path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create store directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+artifact.Name+"-*")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	h := sha256.New()
+	_, copyErr := io.Copy(io.MultiWriter(tmp, h), body)
+	closeErr := tmp.Close()
+	if copyErr != nil {
+		return fmt.Errorf("download %s %s: %w", artifact.Name, artifact.Version, copyErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("write %s %s: %w", artifact.Name, artifact.Version, closeErr)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != digest {
+		return fmt.Errorf("%w for %s %s: got %s, want %s", ErrChecksumMismatch, artifact.Name, artifact.Version, got, digest)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil { //nolint:gosec // G302: stored binaries must be executable
+		return fmt.Errorf("make %s executable: %w", artifact.Name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("store %s %s: %w", artifact.Name, artifact.Version, err)
+	}
+	return nil
+}
+
+// ManagerOptions returns WithKineBinary and WithKubeAPIServerBinary options
+// pointing at the installed kine and kube-apiserver versions. Use it for
+// other versions or another store; k8senv.NewManager already falls back to
+// the pinned versions in the default store.
+//
+// Returns ErrNotInstalled if either version has not been installed.
+func (s *Store) ManagerOptions(kineVersion, kubeAPIServerVersion string) ([]k8senv.ManagerOption, error) {
+	kine, err := s.Path(Kine, kineVersion)
+	if err != nil {
+		return nil, err
+	}
+	apiserver, err := s.Path(KubeAPIServer, kubeAPIServerVersion)
+	if err != nil {
+		return nil, err
+	}
+	return []k8senv.ManagerOption{
+		k8senv.WithKineBinary(kine),
+		k8senv.WithKubeAPIServerBinary(apiserver),
+	}, nil
+}
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory, so readers, and concurrent installs, see either the old or the
+// new content.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, writeErr := tmp.Write(data)
+	if err := errors.Join(writeErr, tmp.Close()); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
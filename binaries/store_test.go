@@ -0,0 +1,315 @@
+package binaries
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// testMirror writes a mirror serving the given binaries (name -> version ->
+// content) for the current platform into a temporary directory and returns
+// its directory.
+func testMirror(t *testing.T, contents map[string]map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	var manifest Manifest
+	for name, versions := range contents {
+		for version, content := range versions {
+			rel := filepath.ToSlash(filepath.Join(version, runtime.GOOS+"-"+runtime.GOARCH, name))
+			path := filepath.Join(dir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			sum := sha256.Sum256([]byte(content))
+			manifest.Binaries = append(manifest.Binaries, Artifact{
+				Name:    name,
+				Version: version,
+				OS:      runtime.GOOS,
+				Arch:    runtime.GOARCH,
+				Path:    rel,
+				SHA256:  hex.EncodeToString(sum[:]),
+			})
+		}
+	}
+	writeManifestFile(t, dir, &manifest)
+	return dir
+}
+
+func writeManifestFile(t *testing.T, dir string, manifest *Manifest) {
+	t.Helper()
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fileMirror(dir string) Mirror {
+	return Mirror{BaseURL: "file://" + filepath.ToSlash(dir)}
+}
+
+func TestInstall_FileMirror(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{
+		KubeAPIServer: {"v1.34.1": "apiserver-134", "v1.35.2": "apiserver-135"},
+	})
+	store := NewStore(t.TempDir())
+
+	for _, version := range []string{"v1.34.1", "v1.35.2"} {
+		path, err := store.Install(t.Context(), fileMirror(dir), KubeAPIServer, version)
+		if err != nil {
+			t.Fatalf("Install(%s): %v", version, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&0o100 == 0 {
+			t.Errorf("%s is not executable: %v", path, info.Mode())
+		}
+		got, err := store.Path(KubeAPIServer, version)
+		if err != nil {
+			t.Fatalf("Path(%s): %v", version, err)
+		}
+		if got != path {
+			t.Errorf("Path(%s) = %q, want %q", version, got, path)
+		}
+	}
+
+	a, _ := store.Path(KubeAPIServer, "v1.34.1")
+	b, _ := store.Path(KubeAPIServer, "v1.35.2")
+	if a == b {
+		t.Errorf("versions share path %q", a)
+	}
+	data, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "apiserver-135" {
+		t.Errorf("v1.35.2 content = %q", data)
+	}
+}
+
+func TestInstall_HTTPMirror(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{Kine: {"v0.14.12": "kine"}})
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(srv.Close)
+
+	store := NewStore(t.TempDir())
+	if _, err := store.Install(t.Context(), Mirror{BaseURL: srv.URL, Client: srv.Client()}, Kine, "v0.14.12"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Path(Kine, "v0.14.12"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstall_ChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{Kine: {"v0.14.12": "kine"}})
+	// Corrupt the binary after its digest went into the manifest.
+	rel := filepath.Join("v0.14.12", runtime.GOOS+"-"+runtime.GOARCH, Kine)
+	if err := os.WriteFile(filepath.Join(dir, rel), []byte("tampered"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	storeDir := t.TempDir()
+	store := NewStore(storeDir)
+	_, err := store.Install(t.Context(), fileMirror(dir), Kine, "v0.14.12")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Install error = %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := store.Path(Kine, "v0.14.12"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Path error = %v, want ErrNotInstalled", err)
+	}
+
+	var leftovers []string
+	err = filepath.WalkDir(storeDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			leftovers = append(leftovers, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leftovers) != 0 {
+		t.Errorf("store holds files after failed install: %v", leftovers)
+	}
+}
+
+func TestInstall_NotInMirror(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{Kine: {"v0.14.12": "kine"}})
+	store := NewStore(t.TempDir())
+
+	tests := map[string][2]string{
+		"unknown version": {Kine, "v0.0.1"},
+		"unknown binary":  {KubeAPIServer, "v0.14.12"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := store.Install(t.Context(), fileMirror(dir), tt[0], tt[1])
+			if !errors.Is(err, ErrNotInMirror) {
+				t.Errorf("Install error = %v, want ErrNotInMirror", err)
+			}
+		})
+	}
+}
+
+func TestInstall_OtherPlatformOnly(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeManifestFile(t, dir, &Manifest{Binaries: []Artifact{{
+		Name:    Kine,
+		Version: "v0.14.12",
+		OS:      "plan9",
+		Arch:    runtime.GOARCH,
+		Path:    "kine",
+		SHA256:  hex.EncodeToString(make([]byte, sha256.Size)),
+	}}})
+
+	_, err := NewStore(t.TempDir()).Install(t.Context(), fileMirror(dir), Kine, "v0.14.12")
+	if !errors.Is(err, ErrNotInMirror) {
+		t.Errorf("Install error = %v, want ErrNotInMirror", err)
+	}
+}
+
+func TestInstall_Idempotent(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{Kine: {"v0.14.12": "kine"}})
+	store := NewStore(t.TempDir())
+
+	first, err := store.Install(t.Context(), fileMirror(dir), Kine, "v0.14.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Remove the mirror's binary: a reinstall must not download it again.
+	rel := filepath.Join("v0.14.12", runtime.GOOS+"-"+runtime.GOARCH, Kine)
+	if err := os.Remove(filepath.Join(dir, rel)); err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Install(t.Context(), fileMirror(dir), Kine, "v0.14.12")
+	if err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	if first != second {
+		t.Errorf("reinstall path = %q, want %q", second, first)
+	}
+}
+
+func TestMirror_RejectsEscapingPaths(t *testing.T) {
+	t.Parallel()
+
+	m := fileMirror(t.TempDir())
+	for _, rel := range []string{"/etc/passwd", "../outside", "a/../../b"} {
+		if _, err := m.open(context.Background(), rel); err == nil {
+			t.Errorf("open(%q) succeeded, want error", rel)
+		}
+	}
+}
+
+func TestMirror_UnsupportedScheme(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (Mirror{BaseURL: "ftp://example.com"}).Manifest(t.Context()); err == nil {
+		t.Error("Manifest succeeded for ftp URL, want error")
+	}
+}
+
+func TestMirror_HTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	if _, err := (Mirror{BaseURL: srv.URL, Client: srv.Client()}).Manifest(t.Context()); err == nil {
+		t.Error("Manifest succeeded for 404, want error")
+	}
+}
+
+func TestManagerOptions(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{
+		Kine:          {"v0.14.12": "kine"},
+		KubeAPIServer: {"v1.35.2": "apiserver"},
+	})
+	store := NewStore(t.TempDir())
+
+	if _, err := store.ManagerOptions("v0.14.12", "v1.35.2"); !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("ManagerOptions before install error = %v, want ErrNotInstalled", err)
+	}
+	for name, version := range map[string]string{Kine: "v0.14.12", KubeAPIServer: "v1.35.2"} {
+		if _, err := store.Install(t.Context(), fileMirror(dir), name, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts, err := store.ManagerOptions("v0.14.12", "v1.35.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 2 {
+		t.Errorf("got %d options, want 2", len(opts))
+	}
+}
+
+func TestNewStore_Default(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(StoreDirEnv, dir)
+
+	if got := NewStore("").Dir; got != dir {
+		t.Errorf("NewStore(\"\").Dir = %q, want %q", got, dir)
+	}
+	if got := NewStore("/explicit").Dir; got != "/explicit" {
+		t.Errorf("NewStore(\"/explicit\").Dir = %q", got)
+	}
+}
+
+func TestStore_RejectsInvalidVersions(t *testing.T) {
+	t.Parallel()
+
+	dir := testMirror(t, map[string]map[string]string{KubeAPIServer: {"v1.35.2": "apiserver"}})
+	store := NewStore(t.TempDir())
+	for _, version := range []string{"", "..", "../../etc", "v1/../x", `v1\x`, "v1.35.2/.."} {
+		if _, err := store.Path(KubeAPIServer, version); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("Path(%q) error = %v, want ErrInvalidVersion", version, err)
+		}
+		if _, err := store.Install(t.Context(), fileMirror(dir), KubeAPIServer, version); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("Install(%q) error = %v, want ErrInvalidVersion", version, err)
+		}
+	}
+}
+
+func TestInstall_RejectsInvalidManifestVersion(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte("x"))
+	store := NewStore(t.TempDir())
+	if _, err := store.install(t.Context(), fileMirror(t.TempDir()), Artifact{
+		Name: Kine, Version: "../escape", Path: "kine", SHA256: hex.EncodeToString(sum[:]),
+	}); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("install error = %v, want ErrInvalidVersion", err)
+	}
+}
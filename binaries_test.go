@@ -0,0 +1,69 @@
+package k8senv_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/binaries"
+)
+
+// installFake writes a fake binary name at version into the store in dir,
+// in the layout binaries.Store uses, and returns its path.
+func installFake(t *testing.T, dir, name, version string) string {
+	t.Helper()
+	const digest = "0000000000000000000000000000000000000000000000000000000000000000"
+	ref := filepath.Join(dir, "refs", name, version, runtime.GOOS+"-"+runtime.GOARCH)
+	path := filepath.Join(dir, "sha256", digest, name)
+	for file, data := range map[string]string{ref: digest + "\n", path: "#!/bin/sh\n"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestStoreBinaryFallback(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(binaries.StoreDirEnv, dir)
+	t.Setenv("PATH", t.TempDir())
+
+	kine := installFake(t, dir, binaries.Kine, binaries.DefaultKineVersion)
+
+	cfg := k8senv.WithStoreBinariesForTesting(k8senv.ApplyOptionsForTesting())
+	if cfg.KineBinary != kine {
+		t.Errorf("KineBinary = %q, want store path %q", cfg.KineBinary, kine)
+	}
+	// Not installed: left at the default name.
+	if cfg.KubeAPIServerBinary != k8senv.DefaultKubeAPIServerBinary {
+		t.Errorf("KubeAPIServerBinary = %q, want %q", cfg.KubeAPIServerBinary, k8senv.DefaultKubeAPIServerBinary)
+	}
+
+	// Explicit paths are never replaced.
+	cfg = k8senv.WithStoreBinariesForTesting(k8senv.ApplyOptionsForTesting(k8senv.WithKineBinary("/opt/kine")))
+	if cfg.KineBinary != "/opt/kine" {
+		t.Errorf("explicit KineBinary = %q, want /opt/kine", cfg.KineBinary)
+	}
+}
+
+func TestStoreBinaryPrefersPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(binaries.StoreDirEnv, dir)
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	installFake(t, dir, binaries.Kine, binaries.DefaultKineVersion)
+	if err := os.WriteFile(filepath.Join(bin, k8senv.DefaultKineBinary), []byte("#!/bin/sh\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := k8senv.WithStoreBinariesForTesting(k8senv.ApplyOptionsForTesting())
+	if cfg.KineBinary != k8senv.DefaultKineBinary {
+		t.Errorf("KineBinary = %q, want %q from $PATH", cfg.KineBinary, k8senv.DefaultKineBinary)
+	}
+}
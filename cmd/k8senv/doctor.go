@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/giantswarm/k8senv"
+)
+
+// runDoctor implements "k8senv doctor": it runs Manager.Preflight with the
+// binaries, data directory and pool size given by flags and prints the
+// report. It exits 1 if any check failed; warnings alone exit 0.
+func runDoctor(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	kine := fs.String("kine", k8senv.DefaultKineBinary, "kine binary name or path")
+	apiserver := fs.String("kube-apiserver", k8senv.DefaultKubeAPIServerBinary, "kube-apiserver binary name or path")
+	dataDir := fs.String("data-dir", "", "base data directory (default the k8senv directory in the system temp directory)")
+	poolSize := fs.Int("pool-size", k8senv.DefaultPoolSize, "pool size to check resource limits for")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *poolSize < 0 {
+		fmt.Fprintln(stderr, "k8senv doctor: -pool-size must not be negative")
+		return 2
+	}
+
+	opts := []k8senv.ManagerOption{
+		k8senv.WithKineBinary(*kine),
+		k8senv.WithKubeAPIServerBinary(*apiserver),
+		k8senv.WithPoolSize(*poolSize),
+	}
+	if *dataDir != "" {
+		opts = append(opts, k8senv.WithBaseDataDir(*dataDir))
+	}
+	mgr := k8senv.NewManager(opts...)
+	defer func() { _ = mgr.Shutdown() }()
+
+	report := mgr.Preflight(ctx)
+	if _, err := report.WriteTo(stdout); err != nil {
+		fmt.Fprintf(stderr, "k8senv doctor: %v\n", err)
+		return 1
+	}
+	if report.Failed() {
+		fmt.Fprintln(stderr, "k8senv doctor: some checks failed")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/k8senv/binaries"
+)
+
+// runInstall implements "k8senv install".
+func runInstall(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mirror := fs.String("mirror", os.Getenv(binaries.MirrorEnv),
+		"mirror base URL, file:// or http(s):// (default $"+binaries.MirrorEnv+")")
+	storeDir := fs.String("store", "", "binary store directory (default $"+binaries.StoreDirEnv+
+		" or "+binaries.DefaultStoreDir()+")")
+	kineVersion := fs.String("kine", binaries.DefaultKineVersion, "kine version")
+	apiVersions := fs.String("kube-apiserver", binaries.DefaultKubeAPIServerVersion,
+		"kube-apiserver version, or a comma-separated list of versions")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *mirror == "" {
+		fmt.Fprintf(stderr, "k8senv install: no mirror: set -mirror or $%s\n", binaries.MirrorEnv)
+		return 2
+	}
+
+	store := binaries.NewStore(*storeDir)
+	src := binaries.Mirror{BaseURL: *mirror}
+	wanted := [][2]string{{binaries.Kine, *kineVersion}}
+	for v := range strings.SplitSeq(*apiVersions, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			wanted = append(wanted, [2]string{binaries.KubeAPIServer, v})
+		}
+	}
+
+	failed := false
+	for _, w := range wanted {
+		path, err := store.Install(ctx, src, w[0], w[1])
+		if err != nil {
+			fmt.Fprintf(stderr, "k8senv install: %v\n", err)
+			failed = true
+			continue
+		}
+		fmt.Fprintf(stdout, "%s %s: %s\n", w[0], w[1], path)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
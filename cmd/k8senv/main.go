@@ -0,0 +1,60 @@
+// Command k8senv manages the binaries k8senv runs.
+//
+// Usage:
+//
+//	k8senv install [-mirror URL] [-store DIR] [-kine VERSION] [-kube-apiserver VERSION[,VERSION...]]
+//	k8senv doctor [-kine PATH] [-kube-apiserver PATH] [-data-dir DIR] [-pool-size N]
+//
+// install downloads kine and kube-apiserver from a mirror (see package
+// binaries for its layout), verifies their SHA-256 checksums and stores them
+// in the local binary store, printing the installed paths.
+//
+// doctor runs the same checks as Manager.Preflight and prints a report, so a
+// new machine can be checked before any test runs. It exits 1 if a check
+// failed.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// usage is printed for missing or unknown subcommands.
+const usage = `usage: k8senv <command> [flags]
+
+commands:
+  install   install kine and kube-apiserver into the local binary store
+  doctor    check that this host can run k8senv instances
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the subcommand in args and returns the process exit code:
+// 0 on success, 1 if the command failed and 2 on a usage error.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "install":
+		return runInstall(ctx, args[1:], stdout, stderr)
+	case "doctor":
+		return runDoctor(ctx, args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "k8senv: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/binaries"
+)
+
+func TestRun_Usage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args []string
+		want int
+	}{
+		"no command":      {nil, 2},
+		"unknown command": {[]string{"frobnicate"}, 2},
+		"help":            {[]string{"help"}, 0},
+		"negative pool":   {[]string{"doctor", "-pool-size", "-1"}, 2},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			if got := run(t.Context(), tt.args, &stdout, &stderr); got != tt.want {
+				t.Errorf("run(%v) = %d, want %d; stderr: %s", tt.args, got, tt.want, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_Install(t *testing.T) {
+	t.Setenv(binaries.MirrorEnv, "")
+
+	mirror := t.TempDir()
+	var manifest binaries.Manifest
+	for name, version := range map[string]string{binaries.Kine: "v0.1.0", binaries.KubeAPIServer: "v1.35.0"} {
+		content := []byte(name + " " + version)
+		if err := os.WriteFile(filepath.Join(mirror, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(content)
+		manifest.Binaries = append(manifest.Binaries, binaries.Artifact{
+			Name: name, Version: version, OS: runtime.GOOS, Arch: runtime.GOARCH,
+			Path: name, SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mirror, binaries.ManifestFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), []string{
+		"install", "-mirror", "file://" + filepath.ToSlash(mirror), "-store", store,
+		"-kine", "v0.1.0", "-kube-apiserver", "v1.35.0",
+	}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("install exited %d; stderr: %s", code, stderr.String())
+	}
+	if lines := strings.Count(stdout.String(), "\n"); lines != 2 {
+		t.Errorf("install printed %d lines, want 2:\n%s", lines, stdout.String())
+	}
+	if _, err := binaries.NewStore(store).ManagerOptions("v0.1.0", "v1.35.0"); err != nil {
+		t.Errorf("ManagerOptions after install: %v", err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run(t.Context(), []string{
+		"install", "-mirror", "file://" + filepath.ToSlash(mirror), "-store", store,
+		"-kine", "v0.1.0", "-kube-apiserver", "v9.9.9",
+	}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("install of unknown version exited %d, want 1", code)
+	}
+
+	if code := run(t.Context(), []string{"install", "-store", store}, &stdout, &stderr); code != 2 {
+		t.Errorf("install without mirror exited %d, want 2", code)
+	}
+}
+
+func TestRun_Doctor(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	kine := filepath.Join(dir, "kine")
+	apiserver := filepath.Join(dir, "kube-apiserver")
+	for path, output := range map[string]string{kine: "kine version v0.14.12", apiserver: "Kubernetes v1.35.2"} {
+		script := "#!/bin/sh\necho '" + output + "'\n"
+		if err := os.WriteFile(path, []byte(script), 0o700); err != nil { //nolint:gosec // G306: test script must be executable
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), []string{
+		"doctor", "-kine", kine, "-kube-apiserver", apiserver, "-data-dir", filepath.Join(dir, "data"), "-pool-size", "1",
+	}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("doctor exited %d; stdout:\n%s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+	for _, want := range []string{"[ok  ] kine binary: " + kine, "[ok  ] kube-apiserver version: v1.35.2", "loopback ports"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, stdout.String())
+		}
+	}
+}
@@ -19,6 +19,10 @@ const (
 	// kube-apiserver in PATH.
 	DefaultKubeAPIServerBinary = "kube-apiserver"
 
+	// DefaultControllerManagerBinary is the binary name used to locate
+	// kube-controller-manager in PATH, for use with WithControllerManager.
+	DefaultControllerManagerBinary = "kube-controller-manager"
+
 	// DefaultAcquireTimeout is the total time allowed for pool acquisition
 	// and instance startup. Under pool contention, increase this to account
 	// for both wait time and startup (~5-15 seconds).
@@ -44,9 +48,29 @@ const (
 	// DefaultShutdownDrainTimeout is the maximum time Shutdown() waits
 	// for in-flight ReleaseToPool operations to complete before proceeding.
 	DefaultShutdownDrainTimeout = 30 * time.Second
+
+	// DefaultMaxKeptInstances is the number of failed tests' instances kept
+	// running by WithKeepOnFailure. Each kept instance holds a kine and a
+	// kube-apiserver process until Shutdown.
+	DefaultMaxKeptInstances = 1
+
+	// DefaultAPIServerVerbosity is the klog verbosity (--v) kube-apiserver
+	// runs with.
+	DefaultAPIServerVerbosity = 2
 )
 
+// BinDirEnv is the environment variable naming the directory of versioned
+// binaries used by WithKubeAPIServerVersions: the kube-apiserver of version
+// v is expected at $K8SENV_BIN_DIR/<v>/kube-apiserver.
+const BinDirEnv = "K8SENV_BIN_DIR"
+
 // defaultBaseDataDirName is the directory name under the system temp directory
 // where instance data is stored. Not exported because it is not directly usable
 // with WithBaseDataDir (the full path is filepath.Join(os.TempDir(), this)).
 const defaultBaseDataDirName = "k8senv"
+
+// defaultPortLockDirName is the directory under the default base data
+// directory holding the port lock files shared by all k8senv processes. It
+// does not follow WithBaseDataDir, so processes with different data
+// directories still coordinate.
+const defaultPortLockDirName = "ports"
@@ -25,4 +25,53 @@ const (
 	// on the same acquisition. After the first Release returns the instance to the
 	// pool, subsequent calls return this error instead of performing any action.
 	ErrDoubleRelease = core.ErrDoubleRelease
+
+	// ErrAuditNotEnabled is returned by Instance.AuditEvents when the manager
+	// was created without WithAuditPolicy or WithAuditPolicyObject.
+	ErrAuditNotEnabled = core.ErrAuditNotEnabled
+
+	// ErrJWTNotEnabled is returned by Instance.ConfigForJWTClaims when the
+	// manager was created without WithJWTAuthenticator.
+	ErrJWTNotEnabled = core.ErrJWTNotEnabled
+
+	// ErrUnknownVersion is returned by AcquireVersion for a kube-apiserver
+	// version that was not configured with WithKubeAPIServerVersions.
+	ErrUnknownVersion = core.ErrUnknownVersion
+
+	// ErrPreflightFailed is wrapped by PreflightReport.Err for every
+	// failed preflight check.
+	ErrPreflightFailed = core.ErrPreflightFailed
+
+	// ErrSystemNamespace is returned by Instance.ServiceAccountConfig for a
+	// system namespace such as "default", which Release does not purge.
+	ErrSystemNamespace = core.ErrSystemNamespace
+
+	// ErrNetworkNamespaceUnsupported is returned by Initialize when
+	// WithNetworkNamespace is used on a platform other than Linux.
+	ErrNetworkNamespaceUnsupported = core.ErrNetworkNamespaceUnsupported
+)
+
+// DiagnosticsError is returned, wrapping the underlying error, when an
+// instance fails to start or cannot be purged on release. Dir is the
+// diagnostics bundle written for the failure; see WithArtifactsDir.
+// Retrieve it with errors.As.
+type DiagnosticsError = core.DiagnosticsError
+
+// StartError is returned, possibly wrapped in a *DiagnosticsError, when an
+// instance fails to start. It reports the failed phase, the number of
+// attempts, and the exit code, port and stderr tail of the failed process.
+// Retrieve it with errors.As.
+type StartError = core.StartError
+
+// StartPhase identifies the step of instance startup that failed.
+type StartPhase = core.StartPhase
+
+// Startup phases reported in StartError.Phase.
+const (
+	PhaseBinaryLookup           = core.PhaseBinaryLookup
+	PhaseSetup                  = core.PhaseSetup
+	PhaseKineReady              = core.PhaseKineReady
+	PhaseAPIServerReady         = core.PhaseAPIServerReady
+	PhaseControllerManagerReady = core.PhaseControllerManagerReady
+	PhaseSystemNamespaces       = core.PhaseSystemNamespaces
 )
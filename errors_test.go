@@ -15,10 +15,16 @@ var allSentinelErrors = []struct {
 	name string
 	err  error
 }{
+	{"ErrAuditNotEnabled", k8senv.ErrAuditNotEnabled},
 	{"ErrDoubleRelease", k8senv.ErrDoubleRelease},
 	{"ErrInstanceReleased", k8senv.ErrInstanceReleased},
+	{"ErrJWTNotEnabled", k8senv.ErrJWTNotEnabled},
+	{"ErrNetworkNamespaceUnsupported", k8senv.ErrNetworkNamespaceUnsupported},
 	{"ErrNotInitialized", k8senv.ErrNotInitialized},
+	{"ErrPreflightFailed", k8senv.ErrPreflightFailed},
 	{"ErrShuttingDown", k8senv.ErrShuttingDown},
+	{"ErrSystemNamespace", k8senv.ErrSystemNamespace},
+	{"ErrUnknownVersion", k8senv.ErrUnknownVersion},
 }
 
 // TestPublicErrorConstants verifies that every exported error constant:
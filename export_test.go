@@ -23,3 +23,15 @@ type ConfigSnapshot = core.ManagerConfig
 func ApplyOptionsForTesting(opts ...ManagerOption) ConfigSnapshot {
 	return applyOptions(opts).ManagerConfig
 }
+
+// WithStoreBinariesForTesting resolves the binaries of cfg from the default
+// binaries store as NewManager does.
+func WithStoreBinariesForTesting(cfg ConfigSnapshot) ConfigSnapshot {
+	return withStoreBinaries(cfg)
+}
+
+// ConfigDiffsForTesting returns the differences NewManager would report
+// between a singleton created with stored and a later call with incoming.
+func ConfigDiffsForTesting(stored, incoming []ManagerOption) []string {
+	return configDiffs(applyOptions(stored), applyOptions(incoming))
+}
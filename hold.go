@@ -0,0 +1,39 @@
+package k8senv
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+)
+
+// holdKept is the -k8senv.hold test flag. When set, Manager.Shutdown waits
+// for an interrupt before stopping instances kept by WithKeepOnFailure.
+var holdKept = holdFlag()
+
+// holdFlag registers -k8senv.hold on the global flag set in test binaries.
+// Other programs importing k8senv keep their command line to themselves and
+// get a flag that is always false.
+func holdFlag() *bool {
+	if !testing.Testing() {
+		return new(bool)
+	}
+	return flag.Bool("k8senv.hold", false,
+		"wait for an interrupt in Shutdown before stopping instances kept by WithKeepOnFailure")
+}
+
+// holdKeptInstances blocks until SIGINT or SIGTERM if kept instances are
+// running, so they can be inspected after the tests have finished.
+func holdKeptInstances(kept int) {
+	if kept == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr,
+		"k8senv: holding %d kept instance(s) for inspection; press Ctrl-C to stop them\n", kept)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+}
@@ -2,7 +2,9 @@ package k8senv
 
 import (
 	"context"
+	"testing"
 
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/rest"
 )
 
@@ -21,6 +23,8 @@ type Manager interface {
 	// Safe to call multiple times: after a successful initialization,
 	// subsequent calls return nil immediately. If initialization fails,
 	// subsequent calls retry instead of returning a cached error permanently.
+	// With WithKubeAPIServerVersions, every version is initialized, and
+	// Initialize fails if any version fails.
 	Initialize(ctx context.Context) error
 
 	// Acquire gets an instance from the pool, creating one on demand if none
@@ -38,6 +42,81 @@ type Manager interface {
 	// Returns ErrShuttingDown if the manager is shutting down.
 	Acquire(ctx context.Context) (Instance, error)
 
+	// AcquireForTest acquires an instance for the duration of test t. It
+	// calls Acquire with t.Context(), fails the test with t.Fatal if that
+	// fails, and registers a t.Cleanup that releases the instance, reporting
+	// release errors with t.Error. Releasing the instance earlier is allowed.
+	//
+	// If t fails, the process output of the acquisition is attached to the
+	// test log, as with LogOnFailure. With WithKeepOnFailure, an instance
+	// released after t has failed is kept for inspection instead of being
+	// purged and returned to the pool.
+	AcquireForTest(t testing.TB) Instance
+
+	// AcquireVersion is Acquire for the kube-apiserver version configured
+	// under that name with WithKubeAPIServerVersions. Each version has its
+	// own pool.
+	//
+	// Returns ErrUnknownVersion if version is not configured, and otherwise
+	// the errors of Acquire.
+	AcquireVersion(ctx context.Context, version string) (Instance, error)
+
+	// ForEachVersion runs fn as a subtest of t, named after the version, for
+	// every kube-apiserver version configured with WithKubeAPIServerVersions,
+	// in ascending order. Each subtest gets an instance of its version
+	// acquired as by AcquireForTest:
+	//
+	//	mgr.ForEachVersion(t, func(t *testing.T, inst k8senv.Instance) {
+	//		cfg, err := inst.Config()
+	//		...
+	//	})
+	//
+	// Subtests run one after another unless fn calls t.Parallel. Without a
+	// version matrix, fn runs once, directly on t.
+	ForEachVersion(t *testing.T, fn func(t *testing.T, inst Instance))
+
+	// KubeAPIServerVersions returns the versions configured with
+	// WithKubeAPIServerVersions in ascending order, or nil without a version
+	// matrix.
+	KubeAPIServerVersions() []string
+
+	// WriteMergedKubeconfig writes a kubeconfig file at path with one
+	// context per running instance, named after the instance ID, for
+	// inspecting instances while debugging:
+	//
+	//	kubectl --kubeconfig merged.yaml --context inst-2-abcd get all -A
+	//
+	// Free instances that are still running are included; the current
+	// context is the instance with the lowest pool index. The file is a
+	// snapshot: instances started or restarted afterwards need a new call.
+	//
+	// Returns ErrNotInitialized if Initialize has not been called.
+	WriteMergedKubeconfig(path string) error
+
+	// Versions returns the kube-apiserver and kine versions detected during
+	// Initialize; with WithKubeAPIServerVersions, those of the newest
+	// version. The kube-apiserver version decides which flags and
+	// configuration file versions k8senv generates, so Initialize fails if
+	// it cannot be determined.
+	//
+	// Returns ErrNotInitialized if Initialize has not completed.
+	Versions() (Versions, error)
+
+	// Preflight checks, without starting anything, that the host can run
+	// the configured instances: binary presence and versions, kine and
+	// kube-apiserver compatibility, base data directory writability and
+	// free space, loopback port availability, the parent-death signal, and
+	// open file and process limits for the pool size. With
+	// WithKubeAPIServerVersions, every version's binary is checked. It may
+	// be called before Initialize, e.g. from TestMain to fail fast with
+	// every problem at once. It creates the base data directory if needed
+	// but otherwise changes nothing:
+	//
+	//	if err := mgr.Preflight(ctx).Err(); err != nil {
+	//		log.Fatal(err)
+	//	}
+	Preflight(ctx context.Context) *PreflightReport
+
 	// Shutdown stops all instances and cleans up.
 	// Safe to call even if Initialize was never called.
 	// Returns an error if any instance fails to stop.
@@ -51,11 +130,39 @@ type Instance interface {
 	// Config returns *rest.Config for connecting to this instance's kube-apiserver.
 	// It must be called while the instance is acquired (between Acquire and Release).
 	//
+	// The config verifies the server against a CA generated for each
+	// apiserver start (TLSClientConfig.CAData), and authenticates as a
+	// cluster admin (system:masters) with a bearer token, or with a client
+	// certificate when WithClientCertAuth is set.
+	//
 	// Returns ErrInstanceReleased if called after Release has completed.
 	// If Config and Release race, Config may succeed one final time; the
 	// underlying instance has its own generation guard as defense in depth.
 	Config() (*rest.Config, error)
 
+	// Kubeconfig returns the kubeconfig file for this instance, with the
+	// same server, CA and credentials as Config, for handing to kubectl,
+	// helm or other subprocesses through a file of their own.
+	//
+	// Returns ErrInstanceReleased if called after Release has completed.
+	Kubeconfig() ([]byte, error)
+
+	// KubeconfigPath returns the path of the kubeconfig file for this
+	// instance, in the instance data directory. The file has the same
+	// lifetime as Config: use it only between Acquire and Release, and do
+	// not modify it.
+	//
+	// Returns ErrInstanceReleased if called after Release has completed.
+	KubeconfigPath() (string, error)
+
+	// Logs returns the kine, kube-apiserver and (with WithControllerManager)
+	// kube-controller-manager output written since this instance was
+	// acquired, leaving out what earlier holders caused. To have it logged
+	// when a test fails, use AcquireForTest or LogOnFailure.
+	//
+	// Returns ErrInstanceReleased if called after Release has completed.
+	Logs() (LeaseLogs, error)
+
 	// Release returns the instance to the pool. Non-system namespace data
 	// is purged from kine's SQLite database, keeping the instance running
 	// for immediate reuse by the next Acquire.
@@ -65,9 +172,74 @@ type Instance interface {
 	// failed and removed from the pool. The error is informational: no
 	// corrective action is required.
 	//
+	// For an instance acquired with AcquireForTest under WithKeepOnFailure,
+	// Release after the test has failed keeps the instance running and
+	// unpurged instead, up to WithMaxKeptInstances instances.
+	//
 	// Returns ErrDoubleRelease if called more than once on the same acquisition.
 	Release() error
 
 	// ID returns a unique identifier for this instance.
 	ID() string
+
+	// ServerVersion returns the version information kube-apiserver serves
+	// at /version, as seen by clients built from Config.
+	//
+	// Returns ErrInstanceReleased if called after Release has completed.
+	ServerVersion(ctx context.Context) (*version.Info, error)
+
+	// AuditEvents returns the audit events kube-apiserver emitted since this
+	// instance was acquired, in delivery order. Which requests are recorded,
+	// and at what level, is governed by the policy passed to WithAuditPolicy
+	// or WithAuditPolicyObject.
+	// Events are delivered synchronously, so a request made through Config
+	// is recorded by the time the call returns.
+	//
+	// Returns ErrAuditNotEnabled if the manager has no audit policy, and
+	// ErrInstanceReleased if called after Release has completed.
+	AuditEvents() ([]AuditEvent, error)
+
+	// RawStorage returns the current storage record of every key in this
+	// instance's kine database, ordered by key. The SQLite file is read
+	// directly through a separate read-only connection, bypassing
+	// kube-apiserver, so values are exactly what the apiserver stored. Use it
+	// to check that resources are encrypted at rest (see
+	// WithEncryptionConfig) or to assert their storage encoding.
+	//
+	// Returns ErrInstanceReleased if called after Release has completed.
+	RawStorage(ctx context.Context) ([]StorageRecord, error)
+
+	// ConfigForJWTClaims returns a *rest.Config that authenticates with a JWT
+	// carrying claims, signed by the issuer started for WithJWTAuthenticator.
+	// "iss", "aud", "iat" and "exp" are filled in unless present in claims,
+	// so a typical call only sets the claims under test:
+	//
+	//	cfg, err := inst.ConfigForJWTClaims(map[string]any{
+	//		"sub":    "alice",
+	//		"groups": []string{"admins"},
+	//	})
+	//
+	// Requests made with the returned config are authenticated by
+	// kube-apiserver's JWT authenticator, exercising the configured claim
+	// mappings and validation rules. A rejected token surfaces as 401
+	// Unauthorized on the first request.
+	//
+	// Returns ErrJWTNotEnabled if the manager has no JWT authenticator, and
+	// ErrInstanceReleased if called after Release has completed.
+	ConfigForJWTClaims(claims map[string]any) (*rest.Config, error)
+
+	// ServiceAccountConfig returns a *rest.Config that authenticates as the
+	// ServiceAccount name in namespace ns, creating both if they do not
+	// exist. The token is issued by the TokenRequest API and signed with
+	// kube-apiserver's service account key, so the identity is the one a Pod
+	// would see: "system:serviceaccount:<ns>:<name>".
+	//
+	// With the default AlwaysAllow authorization every request is permitted;
+	// combine with WithAuthorizer to check what the ServiceAccount may do.
+	// ns must not be a system namespace such as "default": Release does not
+	// purge those, so the ServiceAccount would leak into later leases.
+	//
+	// Returns ErrSystemNamespace for a system namespace, and
+	// ErrInstanceReleased if called after Release has completed.
+	ServiceAccountConfig(ctx context.Context, ns, name string) (*rest.Config, error)
 }
@@ -0,0 +1,142 @@
+package apiserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// certLifetime is the validity of the generated CA and leaf certificates.
+// A fresh CA is generated on every start, so it only has to outlive one run.
+const certLifetime = 7 * 24 * time.Hour
+
+// adminClientCommonName and adminClientGroup identify the client certificate
+// issued for the kubeconfig. system:masters has cluster-admin rights, matching
+// the static bearer token.
+const (
+	adminClientCommonName = "admin"
+	adminClientGroup      = "system:masters"
+)
+
+// keyPair is a PEM-encoded certificate and its ECDSA private key.
+type keyPair struct {
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// certificateAuthority is the per-run CA that issues the serving certificate
+// and the admin client certificate.
+type certificateAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newCertificateAuthority generates a self-signed ECDSA P-256 CA.
+func newCertificateAuthority() (*certificateAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate CA key: %w", err)
+	}
+	tmpl, err := certTemplate("k8senv-ca")
+	if err != nil {
+		return nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse CA certificate: %w", err)
+	}
+	return &certificateAuthority{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// issueServing issues a serving certificate for 127.0.0.1 and localhost, the
+// addresses clients use to reach kube-apiserver.
+func (ca *certificateAuthority) issueServing() (keyPair, error) {
+	tmpl, err := certTemplate("kube-apiserver")
+	if err != nil {
+		return keyPair{}, err
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+	tmpl.DNSNames = []string{"localhost"}
+	return ca.issue(tmpl)
+}
+
+// issueClient issues a client certificate for commonName in groups
+// (the certificate's organizations, as interpreted by kube-apiserver).
+func (ca *certificateAuthority) issueClient(commonName string, groups ...string) (keyPair, error) {
+	tmpl, err := certTemplate(commonName)
+	if err != nil {
+		return keyPair{}, err
+	}
+	tmpl.Subject.Organization = groups
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	return ca.issue(tmpl)
+}
+
+// issue generates a key for tmpl and signs the certificate with the CA.
+func (ca *certificateAuthority) issue(tmpl *x509.Certificate) (keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return keyPair{}, fmt.Errorf("generate key for %s: %w", tmpl.Subject.CommonName, err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return keyPair{}, fmt.Errorf("create certificate for %s: %w", tmpl.Subject.CommonName, err)
+	}
+	keyPEM, err := encodeECKey(key)
+	if err != nil {
+		return keyPair{}, err
+	}
+	return keyPair{
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  keyPEM,
+	}, nil
+}
+
+// certTemplate returns a certificate template with a random serial number and
+// the standard validity window. NotBefore is backdated to tolerate clock skew
+// between the test process and kube-apiserver.
+func certTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certLifetime),
+	}, nil
+}
+
+// encodeECKey PEM-encodes an ECDSA private key in SEC 1 form.
+func encodeECKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
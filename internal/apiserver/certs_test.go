@@ -0,0 +1,134 @@
+package apiserver
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func parseCertPEM(t *testing.T, data []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("no PEM block found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// TestSetupCertsAndKeys verifies that the serving and client certificates
+// chain to the per-run CA with the expected names and usages.
+func TestSetupCertsAndKeys(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	p := &Process{}
+	var files startFiles
+	if err := p.setupCertsAndKeys(dir, &files); err != nil {
+		t.Fatalf("setupCertsAndKeys() error: %v", err)
+	}
+
+	caPEM, err := os.ReadFile(files.caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		t.Fatal("ca.crt is not a valid certificate")
+	}
+
+	servingPEM, err := os.ReadFile(files.servingCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serving := parseCertPEM(t, servingPEM)
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		if _, err := serving.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("serving certificate does not verify for %s: %v", host, err)
+		}
+	}
+
+	client := parseCertPEM(t, p.adminClient.certPEM)
+	if _, err := client.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		t.Errorf("client certificate does not verify: %v", err)
+	}
+	if client.Subject.CommonName != adminClientCommonName ||
+		len(client.Subject.Organization) != 1 || client.Subject.Organization[0] != adminClientGroup {
+		t.Errorf("client subject = %v, want CN=%s O=%s", client.Subject, adminClientCommonName, adminClientGroup)
+	}
+
+	if _, err := os.Stat(files.saKeyPath); err != nil {
+		t.Errorf("service account key not written: %v", err)
+	}
+}
+
+func TestWriteKubeconfigEmbedsCA(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		clientCertAuth bool
+		namespace      bool
+		wantHost       string
+	}{
+		"bearer token":       {wantHost: "https://127.0.0.1:6443"},
+		"client certificate": {clientCertAuth: true, wantHost: "https://127.0.0.1:6443"},
+		"network namespace":  {namespace: true, wantHost: "https://127.0.0.1:40123"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			p := &Process{config: Config{
+				Port:           6443,
+				KubeconfigPath: filepath.Join(dir, "kubeconfig.yaml"),
+				Options:        Options{ClientCertAuth: tc.clientCertAuth},
+			}}
+			if tc.namespace {
+				p.config.NamespaceSocket = filepath.Join(dir, "apiserver.sock")
+				p.config.ClientPort = 40123
+			}
+			var files startFiles
+			if err := p.setupCertsAndKeys(dir, &files); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.WriteKubeconfig(); err != nil {
+				t.Fatalf("WriteKubeconfig() error: %v", err)
+			}
+
+			cfg, err := clientcmd.BuildConfigFromFlags("", p.config.KubeconfigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Host != tc.wantHost {
+				t.Errorf("server = %q, want %q", cfg.Host, tc.wantHost)
+			}
+			if cfg.Insecure || len(cfg.CAData) == 0 {
+				t.Errorf("TLS config = %+v, want CA-verified", cfg.TLSClientConfig)
+			}
+			gotCert := len(cfg.CertData) > 0 && len(cfg.KeyData) > 0
+			if gotCert != tc.clientCertAuth || (cfg.BearerToken == "") != tc.clientCertAuth {
+				t.Errorf("credentials: client cert=%v token=%q, want client cert=%v",
+					gotCert, cfg.BearerToken, tc.clientCertAuth)
+			}
+		})
+	}
+}
+
+func TestWriteKubeconfigBeforeStart(t *testing.T) {
+	t.Parallel()
+
+	p := &Process{config: Config{KubeconfigPath: filepath.Join(t.TempDir(), "kubeconfig.yaml")}}
+	if err := p.WriteKubeconfig(); err == nil {
+		t.Error("WriteKubeconfig() before Start: expected error, got nil")
+	}
+}
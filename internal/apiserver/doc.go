@@ -1,8 +1,10 @@
 // Package apiserver provides process management for the kube-apiserver.
 //
-// On startup it generates self-signed TLS certificates, a static bearer token
-// for authentication, and an AuthenticationConfiguration for anonymous health
-// endpoints. Readiness is determined by polling the /livez HTTPS endpoint.
-// After the server is ready, WriteKubeconfig produces a kubeconfig file that
-// clients can use to connect.
+// On startup it generates a per-run CA with a serving certificate and an admin
+// client certificate, a static bearer token for authentication, and an
+// AuthenticationConfiguration for anonymous health endpoints. Readiness is
+// determined by polling the /livez HTTPS endpoint, verifying the serving
+// certificate against the CA. After the server is ready, WriteKubeconfig
+// produces a kubeconfig file, embedding the CA, that clients can use to
+// connect.
 package apiserver
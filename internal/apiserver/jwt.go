@@ -0,0 +1,201 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DefaultJWTAudience is the audience used when a JWTAuthenticator lists none.
+const DefaultJWTAudience = "k8senv"
+
+// JWTAuthenticator configures the claim handling of a jwt authenticator in
+// the apiserver.config.k8s.io/v1 AuthenticationConfiguration. The issuer
+// (URL and CA) is supplied separately via Options, since k8senv runs it.
+// Field semantics follow the upstream JWTAuthenticator type; CEL expressions
+// are evaluated by kube-apiserver.
+type JWTAuthenticator struct {
+	// Audiences lists the acceptable "aud" values. A token must carry at
+	// least one of them. Default: [DefaultJWTAudience].
+	Audiences []string
+
+	// ClaimValidationRules are evaluated against the token claims before
+	// mapping. A token failing any rule is rejected.
+	ClaimValidationRules []ClaimValidationRule
+
+	// ClaimMappings maps claims to the user's identity. Default: username
+	// from the "sub" claim without a prefix.
+	ClaimMappings ClaimMappings
+
+	// UserValidationRules are evaluated against the mapped user info.
+	UserValidationRules []UserValidationRule
+}
+
+// ClaimValidationRule validates token claims, either by requiring a claim to
+// equal RequiredValue or by a CEL Expression over "claims".
+type ClaimValidationRule struct {
+	Claim         string `json:"claim,omitempty"`
+	RequiredValue string `json:"requiredValue,omitempty"`
+	Expression    string `json:"expression,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// ClaimMappings maps token claims to user attributes.
+type ClaimMappings struct {
+	Username PrefixedClaimOrExpression
+	Groups   PrefixedClaimOrExpression
+	UID      ClaimOrExpression
+	Extra    []ExtraMapping
+}
+
+// PrefixedClaimOrExpression maps a claim, with Prefix prepended to its value,
+// or a CEL Expression to a user attribute. Prefix is ignored with Expression.
+type PrefixedClaimOrExpression struct {
+	Claim      string
+	Prefix     string
+	Expression string
+}
+
+// ClaimOrExpression maps a claim or a CEL Expression to a user attribute.
+type ClaimOrExpression struct {
+	Claim      string `json:"claim,omitempty"`
+	Expression string `json:"expression,omitempty"`
+}
+
+// ExtraMapping maps a CEL ValueExpression to the extra attribute Key, which
+// must be a domain-prefixed path such as "example.com/tenant".
+type ExtraMapping struct {
+	Key             string `json:"key"`
+	ValueExpression string `json:"valueExpression"`
+}
+
+// UserValidationRule is a CEL Expression over "user" that must evaluate to
+// true for the request to be authenticated.
+type UserValidationRule struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+}
+
+// isZero reports whether no claim or expression is set.
+func (p PrefixedClaimOrExpression) isZero() bool {
+	return p.Claim == "" && p.Expression == ""
+}
+
+// EffectiveAudiences returns Audiences, or the default audience when none is
+// set.
+func (a JWTAuthenticator) EffectiveAudiences() []string {
+	if len(a.Audiences) == 0 {
+		return []string{DefaultJWTAudience}
+	}
+	return a.Audiences
+}
+
+// Validate checks JWTAuthenticator invariants that kube-apiserver would only
+// report as a failed start.
+func (a JWTAuthenticator) Validate() error {
+	var errs []error
+	for _, p := range []struct {
+		name string
+		m    PrefixedClaimOrExpression
+	}{{"username", a.ClaimMappings.Username}, {"groups", a.ClaimMappings.Groups}} {
+		if p.m.Claim != "" && p.m.Expression != "" {
+			errs = append(errs, fmt.Errorf("jwt %s mapping: claim and expression are mutually exclusive", p.name))
+		}
+	}
+	if a.ClaimMappings.UID.Claim != "" && a.ClaimMappings.UID.Expression != "" {
+		errs = append(errs, errors.New("jwt uid mapping: claim and expression are mutually exclusive"))
+	}
+	for _, r := range a.ClaimValidationRules {
+		if (r.Claim == "") == (r.Expression == "") {
+			errs = append(errs, errors.New("jwt claim validation rule: exactly one of claim and expression must be set"))
+		}
+	}
+	for _, r := range a.UserValidationRules {
+		if r.Expression == "" {
+			errs = append(errs, errors.New("jwt user validation rule: expression must not be empty"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// The jwt* types below are the wire form of the upstream JWTAuthenticator.
+// They differ from the exported types where upstream uses pointers to tell
+// "unset" from "empty" (the claim prefix).
+
+type jwtAuthenticatorWire struct {
+	Issuer               jwtIssuerWire         `json:"issuer"`
+	ClaimValidationRules []ClaimValidationRule `json:"claimValidationRules,omitempty"`
+	ClaimMappings        claimMappingsWire     `json:"claimMappings"`
+	UserValidationRules  []UserValidationRule  `json:"userValidationRules,omitempty"`
+}
+
+type jwtIssuerWire struct {
+	URL                  string   `json:"url"`
+	CertificateAuthority string   `json:"certificateAuthority"`
+	Audiences            []string `json:"audiences"`
+	AudienceMatchPolicy  string   `json:"audienceMatchPolicy,omitempty"`
+}
+
+type claimMappingsWire struct {
+	Username prefixedClaimWire  `json:"username"`
+	Groups   *prefixedClaimWire `json:"groups,omitempty"`
+	UID      *ClaimOrExpression `json:"uid,omitempty"`
+	Extra    []ExtraMapping     `json:"extra,omitempty"`
+}
+
+type prefixedClaimWire struct {
+	Claim      string  `json:"claim,omitempty"`
+	Prefix     *string `json:"prefix,omitempty"`
+	Expression string  `json:"expression,omitempty"`
+}
+
+// wire converts p to its wire form. Upstream requires prefix to be present
+// whenever claim is set, so it is always emitted alongside a claim.
+func (p PrefixedClaimOrExpression) wire() prefixedClaimWire {
+	w := prefixedClaimWire{Claim: p.Claim, Expression: p.Expression}
+	if p.Claim != "" {
+		prefix := p.Prefix
+		w.Prefix = &prefix
+	}
+	return w
+}
+
+// jwtAuthenticatorJSON renders the jwt authenticator entry for the issuer at
+// issuerURL, trusted via caPEM, as single-line JSON. JSON is a subset of
+// YAML flow syntax, so the result can be embedded as a sequence item in
+// authConfigYAML.
+func (a JWTAuthenticator) jwtAuthenticatorJSON(issuerURL string, caPEM []byte) ([]byte, error) {
+	audiences := a.EffectiveAudiences()
+	w := jwtAuthenticatorWire{
+		Issuer: jwtIssuerWire{
+			URL:                  issuerURL,
+			CertificateAuthority: string(caPEM),
+			Audiences:            audiences,
+		},
+		ClaimValidationRules: a.ClaimValidationRules,
+		UserValidationRules:  a.UserValidationRules,
+	}
+	if len(audiences) > 1 {
+		w.Issuer.AudienceMatchPolicy = "MatchAny"
+	}
+
+	username := a.ClaimMappings.Username
+	if username.isZero() {
+		username = PrefixedClaimOrExpression{Claim: "sub"}
+	}
+	w.ClaimMappings.Username = username.wire()
+	if !a.ClaimMappings.Groups.isZero() {
+		groups := a.ClaimMappings.Groups.wire()
+		w.ClaimMappings.Groups = &groups
+	}
+	if uid := a.ClaimMappings.UID; uid != (ClaimOrExpression{}) {
+		w.ClaimMappings.UID = &uid
+	}
+	w.ClaimMappings.Extra = a.ClaimMappings.Extra
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("marshal jwt authenticator: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,107 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// TestOptionsAuthConfigJWT verifies that the jwt authenticator is embedded in
+// the AuthenticationConfiguration as valid YAML with upstream field names.
+func TestOptionsAuthConfigJWT(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{
+		JWT: &JWTAuthenticator{
+			Audiences: []string{"a", "b"},
+			ClaimValidationRules: []ClaimValidationRule{
+				{Claim: "hd", RequiredValue: "example.com"},
+			},
+			ClaimMappings: ClaimMappings{
+				Username: PrefixedClaimOrExpression{Claim: "email"},
+				Groups:   PrefixedClaimOrExpression{Claim: "groups", Prefix: "oidc:"},
+			},
+			UserValidationRules: []UserValidationRule{
+				{Expression: "!user.username.startsWith('system:')", Message: "reserved"},
+			},
+		},
+		JWTIssuerURL: "https://127.0.0.1:8443",
+		JWTIssuerCA:  []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"),
+	}
+	if err := opts.validate(); err != nil {
+		t.Fatalf("validate() unexpected error: %v", err)
+	}
+
+	data, err := opts.authConfig()
+	if err != nil {
+		t.Fatalf("authConfig() unexpected error: %v", err)
+	}
+	js, err := yamlutil.ToJSON(data)
+	if err != nil {
+		t.Fatalf("authConfig() is not valid YAML: %v\n%s", err, data)
+	}
+
+	var cfg struct {
+		Anonymous struct {
+			Enabled bool `json:"enabled"`
+		} `json:"anonymous"`
+		JWT []map[string]any `json:"jwt"`
+	}
+	if err := json.Unmarshal(js, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Anonymous.Enabled || len(cfg.JWT) != 1 {
+		t.Fatalf("authConfig() = %s, want anonymous enabled and one jwt entry", js)
+	}
+
+	got, err := json.Marshal(cfg.JWT[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{
+		`"url":"https://127.0.0.1:8443"`,
+		`"audienceMatchPolicy":"MatchAny"`,
+		`"username":{"claim":"email","prefix":""}`,
+		`"groups":{"claim":"groups","prefix":"oidc:"}`,
+		`"requiredValue":"example.com"`,
+		`"userValidationRules":[{`,
+	} {
+		if !strings.Contains(string(got), part) {
+			t.Errorf("jwt authenticator %s should contain %s", got, part)
+		}
+	}
+}
+
+func TestOptionsAuthConfigDefault(t *testing.T) {
+	t.Parallel()
+
+	data, err := (Options{}).authConfig()
+	if err != nil {
+		t.Fatalf("authConfig() unexpected error: %v", err)
+	}
+	want := "apiVersion: apiserver.config.k8s.io/v1\nkind: AuthenticationConfiguration\n" + anonymousConfigYAML
+	if string(data) != want {
+		t.Errorf("authConfig() = %q, want %q", data, want)
+	}
+}
+
+func TestJWTAuthenticatorValidate(t *testing.T) {
+	t.Parallel()
+
+	err := Options{JWT: &JWTAuthenticator{
+		ClaimMappings: ClaimMappings{
+			Username: PrefixedClaimOrExpression{Claim: "sub", Expression: "claims.sub"},
+		},
+		ClaimValidationRules: []ClaimValidationRule{{}},
+	}}.validate()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, part := range []string{"issuer URL", "issuer CA", "username mapping", "claim validation rule"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should contain %q", err.Error(), part)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package apiserver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Encryption providers accepted in Options.EncryptionProvider. The names match
+// the provider keys of the apiserver.config.k8s.io/v1 EncryptionConfiguration.
+const (
+	EncryptionAESCBC    = "aescbc"
+	EncryptionSecretbox = "secretbox"
+)
+
+// EncryptionKeySize is the key length, in bytes, required by both supported
+// providers (AES-256 for aescbc, XSalsa20-Poly1305 for secretbox).
+const EncryptionKeySize = 32
+
+// EncryptionKeyName is the name of the single key in the generated
+// EncryptionConfiguration. It appears in the prefix of every encrypted value
+// stored in kine, e.g. "k8s:enc:aescbc:v1:key1:".
+const EncryptionKeyName = "key1"
+
+// DefaultVerbosity is the klog verbosity (--v) kube-apiserver runs with when
+// Options.Verbosity is nil.
+const DefaultVerbosity = 2
+
+// ServiceClusterIPRange is the range Service ClusterIPs, including the one of
+// the default "kubernetes" Service, are allocated from.
+const ServiceClusterIPRange = "10.96.0.0/12"
+
+// ServiceAccountAdmissionPlugin is the admission plugin disabled by default.
+// It requires a ServiceAccount token controller, which k8senv does not run,
+// and would otherwise reject every Pod that references a ServiceAccount.
+const ServiceAccountAdmissionPlugin = "ServiceAccount"
+
+// Options holds optional kube-apiserver features layered on top of the
+// baseline flags produced by buildArgs. The zero value reproduces the default
+// k8senv configuration.
+type Options struct {
+	// EnableAdmissionPlugins is passed via --enable-admission-plugins, in
+	// addition to the plugins kube-apiserver enables by default.
+	EnableAdmissionPlugins []string
+
+	// DisableAdmissionPlugins is passed via --disable-admission-plugins.
+	// ServiceAccount is always disabled unless it appears in
+	// EnableAdmissionPlugins.
+	DisableAdmissionPlugins []string
+
+	// AdmissionConfigFile is the absolute path to an AdmissionConfiguration
+	// file passed via --admission-control-config-file. Empty means none.
+	AdmissionConfigFile string
+
+	// AdmissionConfig is an AdmissionConfiguration document written to the
+	// data directory and passed via --admission-control-config-file.
+	// Mutually exclusive with AdmissionConfigFile. Empty means none.
+	AdmissionConfig []byte
+
+	// AuditPolicyFile is the absolute path to an audit Policy file passed via
+	// --audit-policy-file. Requires AuditWebhookURL. Empty disables auditing
+	// unless AuditPolicy is set.
+	AuditPolicyFile string
+
+	// AuditPolicy is an audit Policy document written to the data directory
+	// and passed via --audit-policy-file. Requires AuditWebhookURL and is
+	// mutually exclusive with AuditPolicyFile. Empty means none.
+	AuditPolicy []byte
+
+	// AuditWebhookURL is the endpoint the audit webhook backend posts
+	// EventLists to. The backend runs in blocking mode so that events are
+	// delivered before the audited request completes.
+	AuditWebhookURL string
+
+	// EncryptionProvider enables encryption at rest with the named provider
+	// (EncryptionAESCBC or EncryptionSecretbox). Empty disables encryption.
+	EncryptionProvider string
+
+	// EncryptionResources lists the resources encrypted with
+	// EncryptionProvider, e.g. "secrets" or "widgets.example.com".
+	EncryptionResources []string
+
+	// EncryptionKey is the raw EncryptionKeySize-byte key written to the
+	// EncryptionConfiguration. It is shared by all instances of a manager so
+	// that a restarted instance can still decrypt its existing data.
+	EncryptionKey []byte
+
+	// JWT adds a jwt authenticator for the issuer at JWTIssuerURL to the
+	// AuthenticationConfiguration. nil disables JWT authentication.
+	JWT *JWTAuthenticator
+
+	// JWTIssuerURL is the HTTPS URL of the OIDC issuer. Required with JWT.
+	JWTIssuerURL string
+
+	// JWTIssuerCA is the PEM-encoded CA that kube-apiserver uses to verify
+	// the issuer's serving certificate. Required with JWT.
+	JWTIssuerCA []byte
+
+	// AuthorizationWebhookURL is the endpoint of an authorization webhook
+	// consulted before AlwaysAllow via --authorization-config. Empty keeps
+	// plain --authorization-mode=AlwaysAllow.
+	AuthorizationWebhookURL string
+
+	// AuthorizationMatchConditions are CEL expressions over "request" (the
+	// SubjectAccessReviewSpec) that must all be true for a request to be sent
+	// to the webhook. Requires AuthorizationWebhookURL.
+	AuthorizationMatchConditions []string
+
+	// ClientCertAuth makes WriteKubeconfig authenticate with the admin client
+	// certificate instead of the static bearer token.
+	ClientCertAuth bool
+
+	// ServiceAccounts keeps the ServiceAccount admission plugin enabled so
+	// Pods are admitted with their ServiceAccount and projected token volume,
+	// as in a real cluster. The TokenRequest API is always served, since the
+	// service account issuer and signing key are configured unconditionally.
+	ServiceAccounts bool
+
+	// Verbosity is the klog verbosity passed via --v. nil uses
+	// DefaultVerbosity.
+	Verbosity *int
+
+	// Version is the kube-apiserver version, which selects the
+	// authentication flags and the apiVersion of generated configuration
+	// files. nil assumes a current release.
+	Version *version.Version
+}
+
+// validate checks Options invariants and returns an error describing every
+// violation found.
+func (o Options) validate() error {
+	var errs []error
+
+	for _, name := range o.EnableAdmissionPlugins {
+		if name == "" {
+			errs = append(errs, errors.New("enabled admission plugin name must not be empty"))
+		}
+		if slices.Contains(o.DisableAdmissionPlugins, name) {
+			errs = append(errs, fmt.Errorf("admission plugin %q is both enabled and disabled", name))
+		}
+	}
+	for _, name := range o.DisableAdmissionPlugins {
+		if name == "" {
+			errs = append(errs, errors.New("disabled admission plugin name must not be empty"))
+		}
+	}
+	if o.AdmissionConfigFile != "" && len(o.AdmissionConfig) > 0 {
+		errs = append(errs, errors.New("admission config file and admission config are mutually exclusive"))
+	}
+	if o.ServiceAccounts && slices.Contains(o.DisableAdmissionPlugins, ServiceAccountAdmissionPlugin) {
+		errs = append(errs, fmt.Errorf("admission plugin %q must not be disabled when service accounts are enabled",
+			ServiceAccountAdmissionPlugin))
+	}
+	errs = append(errs, o.validateVersion()...)
+	if o.Verbosity != nil && *o.Verbosity < 0 {
+		errs = append(errs, fmt.Errorf("verbosity must not be negative, got %d", *o.Verbosity))
+	}
+	if o.AuditPolicyFile != "" && len(o.AuditPolicy) > 0 {
+		errs = append(errs, errors.New("audit policy file and audit policy are mutually exclusive"))
+	}
+	if o.AuditEnabled() && o.AuditWebhookURL == "" {
+		errs = append(errs, errors.New("audit webhook URL must be set when an audit policy is configured"))
+	}
+	if o.EncryptionProvider != "" {
+		errs = append(errs, o.validateEncryption()...)
+	}
+	if len(o.AuthorizationMatchConditions) > 0 && o.AuthorizationWebhookURL == "" {
+		errs = append(errs, errors.New("authorization match conditions require an authorization webhook"))
+	}
+	if slices.Contains(o.AuthorizationMatchConditions, "") {
+		errs = append(errs, errors.New("authorization match condition must not be empty"))
+	}
+	if o.JWT != nil {
+		if o.JWTIssuerURL == "" {
+			errs = append(errs, errors.New("jwt issuer URL must be set when a JWT authenticator is configured"))
+		}
+		if len(o.JWTIssuerCA) == 0 {
+			errs = append(errs, errors.New("jwt issuer CA must be set when a JWT authenticator is configured"))
+		}
+		if err := o.JWT.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// verbosity returns the value passed via --v.
+func (o Options) verbosity() int {
+	if o.Verbosity == nil {
+		return DefaultVerbosity
+	}
+	return *o.Verbosity
+}
+
+// StorageOptions returns the subset of o that shapes the objects
+// kube-apiserver writes to storage: admission, which may mutate or reject
+// them, and encryption at rest, which changes how they are encoded. A
+// kube-apiserver that builds a database for other instances, such as the
+// CRD cache, runs with these so that the rows match what the instances
+// themselves would write.
+func (o Options) StorageOptions() Options {
+	return Options{
+		EnableAdmissionPlugins:  slices.Clone(o.EnableAdmissionPlugins),
+		DisableAdmissionPlugins: slices.Clone(o.DisableAdmissionPlugins),
+		AdmissionConfigFile:     o.AdmissionConfigFile,
+		AdmissionConfig:         slices.Clone(o.AdmissionConfig),
+		EncryptionProvider:      o.EncryptionProvider,
+		EncryptionResources:     slices.Clone(o.EncryptionResources),
+		EncryptionKey:           slices.Clone(o.EncryptionKey),
+		ServiceAccounts:         o.ServiceAccounts,
+		Version:                 o.Version,
+	}
+}
+
+// StorageArgs returns the kube-apiserver flags of StorageOptions, plus the
+// Service IP range, independent of per-start paths and ports. Flags naming
+// a generated file carry a digest of its content instead. A database built
+// by one configuration is only reusable by another with the same
+// StorageArgs and version. Since the digest of the encryption
+// configuration covers its key, a database built with encryption is only
+// reused with the same key.
+func (o Options) StorageArgs() []string {
+	s := o.StorageOptions()
+	args := []string{"--service-cluster-ip-range=" + ServiceClusterIPRange}
+	var admissionDigest string
+	if len(s.AdmissionConfig) > 0 {
+		admissionDigest = digest(s.AdmissionConfig)
+	}
+	args = append(args, s.admissionArgs(admissionDigest)...)
+	if s.EncryptionProvider != "" {
+		// encryptionConfig only fails to marshal types that cannot fail.
+		data, _ := s.encryptionConfig()
+		args = append(args, s.encryptionArgs(digest(data))...)
+	}
+	return args
+}
+
+// digest returns "sha256:<hex>" of data.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// disabledAdmissionPlugins returns the plugins to pass via
+// --disable-admission-plugins: ServiceAccount (unless explicitly enabled or
+// ServiceAccounts is set) followed by the caller's DisableAdmissionPlugins, without duplicates.
+func (o Options) disabledAdmissionPlugins() []string {
+	disabled := make([]string, 0, 1+len(o.DisableAdmissionPlugins))
+	if !o.ServiceAccounts && !slices.Contains(o.EnableAdmissionPlugins, ServiceAccountAdmissionPlugin) {
+		disabled = append(disabled, ServiceAccountAdmissionPlugin)
+	}
+	for _, name := range o.DisableAdmissionPlugins {
+		if !slices.Contains(disabled, name) {
+			disabled = append(disabled, name)
+		}
+	}
+	return disabled
+}
+
+// admissionArgs returns the admission-related kube-apiserver flags. configPath
+// is the file written by writeAdmissionConfig; it is empty unless
+// AdmissionConfig is set.
+func (o Options) admissionArgs(configPath string) []string {
+	var args []string
+	if len(o.EnableAdmissionPlugins) > 0 {
+		args = append(args, "--enable-admission-plugins="+strings.Join(o.EnableAdmissionPlugins, ","))
+	}
+	if disabled := o.disabledAdmissionPlugins(); len(disabled) > 0 {
+		args = append(args, "--disable-admission-plugins="+strings.Join(disabled, ","))
+	}
+	switch {
+	case o.AdmissionConfigFile != "":
+		args = append(args, "--admission-control-config-file="+o.AdmissionConfigFile)
+	case configPath != "":
+		args = append(args, "--admission-control-config-file="+configPath)
+	}
+	return args
+}
+
+// AuditEnabled reports whether an audit policy is configured, as a file or
+// as a document.
+func (o Options) AuditEnabled() bool {
+	return o.AuditPolicyFile != "" || len(o.AuditPolicy) > 0
+}
+
+// auditArgs returns the audit-related kube-apiserver flags. policyPath is the
+// file written by writeAuditPolicy; it is empty unless AuditPolicy is set.
+// webhookConfigPath is the kubeconfig written by writeAuditWebhookConfig; it
+// is empty when auditing is disabled.
+func (o Options) auditArgs(policyPath, webhookConfigPath string) []string {
+	if !o.AuditEnabled() {
+		return nil
+	}
+	if o.AuditPolicyFile != "" {
+		policyPath = o.AuditPolicyFile
+	}
+	return []string{
+		"--audit-policy-file=" + policyPath,
+		"--audit-webhook-config-file=" + webhookConfigPath,
+		"--audit-webhook-mode=blocking",
+	}
+}
+
+// validateEncryption checks the encryption settings. It is only called when
+// EncryptionProvider is set.
+func (o Options) validateEncryption() []error {
+	var errs []error
+	if o.EncryptionProvider != EncryptionAESCBC && o.EncryptionProvider != EncryptionSecretbox {
+		errs = append(errs, fmt.Errorf("unknown encryption provider %q", o.EncryptionProvider))
+	}
+	if len(o.EncryptionResources) == 0 {
+		errs = append(errs, errors.New("encryption resources must not be empty"))
+	}
+	if slices.Contains(o.EncryptionResources, "") {
+		errs = append(errs, errors.New("encryption resource name must not be empty"))
+	}
+	if len(o.EncryptionKey) != EncryptionKeySize {
+		errs = append(errs, fmt.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(o.EncryptionKey)))
+	}
+	return errs
+}
+
+// encryptionArgs returns the encryption-at-rest kube-apiserver flags.
+// configPath is the file written by writeEncryptionConfig; it is empty when
+// encryption is disabled.
+func (o Options) encryptionArgs(configPath string) []string {
+	if o.EncryptionProvider == "" {
+		return nil
+	}
+	return []string{"--encryption-provider-config=" + configPath}
+}
+
+// encryptionConfiguration mirrors the subset of the
+// apiserver.config.k8s.io/v1 EncryptionConfiguration used by k8senv.
+type encryptionConfiguration struct {
+	APIVersion string                  `json:"apiVersion"`
+	Kind       string                  `json:"kind"`
+	Resources  []encryptionResourceSet `json:"resources"`
+}
+
+type encryptionResourceSet struct {
+	Resources []string         `json:"resources"`
+	Providers []map[string]any `json:"providers"`
+}
+
+type encryptionKey struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+// encryptionConfig renders the EncryptionConfiguration for o. The identity
+// provider is listed last so that data written before encryption was
+// enabled (e.g., rows copied from the CRD cache) remains readable.
+func (o Options) encryptionConfig() ([]byte, error) {
+	cfg := encryptionConfiguration{
+		APIVersion: "apiserver.config.k8s.io/v1",
+		Kind:       "EncryptionConfiguration",
+		Resources: []encryptionResourceSet{{
+			Resources: o.EncryptionResources,
+			Providers: []map[string]any{
+				{o.EncryptionProvider: map[string]any{
+					"keys": []encryptionKey{{
+						Name:   EncryptionKeyName,
+						Secret: base64.StdEncoding.EncodeToString(o.EncryptionKey),
+					}},
+				}},
+				{"identity": map[string]any{}},
+			},
+		}},
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal encryption config: %w", err)
+	}
+	return data, nil
+}
+
+// authConfig renders the AuthenticationConfiguration: authConfigYAML, plus
+// the jwt authenticator when JWT is set.
+func (o Options) authConfig() ([]byte, error) {
+	header := "apiVersion: " + o.authnAPIVersion() + "\nkind: AuthenticationConfiguration\n"
+	if o.atLeast(anonymousConditionsVersion) {
+		header += anonymousConfigYAML
+	}
+	if o.JWT == nil {
+		return []byte(header), nil
+	}
+	jwt, err := o.JWT.jwtAuthenticatorJSON(o.JWTIssuerURL, o.JWTIssuerCA)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(header + "jwt:\n- " + string(jwt) + "\n"), nil
+}
+
+// authnArgs returns the authentication flags besides the token file.
+// configPath is the AuthenticationConfiguration written by writeAuthConfig;
+// it is empty for versions that predate it. Versions that cannot restrict
+// anonymous access to the health endpoints in that file allow anonymous
+// requests through --anonymous-auth; AlwaysAllow authorizes them anyway.
+func (o Options) authnArgs(configPath string) []string {
+	var args []string
+	if configPath != "" {
+		args = append(args, "--authentication-config="+configPath)
+	}
+	if !o.atLeast(anonymousConditionsVersion) {
+		args = append(args, "--anonymous-auth=true")
+	}
+	return args
+}
+
+// healthCheckMatchCondition keeps the anonymous health checks used for
+// readiness away from the authorization webhook, so that a handler denying
+// everything cannot prevent an instance from starting.
+const healthCheckMatchCondition = `!has(request.nonResourceAttributes) || ` +
+	`!(request.nonResourceAttributes.path in ['/livez', '/readyz', '/healthz'])`
+
+// authorizationWebhookTimeout bounds each SubjectAccessReview round trip.
+const authorizationWebhookTimeout = "10s"
+
+// authorizationCacheTTL is the webhook decision cache TTL. kube-apiserver
+// requires a positive value; 1ms effectively disables caching so that every
+// request reaches the handler.
+const authorizationCacheTTL = "1ms"
+
+// authorizationArgs returns the authorization kube-apiserver flags.
+// configPath is the AuthorizationConfiguration written by
+// writeAuthorizationConfig; it is empty when no webhook is configured.
+func (o Options) authorizationArgs(configPath string) []string {
+	if o.AuthorizationWebhookURL == "" {
+		return []string{"--authorization-mode=AlwaysAllow"}
+	}
+	return []string{"--authorization-config=" + configPath}
+}
+
+// authorizationConfig renders the apiserver.config.k8s.io/v1
+// AuthorizationConfiguration chaining the webhook (reached through the
+// kubeconfig at kubeconfigPath) and AlwaysAllow. A webhook "no opinion"
+// therefore allows the request, and failures deny it.
+func (o Options) authorizationConfig(kubeconfigPath string) ([]byte, error) {
+	conditions := make([]map[string]string, 0, 1+len(o.AuthorizationMatchConditions))
+	for _, expr := range append([]string{healthCheckMatchCondition}, o.AuthorizationMatchConditions...) {
+		conditions = append(conditions, map[string]string{"expression": expr})
+	}
+
+	cfg := map[string]any{
+		"apiVersion": o.authzAPIVersion(),
+		"kind":       "AuthorizationConfiguration",
+		"authorizers": []map[string]any{
+			{
+				"type": "Webhook",
+				"name": "k8senv",
+				"webhook": map[string]any{
+					"timeout":                    authorizationWebhookTimeout,
+					"authorizedTTL":              authorizationCacheTTL,
+					"unauthorizedTTL":            authorizationCacheTTL,
+					"subjectAccessReviewVersion": "v1",
+					"matchConditionSubjectAccessReviewVersion": "v1",
+					"failurePolicy": "Deny",
+					"connectionInfo": map[string]string{
+						"type":           "KubeConfigFile",
+						"kubeConfigFile": kubeconfigPath,
+					},
+					"matchConditions": conditions,
+				},
+			},
+			{"type": "AlwaysAllow", "name": "always-allow"},
+		},
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal authorization config: %w", err)
+	}
+	return data, nil
+}
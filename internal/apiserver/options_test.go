@@ -0,0 +1,271 @@
+package apiserver
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOptionsAdmissionArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts       Options
+		configPath string
+		want       []string
+	}{
+		"default disables ServiceAccount": {
+			opts: Options{},
+			want: []string{"--disable-admission-plugins=ServiceAccount"},
+		},
+		"enable and disable": {
+			opts: Options{
+				EnableAdmissionPlugins:  []string{"EventRateLimit"},
+				DisableAdmissionPlugins: []string{"LimitRanger", "ServiceAccount"},
+			},
+			want: []string{
+				"--enable-admission-plugins=EventRateLimit",
+				"--disable-admission-plugins=ServiceAccount,LimitRanger",
+			},
+		},
+		"enabling ServiceAccount removes default disable": {
+			opts: Options{EnableAdmissionPlugins: []string{"ServiceAccount"}},
+			want: []string{"--enable-admission-plugins=ServiceAccount"},
+		},
+		"service accounts keep ServiceAccount enabled": {
+			opts: Options{ServiceAccounts: true},
+			want: nil,
+		},
+		"admission config file": {
+			opts: Options{AdmissionConfigFile: "/etc/admission.yaml"},
+			want: []string{
+				"--disable-admission-plugins=ServiceAccount",
+				"--admission-control-config-file=/etc/admission.yaml",
+			},
+		},
+		"generated admission config": {
+			opts:       Options{AdmissionConfig: []byte(`{"kind":"AdmissionConfiguration"}`)},
+			configPath: "/data/admission-config.json",
+			want: []string{
+				"--disable-admission-plugins=ServiceAccount",
+				"--admission-control-config-file=/data/admission-config.json",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := tc.opts.admissionArgs(tc.configPath); !slices.Equal(got, tc.want) {
+				t.Errorf("admissionArgs() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (Options{}).validate(); err != nil {
+		t.Fatalf("zero Options: unexpected error: %v", err)
+	}
+
+	err := Options{
+		EnableAdmissionPlugins:  []string{"PodSecurity", ""},
+		DisableAdmissionPlugins: []string{"PodSecurity"},
+	}.validate()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, part := range []string{`"PodSecurity" is both enabled and disabled`, "must not be empty"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should contain %q", err.Error(), part)
+		}
+	}
+
+	err = Options{ServiceAccounts: true, DisableAdmissionPlugins: []string{"ServiceAccount"}}.validate()
+	if err == nil || !strings.Contains(err.Error(), "service accounts are enabled") {
+		t.Errorf("validate() = %v, want service account conflict", err)
+	}
+
+	err = Options{AdmissionConfigFile: "/etc/admission.yaml", AdmissionConfig: []byte("{}")}.validate()
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("validate() = %v, want admission config conflict", err)
+	}
+}
+
+func TestOptionsVerbosity(t *testing.T) {
+	t.Parallel()
+
+	if got := (Options{}).verbosity(); got != DefaultVerbosity {
+		t.Errorf("verbosity() with nil = %d, want %d", got, DefaultVerbosity)
+	}
+	zero, negative := 0, -1
+	if got := (Options{Verbosity: &zero}).verbosity(); got != 0 {
+		t.Errorf("verbosity() = %d, want 0", got)
+	}
+	if err := (Options{Verbosity: &negative}).validate(); err == nil {
+		t.Error("validate() with negative verbosity: expected error, got nil")
+	}
+}
+
+func TestOptionsAuditArgs(t *testing.T) {
+	t.Parallel()
+
+	if got := (Options{}).auditArgs("", ""); got != nil {
+		t.Errorf("auditArgs() with no policy = %q, want nil", got)
+	}
+
+	opts := Options{AuditPolicyFile: "/etc/audit.yaml", AuditWebhookURL: "http://127.0.0.1:1234/audit/x"}
+	want := []string{
+		"--audit-policy-file=/etc/audit.yaml",
+		"--audit-webhook-config-file=/data/audit-webhook.kubeconfig",
+		"--audit-webhook-mode=blocking",
+	}
+	if got := opts.auditArgs("", "/data/audit-webhook.kubeconfig"); !slices.Equal(got, want) {
+		t.Errorf("auditArgs() = %q, want %q", got, want)
+	}
+
+	opts = Options{AuditPolicy: []byte(`{"kind":"Policy"}`), AuditWebhookURL: "http://127.0.0.1:1234/audit/x"}
+	want[0] = "--audit-policy-file=/data/audit-policy.json"
+	if got := opts.auditArgs("/data/audit-policy.json", "/data/audit-webhook.kubeconfig"); !slices.Equal(got, want) {
+		t.Errorf("auditArgs() with policy document = %q, want %q", got, want)
+	}
+
+	if err := (Options{AuditPolicyFile: "/etc/audit.yaml"}).validate(); err == nil {
+		t.Error("validate() with policy but no webhook URL: expected error, got nil")
+	}
+	if err := (Options{AuditPolicy: []byte("{}")}).validate(); err == nil {
+		t.Error("validate() with policy document but no webhook URL: expected error, got nil")
+	}
+	err := Options{AuditPolicyFile: "/etc/audit.yaml", AuditPolicy: []byte("{}"), AuditWebhookURL: "http://x"}.validate()
+	if err == nil {
+		t.Error("validate() with policy file and document: expected error, got nil")
+	}
+}
+
+func TestOptionsEncryptionConfig(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{
+		EncryptionProvider:  EncryptionSecretbox,
+		EncryptionResources: []string{"secrets", "widgets.example.com"},
+		EncryptionKey:       make([]byte, EncryptionKeySize),
+	}
+	if err := opts.validate(); err != nil {
+		t.Fatalf("validate() unexpected error: %v", err)
+	}
+
+	data, err := opts.encryptionConfig()
+	if err != nil {
+		t.Fatalf("encryptionConfig() unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`"kind": "EncryptionConfiguration"`,
+		`"widgets.example.com"`,
+		`"secretbox"`,
+		`"name": "key1"`,
+		`"secret": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="`,
+		`"identity": {}`,
+	} {
+		if !strings.Contains(string(data), part) {
+			t.Errorf("encryptionConfig() = %s, should contain %s", data, part)
+		}
+	}
+
+	want := []string{"--encryption-provider-config=/data/encryption-config.json"}
+	if got := opts.encryptionArgs("/data/encryption-config.json"); !slices.Equal(got, want) {
+		t.Errorf("encryptionArgs() = %q, want %q", got, want)
+	}
+
+	err = Options{EncryptionProvider: "aesgcm"}.validate()
+	if err == nil {
+		t.Fatal("validate() with invalid encryption settings: expected error, got nil")
+	}
+	for _, part := range []string{"unknown encryption provider", "resources must not be empty", "key must be 32 bytes"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should contain %q", err.Error(), part)
+		}
+	}
+}
+
+func TestOptionsStorageArgs(t *testing.T) {
+	t.Parallel()
+
+	if got := (Options{ServiceAccounts: true}).StorageArgs(); !slices.Equal(got, []string{
+		"--service-cluster-ip-range=" + ServiceClusterIPRange,
+	}) {
+		t.Errorf("StorageArgs() of defaults = %q, want only the Service IP range", got)
+	}
+
+	opts := Options{
+		EnableAdmissionPlugins: []string{"PodSecurity"},
+		AdmissionConfig:        []byte(`{"kind":"AdmissionConfiguration"}`),
+		EncryptionProvider:     EncryptionAESCBC,
+		EncryptionResources:    []string{"secrets"},
+		EncryptionKey:          make([]byte, EncryptionKeySize),
+		AuditPolicyFile:        "/etc/audit.yaml",
+		AuditWebhookURL:        "https://127.0.0.1:1/audit",
+	}
+	got := strings.Join(opts.StorageArgs(), " ")
+	for _, part := range []string{
+		"--enable-admission-plugins=PodSecurity",
+		"--admission-control-config-file=sha256:",
+		"--encryption-provider-config=sha256:",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("StorageArgs() = %s, should contain %s", got, part)
+		}
+	}
+	if strings.Contains(got, "audit") {
+		t.Errorf("StorageArgs() = %s, should not contain audit flags", got)
+	}
+
+	rekeyed := opts
+	rekeyed.EncryptionKey = slices.Repeat([]byte{1}, EncryptionKeySize)
+	if again := strings.Join(rekeyed.StorageArgs(), " "); again == got {
+		t.Error("StorageArgs() did not change with the encryption key")
+	}
+}
+
+func TestOptionsAuthorization(t *testing.T) {
+	t.Parallel()
+
+	want := []string{"--authorization-mode=AlwaysAllow"}
+	if got := (Options{}).authorizationArgs(""); !slices.Equal(got, want) {
+		t.Errorf("authorizationArgs() without webhook = %q, want %q", got, want)
+	}
+
+	opts := Options{
+		AuthorizationWebhookURL:      "http://127.0.0.1:1234/authorize",
+		AuthorizationMatchConditions: []string{"request.user == 'alice'"},
+	}
+	if err := opts.validate(); err != nil {
+		t.Fatalf("validate() unexpected error: %v", err)
+	}
+	want = []string{"--authorization-config=/data/authz-config.json"}
+	if got := opts.authorizationArgs("/data/authz-config.json"); !slices.Equal(got, want) {
+		t.Errorf("authorizationArgs() = %q, want %q", got, want)
+	}
+
+	data, err := opts.authorizationConfig("/data/authz-webhook.kubeconfig")
+	if err != nil {
+		t.Fatalf("authorizationConfig() unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`"kind": "AuthorizationConfiguration"`,
+		`"type": "Webhook"`,
+		`"kubeConfigFile": "/data/authz-webhook.kubeconfig"`,
+		`"expression": "request.user == 'alice'"`,
+		`/livez`,
+		`"type": "AlwaysAllow"`,
+	} {
+		if !strings.Contains(string(data), part) {
+			t.Errorf("authorizationConfig() = %s, should contain %s", data, part)
+		}
+	}
+
+	if err := (Options{AuthorizationMatchConditions: []string{"true"}}).validate(); err == nil {
+		t.Error("validate() with match conditions but no webhook: expected error, got nil")
+	}
+}
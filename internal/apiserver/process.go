@@ -7,7 +7,6 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +18,7 @@ import (
 	"time"
 
 	"github.com/giantswarm/k8senv/internal/fileutil"
+	"github.com/giantswarm/k8senv/internal/netns"
 	"github.com/giantswarm/k8senv/internal/process"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -35,14 +35,37 @@ type Config struct {
 	EtcdEndpoint   string // Kine/etcd endpoint URL (e.g., "http://127.0.0.1:2379")
 	KubeconfigPath string // Output path for kubeconfig file
 
+	// NamespaceSocket, when set, runs kube-apiserver in its own network
+	// namespace (see netns.Command), where it binds Port on loopback. The
+	// namespace helper bridges Port to this unix socket, and ClientPort is
+	// the loopback port of the netns.Forwarder through which clients,
+	// readiness checks included, reach it from outside.
+	NamespaceSocket string
+	ClientPort      int
+
+	// Options holds optional features (admission plugins, ...) layered on
+	// top of the baseline flags. The zero value is the default configuration.
+	Options Options
+
 	// StopTimeout is the timeout used by Close when auto-stopping a process
 	// that was not explicitly stopped. Zero uses process.DefaultStopTimeout.
 	StopTimeout time.Duration
 
+	// LogStream optionally re-emits the process output through Logger.
+	LogStream process.LogStream
+
+	// LogRotation optionally caps the size of the log files.
+	LogRotation process.LogRotation
+
 	// Logger (optional, defaults to slog.Default())
 	Logger *slog.Logger
 }
 
+// NamespacePort is the secure port kube-apiserver binds inside a network
+// namespace of its own. Each namespace has a private loopback, so every
+// instance uses the same port.
+const NamespacePort = 6443
+
 // testAuthToken is the static bearer token for kube-apiserver authentication
 // in the test environment. Safe because the API server binds to 127.0.0.1 only
 // and uses ephemeral self-signed certificates. Do NOT reuse in production or
@@ -66,12 +89,10 @@ const healthCheckTimeout = 5 * time.Second
 // this is a test framework where instances must start as quickly as possible.
 const readinessPollInterval = 100 * time.Millisecond
 
-// authConfigYAML is the AuthenticationConfiguration that allows anonymous
-// access to health check endpoints. Extracted as a constant so that
+// anonymousConfigYAML is the AuthenticationConfiguration section that allows
+// anonymous access to health check endpoints. Extracted as a constant so that
 // indentation changes during refactoring cannot silently break the YAML.
-const authConfigYAML = `apiVersion: apiserver.config.k8s.io/v1
-kind: AuthenticationConfiguration
-anonymous:
+const anonymousConfigYAML = `anonymous:
   enabled: true
   conditions:
   - path: /livez
@@ -83,6 +104,16 @@ anonymous:
 type Process struct {
 	config Config
 	base   process.BaseProcess
+
+	// caPEM and adminClient are generated by Start and consumed by
+	// WaitReady and WriteKubeconfig, which callers invoke after Start.
+	caPEM       []byte
+	adminClient keyPair
+
+	// caFile and saKeyFile are the paths written by Start, exposed through
+	// CAFile and ServiceAccountKeyFile for processes that share them.
+	caFile    string
+	saKeyFile string
 }
 
 // validate checks that all required Config fields are set and returns an error
@@ -101,12 +132,18 @@ func (c Config) validate() error {
 	if c.Port <= 0 || c.Port > 65535 {
 		errs = append(errs, errors.New("port must be between 1 and 65535"))
 	}
+	if c.NamespaceSocket != "" && (c.ClientPort <= 0 || c.ClientPort > 65535) {
+		errs = append(errs, errors.New("client port must be between 1 and 65535"))
+	}
 	if c.EtcdEndpoint == "" {
 		errs = append(errs, errors.New("etcd endpoint must not be empty"))
 	}
 	if c.KubeconfigPath == "" {
 		errs = append(errs, errors.New("kubeconfig path must not be empty"))
 	}
+	if err := c.Options.validate(); err != nil {
+		errs = append(errs, err)
+	}
 
 	return errors.Join(errs...)
 }
@@ -117,19 +154,36 @@ func New(cfg Config) (*Process, error) {
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("invalid apiserver config: %w", err)
 	}
-	return &Process{
-		config: cfg,
-		base:   process.NewBaseProcess("kube-apiserver", cfg.Logger, cfg.StopTimeout),
-	}, nil
+	base := process.NewBaseProcess("kube-apiserver", cfg.Logger, cfg.StopTimeout)
+	base.SetLogStream(cfg.LogStream)
+	base.SetLogRotation(cfg.LogRotation)
+	return &Process{config: cfg, base: base}, nil
 }
 
 // startFiles holds the file paths produced by prepareFiles and consumed by
 // buildArgs, bundling related paths into a single value.
 type startFiles struct {
 	tokenFilePath  string
-	certDir        string
+	caFile         string
+	servingCert    string
+	servingKey     string
 	saKeyPath      string
 	authConfigPath string
+
+	// admissionConfigPath is empty unless Options.AdmissionConfig is set.
+	admissionConfigPath string
+
+	// auditPolicyPath is empty unless Options.AuditPolicy is set.
+	auditPolicyPath string
+
+	// auditWebhookConfigPath is empty when auditing is disabled.
+	auditWebhookConfigPath string
+
+	// encryptionConfigPath is empty when encryption at rest is disabled.
+	encryptionConfigPath string
+
+	// authzConfigPath is empty when no authorization webhook is configured.
+	authzConfigPath string
 }
 
 // Start launches the kube-apiserver process. It prepares token, certificate,
@@ -149,19 +203,43 @@ func (p *Process) Start(ctx context.Context) error {
 
 	args := p.buildArgs(files)
 
-	cmd := exec.CommandContext( //nolint:gosec // G204: binary path is from config, not user input
-		ctx,
-		p.config.Binary,
-		args...)
+	cmd, err := p.command(ctx, args)
+	if err != nil {
+		return fmt.Errorf("start apiserver: %w", err)
+	}
 	if err := p.base.SetupAndStart(cmd, dir); err != nil {
 		return fmt.Errorf("setup and start apiserver process: %w", err)
 	}
 	return nil
 }
 
-// prepareFiles creates token, certificate, and auth config files sequentially.
-// These are fast local file writes; setupCertsAndKeys dominates at ~5-15ms
-// (ECDSA key generation), while the other two complete in microseconds.
+// command returns the command running the kube-apiserver binary with args,
+// directly or in a network namespace of its own.
+func (p *Process) command(ctx context.Context, args []string) (*exec.Cmd, error) {
+	if p.config.NamespaceSocket != "" {
+		cmd, err := netns.Command(ctx, p.config.NamespaceSocket, p.config.Port, p.config.Binary, args...)
+		if err != nil {
+			return nil, fmt.Errorf("create network namespace command: %w", err)
+		}
+		return cmd, nil
+	}
+	return exec.CommandContext(ctx, p.config.Binary, args...), nil //nolint:gosec // G204: binary path is from config, not user input
+}
+
+// clientPort returns the port clients connect to: ClientPort with a
+// NamespaceSocket, Port otherwise.
+func (p *Process) clientPort() int {
+	if p.config.NamespaceSocket != "" {
+		return p.config.ClientPort
+	}
+	return p.config.Port
+}
+
+// prepareFiles creates the token, certificate and authentication config
+// files, and the admission, audit policy, audit webhook, encryption and
+// authorization config files the options call for, sequentially. These are
+// fast local file writes; setupCertsAndKeys dominates at ~5-15ms (ECDSA key
+// generation), while the others complete in microseconds.
 func (p *Process) prepareFiles(dir string) (startFiles, error) {
 	var files startFiles
 
@@ -171,18 +249,57 @@ func (p *Process) prepareFiles(dir string) (startFiles, error) {
 	}
 	files.tokenFilePath = tokenFilePath
 
-	certDir, saKeyPath, err := p.setupCertsAndKeys(dir)
-	if err != nil {
+	if err := p.setupCertsAndKeys(dir, &files); err != nil {
 		return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
 	}
-	files.certDir = certDir
-	files.saKeyPath = saKeyPath
 
-	authConfigPath, err := p.writeAuthConfig(dir)
-	if err != nil {
-		return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+	if p.config.Options.structuredAuthn() {
+		authConfigPath, err := p.writeAuthConfig(dir)
+		if err != nil {
+			return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+		}
+		files.authConfigPath = authConfigPath
+	}
+
+	if len(p.config.Options.AdmissionConfig) > 0 {
+		admissionPath, err := p.writeAdmissionConfig(dir)
+		if err != nil {
+			return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+		}
+		files.admissionConfigPath = admissionPath
+	}
+
+	if len(p.config.Options.AuditPolicy) > 0 {
+		policyPath, err := p.writeAuditPolicy(dir)
+		if err != nil {
+			return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+		}
+		files.auditPolicyPath = policyPath
+	}
+
+	if p.config.Options.AuditEnabled() {
+		auditPath, err := p.writeAuditWebhookConfig(dir)
+		if err != nil {
+			return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+		}
+		files.auditWebhookConfigPath = auditPath
+	}
+
+	if p.config.Options.EncryptionProvider != "" {
+		encPath, err := p.writeEncryptionConfig(dir)
+		if err != nil {
+			return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+		}
+		files.encryptionConfigPath = encPath
+	}
+
+	if p.config.Options.AuthorizationWebhookURL != "" {
+		authzPath, err := p.writeAuthorizationConfig(dir)
+		if err != nil {
+			return startFiles{}, fmt.Errorf("prepare apiserver files: %w", err)
+		}
+		files.authzConfigPath = authzPath
 	}
-	files.authConfigPath = authConfigPath
 
 	return files, nil
 }
@@ -202,50 +319,176 @@ func (p *Process) writeTokenFile(dir string) (string, error) {
 	return tokenFilePath, nil
 }
 
-// setupCertsAndKeys creates the certificate directory and generates an ECDSA
-// P-256 key pair for service account signing. ECDSA P-256 is used instead of
-// RSA 2048 for faster key generation (<1ms vs ~50-200ms). It returns the cert
-// directory path and the service account key file path.
-func (p *Process) setupCertsAndKeys(dir string) (certDir string, saKeyPath string, err error) {
-	certDir = filepath.Join(dir, "certs")
+// setupCertsAndKeys creates the certificate directory and generates the TLS
+// and service account credentials, recording their paths in files:
+//   - a per-run CA, which kube-apiserver also uses to verify client
+//     certificates (--client-ca-file);
+//   - a serving certificate for 127.0.0.1 and localhost issued by that CA;
+//   - an admin client certificate (system:masters), kept in memory for
+//     WriteKubeconfig;
+//   - an ECDSA P-256 key pair for service account signing.
+//
+// ECDSA P-256 is used instead of RSA 2048 for faster key generation (<1ms vs
+// ~50-200ms per key).
+func (p *Process) setupCertsAndKeys(dir string, files *startFiles) error {
+	certDir := filepath.Join(dir, "certs")
 	if err := fileutil.EnsureDir(certDir); err != nil {
-		return "", "", fmt.Errorf("create cert dir: %w", err)
+		return fmt.Errorf("create cert dir: %w", err)
 	}
 
-	saKeyPath = filepath.Join(certDir, "sa.key")
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca, err := newCertificateAuthority()
+	if err != nil {
+		return err
+	}
+	serving, err := ca.issueServing()
 	if err != nil {
-		return "", "", fmt.Errorf("generate service account key: %w", err)
+		return err
+	}
+	adminClient, err := ca.issueClient(adminClientCommonName, adminClientGroup)
+	if err != nil {
+		return err
 	}
 
-	ecBytes, err := x509.MarshalECPrivateKey(privateKey)
+	saKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate service account key: %w", err)
+	}
+	saKeyPEM, err := encodeECKey(saKey)
 	if err != nil {
-		return "", "", fmt.Errorf("marshal service account key: %w", err)
+		return fmt.Errorf("encode service account key: %w", err)
 	}
 
-	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "EC PRIVATE KEY",
-		Bytes: ecBytes,
-	})
-	if err := os.WriteFile(saKeyPath, privateKeyPEM, 0o600); err != nil {
-		return "", "", fmt.Errorf("write service account key: %w", err)
+	files.caFile = filepath.Join(certDir, "ca.crt")
+	files.servingCert = filepath.Join(certDir, "apiserver.crt")
+	files.servingKey = filepath.Join(certDir, "apiserver.key")
+	files.saKeyPath = filepath.Join(certDir, "sa.key")
+	for _, f := range []struct {
+		path string
+		data []byte
+	}{
+		{files.caFile, ca.pem},
+		{files.servingCert, serving.certPEM},
+		{files.servingKey, serving.keyPEM},
+		{files.saKeyPath, saKeyPEM},
+	} {
+		if err := os.WriteFile(f.path, f.data, 0o600); err != nil {
+			return fmt.Errorf("write %s: %w", filepath.Base(f.path), err)
+		}
 	}
-	return certDir, saKeyPath, nil
+
+	p.caPEM = ca.pem
+	p.adminClient = adminClient
+	p.caFile = files.caFile
+	p.saKeyFile = files.saKeyPath
+	return nil
 }
 
 // writeAuthConfig creates the AuthenticationConfiguration YAML file that
-// allows anonymous access to health check endpoints (/livez, /readyz, /healthz).
+// allows anonymous access to health check endpoints (/livez, /readyz, /healthz)
+// and, when configured, adds the JWT authenticator. The apiVersion and the
+// anonymous section depend on Options.Version.
 func (p *Process) writeAuthConfig(dir string) (string, error) {
+	data, err := p.config.Options.authConfig()
+	if err != nil {
+		return "", err
+	}
 	authConfigPath := filepath.Join(dir, "auth-config.yaml")
-	if err := os.WriteFile(authConfigPath, []byte(authConfigYAML), 0o600); err != nil {
+	if err := os.WriteFile(authConfigPath, data, 0o600); err != nil {
 		return "", fmt.Errorf("create auth config file: %w", err)
 	}
 	return authConfigPath, nil
 }
 
+// writeAdmissionConfig creates the AdmissionConfiguration file passed via
+// --admission-control-config-file from Options.AdmissionConfig.
+func (p *Process) writeAdmissionConfig(dir string) (string, error) {
+	path := filepath.Join(dir, "admission-config.json")
+	if err := os.WriteFile(path, p.config.Options.AdmissionConfig, 0o600); err != nil {
+		return "", fmt.Errorf("create admission config file: %w", err)
+	}
+	return path, nil
+}
+
+// writeAuditPolicy creates the audit Policy file passed via
+// --audit-policy-file from Options.AuditPolicy.
+func (p *Process) writeAuditPolicy(dir string) (string, error) {
+	path := filepath.Join(dir, "audit-policy.json")
+	if err := os.WriteFile(path, p.config.Options.AuditPolicy, 0o600); err != nil {
+		return "", fmt.Errorf("create audit policy file: %w", err)
+	}
+	return path, nil
+}
+
+// writeAuditWebhookConfig creates the kubeconfig consumed by the audit
+// webhook backend.
+func (p *Process) writeAuditWebhookConfig(dir string) (string, error) {
+	path := filepath.Join(dir, "audit-webhook.kubeconfig")
+	if err := writeWebhookKubeconfig(path, p.config.Options.AuditWebhookURL); err != nil {
+		return "", fmt.Errorf("write audit webhook config: %w", err)
+	}
+	return path, nil
+}
+
+// writeAuthorizationConfig creates the kubeconfig for the authorization
+// webhook and the AuthorizationConfiguration referencing it. It returns the
+// path of the AuthorizationConfiguration.
+func (p *Process) writeAuthorizationConfig(dir string) (string, error) {
+	kubeconfigPath := filepath.Join(dir, "authz-webhook.kubeconfig")
+	if err := writeWebhookKubeconfig(kubeconfigPath, p.config.Options.AuthorizationWebhookURL); err != nil {
+		return "", fmt.Errorf("write authorization webhook config: %w", err)
+	}
+
+	data, err := p.config.Options.authorizationConfig(kubeconfigPath)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "authz-config.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("create authorization config file: %w", err)
+	}
+	return path, nil
+}
+
+// writeWebhookKubeconfig writes a kubeconfig pointing at a webhook served by
+// the test process. The webhook listens on loopback, so plain HTTP without
+// credentials is sufficient.
+func writeWebhookKubeconfig(path, server string) error {
+	config := clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			kubeconfigEntryName: {Server: server},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			kubeconfigEntryName: {Cluster: kubeconfigEntryName, AuthInfo: kubeconfigEntryName},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			kubeconfigEntryName: {},
+		},
+		CurrentContext: kubeconfigEntryName,
+	}
+	if err := clientcmd.WriteToFile(config, path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeEncryptionConfig creates the EncryptionConfiguration file passed via
+// --encryption-provider-config. JSON is used because it is valid YAML and
+// avoids hand-quoting resource names.
+func (p *Process) writeEncryptionConfig(dir string) (string, error) {
+	data, err := p.config.Options.encryptionConfig()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "encryption-config.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("create encryption config file: %w", err)
+	}
+	return path, nil
+}
+
 // buildArgs assembles the kube-apiserver command-line arguments.
 func (p *Process) buildArgs(files startFiles) []string {
-	return []string{
+	args := []string{
 		// Storage
 		"--etcd-servers=" + p.config.EtcdEndpoint,
 
@@ -253,27 +496,21 @@ func (p *Process) buildArgs(files startFiles) []string {
 		"--bind-address=127.0.0.1",
 		fmt.Sprintf("--secure-port=%d", p.config.Port),
 
-		// TLS (self-signed for testing)
-		"--cert-dir=" + files.certDir,
+		// TLS: serving certificate and client certificate verification
+		// from the per-run CA generated by setupCertsAndKeys.
+		"--tls-cert-file=" + files.servingCert,
+		"--tls-private-key-file=" + files.servingKey,
+		"--client-ca-file=" + files.caFile,
 
-		// Authentication - use AuthenticationConfiguration for anonymous health endpoints
-		"--authentication-config=" + files.authConfigPath,
+		// Authentication: the static admin token; anonymous health checks
+		// are allowed by authnArgs below.
 		"--token-auth-file=" + files.tokenFilePath,
-		// Use AlwaysAllow for faster startup - RBAC bootstrap is slow (~6s)
-		// Tests using token auth with system:masters group still work correctly
-		"--authorization-mode=AlwaysAllow",
 
 		// Service account configuration (required)
 		"--service-account-key-file=" + files.saKeyPath,
 		"--service-account-signing-key-file=" + files.saKeyPath,
 		"--service-account-issuer=https://kubernetes.default.svc",
 
-		// Required service configuration
-		"--service-cluster-ip-range=10.96.0.0/12",
-
-		// Disable admission plugins for simplicity
-		"--disable-admission-plugins=ServiceAccount",
-
 		// Disable the watch cache so all reads go directly to kine/SQLite.
 		// The watch cache is designed to reduce load on remote etcd clusters,
 		// but k8senv uses local SQLite where the benefit is negligible. More
@@ -282,22 +519,43 @@ func (p *Process) buildArgs(files startFiles) []string {
 		// Release() to miss recently-created namespaces under high concurrency.
 		"--watch-cache=false",
 
+		// The Service IP range the default "kubernetes" Service is
+		// allocated from.
+		"--service-cluster-ip-range=" + ServiceClusterIPRange,
+
 		// Logging
-		"--v=2",
+		fmt.Sprintf("--v=%d", p.config.Options.verbosity()),
 	}
+
+	// Authentication: an AuthenticationConfiguration restricting anonymous
+	// access to the health endpoints, or flags on versions predating it.
+	args = append(args, p.config.Options.authnArgs(files.authConfigPath)...)
+
+	// Admission plugins: ServiceAccount is disabled unless explicitly
+	// enabled, since no token controller runs alongside the apiserver.
+	args = append(args, p.config.Options.admissionArgs(files.admissionConfigPath)...)
+
+	// Authorization: AlwaysAllow for faster startup - RBAC bootstrap is slow
+	// (~6s). Tests using token auth with system:masters group still work
+	// correctly. An authorization webhook, if configured, is consulted first.
+	args = append(args, p.config.Options.authorizationArgs(files.authzConfigPath)...)
+
+	args = append(args, p.config.Options.auditArgs(files.auditPolicyPath, files.auditWebhookConfigPath)...)
+
+	return append(args, p.config.Options.encryptionArgs(files.encryptionConfigPath)...)
 }
 
-// WaitReady polls the /livez endpoint until it returns 200.
+// WaitReady polls the /livez endpoint until it returns 200. The serving
+// certificate is verified against the per-run CA, so Start must have been
+// called first.
 func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(p.caPEM) {
+		return errors.New("apiserver not ready: no CA certificate (Start not called)")
+	}
 	httpClient := &http.Client{
 		Transport: &http.Transport{
-			// InsecureSkipVerify is safe here because:
-			// 1. This is a testing framework - not production code
-			// 2. The API server uses ephemeral self-signed certificates generated at startup
-			// 3. We only connect to localhost (127.0.0.1) - no network exposure
-			// 4. The certificates are not known ahead of time and have no CA to verify against
-			//nolint:gosec // G402: InsecureSkipVerify is appropriate for testing framework
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
 
 			// DisableKeepAlives ensures each health-check request opens a fresh
 			// connection that is closed immediately after the response is read.
@@ -313,14 +571,15 @@ func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
 	// Use /livez instead of /readyz to avoid waiting for slow post-start hooks
 	// /livez only checks if the server process is alive, not if all controllers are ready
 	// This significantly reduces startup time from ~10s to ~3-4s
-	healthURL := fmt.Sprintf("https://127.0.0.1:%d/livez", p.config.Port)
+	port := p.clientPort()
+	healthURL := fmt.Sprintf("https://127.0.0.1:%d/livez", port)
 
 	log := p.base.Logger()
 	if err := process.WaitReady(ctx, process.WaitReadyConfig{
 		Interval:      readinessPollInterval,
 		Timeout:       timeout,
 		Name:          "apiserver",
-		Port:          p.config.Port,
+		Port:          port,
 		Logger:        log,
 		ProcessExited: p.base.Exited(),
 	}, func(checkCtx context.Context, attempt int) (bool, error) {
@@ -331,7 +590,7 @@ func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
 		resp, err := httpClient.Do(req)
 		if err != nil {
 			if log.Enabled(checkCtx, slog.LevelDebug) {
-				log.Debug("waitForAPIServer attempt", "port", p.config.Port, "attempt", attempt, "error", err)
+				log.Debug("waitForAPIServer attempt", "port", port, "attempt", attempt, "error", err)
 			}
 			return false, nil
 		}
@@ -346,7 +605,7 @@ func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
 			return true, nil
 		}
 		if log.Enabled(checkCtx, slog.LevelDebug) {
-			log.Debug("waitForAPIServer attempt", "port", p.config.Port, "attempt", attempt, "status", resp.StatusCode)
+			log.Debug("waitForAPIServer attempt", "port", port, "attempt", attempt, "status", resp.StatusCode)
 		}
 		return false, nil
 	}); err != nil {
@@ -356,19 +615,31 @@ func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
 }
 
 // WriteKubeconfig generates a kubeconfig file for connecting to this API server.
+// The cluster entry embeds the per-run CA, so clients verify the serving
+// certificate. The user authenticates with the static bearer token, or with
+// the admin client certificate when Options.ClientCertAuth is set.
+//
+// Returns an error if Start has not been called, since the CA and client
+// certificate do not exist yet.
 func (p *Process) WriteKubeconfig() error {
-	apiServerURL := fmt.Sprintf("https://127.0.0.1:%d", p.config.Port)
+	if len(p.caPEM) == 0 {
+		return errors.New("write kubeconfig: no CA certificate (Start not called)")
+	}
+	apiServerURL := fmt.Sprintf("https://127.0.0.1:%d", p.clientPort())
+
+	authInfo := &clientcmdapi.AuthInfo{Token: testAuthToken}
+	if p.config.Options.ClientCertAuth {
+		authInfo = &clientcmdapi.AuthInfo{
+			ClientCertificateData: p.adminClient.certPEM,
+			ClientKeyData:         p.adminClient.keyPEM,
+		}
+	}
 
 	config := clientcmdapi.Config{
 		Clusters: map[string]*clientcmdapi.Cluster{
 			kubeconfigEntryName: {
-				Server: apiServerURL,
-				// InsecureSkipTLSVerify is safe here because:
-				// 1. This is a testing framework - not production code
-				// 2. The API server uses ephemeral self-signed certificates generated at startup
-				// 3. We only connect to localhost (127.0.0.1) - no network exposure
-				// 4. The certificates are not known ahead of time and have no CA to verify against
-				InsecureSkipTLSVerify: true,
+				Server:                   apiServerURL,
+				CertificateAuthorityData: p.caPEM,
 			},
 		},
 		Contexts: map[string]*clientcmdapi.Context{
@@ -379,9 +650,7 @@ func (p *Process) WriteKubeconfig() error {
 		},
 		CurrentContext: kubeconfigEntryName,
 		AuthInfos: map[string]*clientcmdapi.AuthInfo{
-			kubeconfigEntryName: {
-				Token: testAuthToken,
-			},
+			kubeconfigEntryName: authInfo,
 		},
 	}
 
@@ -391,6 +660,18 @@ func (p *Process) WriteKubeconfig() error {
 	return nil
 }
 
+// CAFile returns the path of the per-run CA certificate written by Start.
+// It is empty before Start.
+func (p *Process) CAFile() string {
+	return p.caFile
+}
+
+// ServiceAccountKeyFile returns the path of the service account signing key
+// written by Start. It is empty before Start.
+func (p *Process) ServiceAccountKeyFile() string {
+	return p.saKeyFile
+}
+
 // Stop terminates the kube-apiserver process with the given timeout.
 func (p *Process) Stop(timeout time.Duration) error {
 	return p.base.Stop(timeout)
@@ -400,3 +681,14 @@ func (p *Process) Stop(timeout time.Duration) error {
 func (p *Process) Close() {
 	p.base.Close()
 }
+
+// Name returns the process name, "kube-apiserver", which also prefixes its log files.
+func (p *Process) Name() string {
+	return p.base.Name()
+}
+
+// ExitCode returns the exit code of a kube-apiserver process that exited on its own,
+// or -1 while it runs. See [process.BaseProcess.ExitCode].
+func (p *Process) ExitCode() int {
+	return p.base.ExitCode()
+}
@@ -0,0 +1,99 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// MinSupportedVersion is the oldest kube-apiserver minor k8senv generates
+// flags and configuration files for.
+var MinSupportedVersion = version.MajorMinor(1, 29)
+
+// Kubernetes minors that changed the flags and configuration files k8senv
+// generates.
+var (
+	// structuredAuthnVersion serves --authentication-config
+	// (StructuredAuthenticationConfiguration beta, enabled by default).
+	structuredAuthnVersion = version.MajorMinor(1, 30)
+
+	// anonymousConditionsVersion accepts the anonymous section with
+	// per-path conditions in the AuthenticationConfiguration
+	// (AnonymousAuthConfigurableEndpoints beta, enabled by default).
+	anonymousConditionsVersion = version.MajorMinor(1, 32)
+
+	// authzV1Version serves apiserver.config.k8s.io/v1 AuthorizationConfiguration.
+	authzV1Version = version.MajorMinor(1, 32)
+
+	// authnV1Version serves apiserver.config.k8s.io/v1 AuthenticationConfiguration.
+	authnV1Version = version.MajorMinor(1, 34)
+)
+
+// ParseVersionOutput parses the output of "kube-apiserver --version", e.g.
+// "Kubernetes v1.34.1".
+func ParseVersionOutput(out string) (*version.Version, error) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return nil, errors.New("parse kube-apiserver version: empty output")
+	}
+	v, err := version.ParseGeneric(fields[len(fields)-1])
+	if err != nil {
+		return nil, fmt.Errorf("parse kube-apiserver version %q: %w", strings.TrimSpace(out), err)
+	}
+	return v, nil
+}
+
+// atLeast reports whether kube-apiserver is want or newer. An unknown
+// version, or a development build reporting v0.0.0, is assumed to be
+// current.
+func (o Options) atLeast(want *version.Version) bool {
+	return o.Version == nil || o.Version.Major() == 0 || o.Version.AtLeast(want)
+}
+
+// structuredAuthn reports whether authentication is configured through an
+// AuthenticationConfiguration file rather than flags alone.
+func (o Options) structuredAuthn() bool {
+	return o.atLeast(structuredAuthnVersion)
+}
+
+// authnAPIVersion returns the apiVersion of the AuthenticationConfiguration.
+func (o Options) authnAPIVersion() string {
+	if o.atLeast(authnV1Version) {
+		return "apiserver.config.k8s.io/v1"
+	}
+	return "apiserver.config.k8s.io/v1beta1"
+}
+
+// authzAPIVersion returns the apiVersion of the AuthorizationConfiguration.
+func (o Options) authzAPIVersion() string {
+	if o.atLeast(authzV1Version) {
+		return "apiserver.config.k8s.io/v1"
+	}
+	return "apiserver.config.k8s.io/v1beta1"
+}
+
+// CheckVersion returns an error describing every configured feature that
+// Version does not support.
+func (o Options) CheckVersion() error {
+	return errors.Join(o.validateVersion()...)
+}
+
+// validateVersion reports the features that the configured kube-apiserver
+// version does not support.
+func (o Options) validateVersion() []error {
+	if o.structuredAuthn() {
+		return nil
+	}
+	var errs []error
+	if o.JWT != nil {
+		errs = append(errs, fmt.Errorf("jwt authenticator requires kube-apiserver %s or later, got %s",
+			structuredAuthnVersion, o.Version))
+	}
+	if o.AuthorizationWebhookURL != "" {
+		errs = append(errs, fmt.Errorf("authorization webhook requires kube-apiserver %s or later, got %s",
+			structuredAuthnVersion, o.Version))
+	}
+	return errs
+}
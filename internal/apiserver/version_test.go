@@ -0,0 +1,148 @@
+package apiserver
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		out     string
+		want    string
+		wantErr bool
+	}{
+		"release":     {out: "Kubernetes v1.34.1\n", want: "1.34.1"},
+		"pre-release": {out: "Kubernetes v1.35.0-alpha.2", want: "1.35.0"},
+		"dev build":   {out: "Kubernetes v0.0.0-master+$Format:%H$", want: "0.0.0"},
+		"empty":       {out: "", wantErr: true},
+		"garbage":     {out: "kube-apiserver: unknown flag", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			v, err := ParseVersionOutput(tc.out)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ParseVersionOutput(%q) = %v, want error", tc.out, v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.String(); got != tc.want {
+				t.Errorf("ParseVersionOutput(%q) = %s, want %s", tc.out, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOptionsVersionAwareAuth(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		version       string // empty for nil
+		wantArgs      []string
+		wantAPI       string // empty when no AuthenticationConfiguration is written
+		wantAnonymous bool
+		wantAuthzAPI  string
+	}{
+		"unknown": {
+			wantArgs:      []string{"--authentication-config=/auth.yaml"},
+			wantAPI:       "apiserver.config.k8s.io/v1",
+			wantAnonymous: true,
+			wantAuthzAPI:  "apiserver.config.k8s.io/v1",
+		},
+		"dev build": {
+			version:       "0.0.0",
+			wantArgs:      []string{"--authentication-config=/auth.yaml"},
+			wantAPI:       "apiserver.config.k8s.io/v1",
+			wantAnonymous: true,
+			wantAuthzAPI:  "apiserver.config.k8s.io/v1",
+		},
+		"1.34": {
+			version:       "1.34.1",
+			wantArgs:      []string{"--authentication-config=/auth.yaml"},
+			wantAPI:       "apiserver.config.k8s.io/v1",
+			wantAnonymous: true,
+			wantAuthzAPI:  "apiserver.config.k8s.io/v1",
+		},
+		"1.32": {
+			version:       "1.32.9",
+			wantArgs:      []string{"--authentication-config=/auth.yaml"},
+			wantAPI:       "apiserver.config.k8s.io/v1beta1",
+			wantAnonymous: true,
+			wantAuthzAPI:  "apiserver.config.k8s.io/v1",
+		},
+		"1.31": {
+			version:      "1.31.4",
+			wantArgs:     []string{"--authentication-config=/auth.yaml", "--anonymous-auth=true"},
+			wantAPI:      "apiserver.config.k8s.io/v1beta1",
+			wantAuthzAPI: "apiserver.config.k8s.io/v1beta1",
+		},
+		"1.29": {
+			version:  "1.29.0",
+			wantArgs: []string{"--anonymous-auth=true"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var opts Options
+			if tc.version != "" {
+				opts.Version = version.MustParseGeneric(tc.version)
+			}
+			configPath := ""
+			if opts.structuredAuthn() {
+				configPath = "/auth.yaml"
+			}
+			if got := opts.authnArgs(configPath); !slices.Equal(got, tc.wantArgs) {
+				t.Errorf("authnArgs() = %q, want %q", got, tc.wantArgs)
+			}
+			if tc.wantAPI == "" {
+				return
+			}
+
+			data, err := opts.authConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), "apiVersion: "+tc.wantAPI+"\n") {
+				t.Errorf("authConfig() = %q, want apiVersion %s", data, tc.wantAPI)
+			}
+			if got := strings.Contains(string(data), "anonymous:"); got != tc.wantAnonymous {
+				t.Errorf("authConfig() has anonymous section = %v, want %v", got, tc.wantAnonymous)
+			}
+			if got := opts.authzAPIVersion(); got != tc.wantAuthzAPI {
+				t.Errorf("authzAPIVersion() = %s, want %s", got, tc.wantAuthzAPI)
+			}
+		})
+	}
+}
+
+func TestOptionsValidateVersion(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{
+		Version:                 version.MustParseGeneric("1.29.3"),
+		JWT:                     &JWTAuthenticator{},
+		JWTIssuerURL:            "https://127.0.0.1:8443",
+		JWTIssuerCA:             []byte("ca"),
+		AuthorizationWebhookURL: "http://127.0.0.1:1234/authorize",
+	}
+	err := opts.validate()
+	if err == nil {
+		t.Fatal("validate() with 1.29 and JWT: expected error, got nil")
+	}
+	for _, part := range []string{"jwt authenticator requires kube-apiserver 1.30", "authorization webhook requires"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should contain %q", err, part)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+// Package audit receives kube-apiserver audit events in the test process.
+//
+// A Receiver serves the audit webhook backend on a loopback HTTP listener.
+// Each instance's kube-apiserver posts audit.k8s.io/v1 EventLists to a path
+// containing the instance ID, and the Receiver dispatches the decoded events
+// to the sink registered for that ID.
+package audit
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"encoding/json"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Event mirrors the audit.k8s.io/v1 Event type. It is declared here rather
+// than imported from k8s.io/apiserver to avoid pulling the apiserver library
+// into the dependency graph for a handful of JSON fields.
+type Event struct {
+	Level                    string                     `json:"level"`
+	AuditID                  types.UID                  `json:"auditID"`
+	Stage                    string                     `json:"stage"`
+	RequestURI               string                     `json:"requestURI"`
+	Verb                     string                     `json:"verb"`
+	User                     authenticationv1.UserInfo  `json:"user"`
+	ImpersonatedUser         *authenticationv1.UserInfo `json:"impersonatedUser,omitempty"`
+	SourceIPs                []string                   `json:"sourceIPs,omitempty"`
+	UserAgent                string                     `json:"userAgent,omitempty"`
+	ObjectRef                *ObjectReference           `json:"objectRef,omitempty"`
+	ResponseStatus           *metav1.Status             `json:"responseStatus,omitempty"`
+	RequestObject            json.RawMessage            `json:"requestObject,omitempty"`
+	ResponseObject           json.RawMessage            `json:"responseObject,omitempty"`
+	RequestReceivedTimestamp metav1.MicroTime           `json:"requestReceivedTimestamp"`
+	StageTimestamp           metav1.MicroTime           `json:"stageTimestamp"`
+	Annotations              map[string]string          `json:"annotations,omitempty"`
+}
+
+// ObjectReference mirrors the audit.k8s.io/v1 ObjectReference type.
+type ObjectReference struct {
+	Resource        string    `json:"resource,omitempty"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name,omitempty"`
+	UID             types.UID `json:"uid,omitempty"`
+	APIGroup        string    `json:"apiGroup,omitempty"`
+	APIVersion      string    `json:"apiVersion,omitempty"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+	Subresource     string    `json:"subresource,omitempty"`
+}
+
+// eventList mirrors the audit.k8s.io/v1 EventList type posted by the
+// webhook backend.
+type eventList struct {
+	Items []Event `json:"items"`
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// pathPrefix is the URL path under which per-instance webhook endpoints are
+// served. The instance ID is the final path segment.
+const pathPrefix = "/audit/"
+
+// maxBatchBytes bounds the size of a single EventList request body. The
+// webhook backend runs in blocking mode, so batches hold a single event in
+// practice; the limit only guards against runaway request/response bodies
+// captured at the RequestResponse level.
+const maxBatchBytes = 64 << 20
+
+// readHeaderTimeout bounds how long the receiver waits for request headers,
+// protecting the listener from stalled connections.
+const readHeaderTimeout = 10 * time.Second
+
+// shutdownTimeout bounds how long Close waits for in-flight webhook
+// requests to finish before closing their connections.
+const shutdownTimeout = 5 * time.Second
+
+// Sink receives the audit events posted for one instance. Sinks are called
+// from HTTP handler goroutines and must be safe for concurrent use.
+type Sink func(events []Event)
+
+// Receiver serves the audit webhook backend for every instance of a manager
+// on a single loopback listener. It is safe for concurrent use.
+type Receiver struct {
+	listener net.Listener
+	server   *http.Server
+	log      *slog.Logger
+
+	// mu protects sinks.
+	mu    sync.RWMutex
+	sinks map[string]Sink
+
+	// serveDone is closed when the Serve goroutine returns.
+	serveDone chan struct{}
+}
+
+// NewReceiver starts a Receiver on an ephemeral loopback port. The listener
+// is held open for the lifetime of the Receiver, so unlike process ports
+// there is no allocation race. If logger is nil, slog.Default() is used.
+func NewReceiver(logger *slog.Logger) (*Receiver, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen for audit webhook: %w", err)
+	}
+
+	r := &Receiver{
+		listener:  listener,
+		log:       logger,
+		sinks:     make(map[string]Sink),
+		serveDone: make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+pathPrefix+"{id}", r.handle)
+	r.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		defer close(r.serveDone)
+		if serveErr := r.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			r.log.Warn("audit receiver stopped unexpectedly", "error", serveErr)
+		}
+	}()
+
+	return r, nil
+}
+
+// URL returns the webhook endpoint that the kube-apiserver of the instance
+// identified by id must post its events to.
+func (r *Receiver) URL(id string) string {
+	return "http://" + r.listener.Addr().String() + pathPrefix + id
+}
+
+// Register routes events posted for id to sink, replacing any previous sink.
+func (r *Receiver) Register(id string, sink Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks[id] = sink
+}
+
+// Unregister stops routing events for id. Events posted afterwards are
+// acknowledged and discarded.
+func (r *Receiver) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sinks, id)
+}
+
+// Close stops the listener and waits for in-flight requests to complete,
+// bounded by shutdownTimeout.
+func (r *Receiver) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := r.server.Shutdown(ctx)
+	<-r.serveDone
+	if err != nil {
+		return fmt.Errorf("shutdown audit receiver: %w", err)
+	}
+	return nil
+}
+
+// handle decodes an EventList and dispatches it to the registered sink.
+// Decoding failures are reported with 400 so that kube-apiserver logs them;
+// unknown instance IDs are acknowledged to avoid blocking an apiserver whose
+// instance has already been detached.
+func (r *Receiver) handle(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+
+	var list eventList
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBatchBytes)).Decode(&list); err != nil {
+		r.log.Warn("decode audit event list", "id", id, "error", err)
+		http.Error(w, "decode audit event list: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.mu.RLock()
+	sink := r.sinks[id]
+	r.mu.RUnlock()
+
+	if sink != nil && len(list.Items) > 0 {
+		sink(list.Items)
+	}
+	w.WriteHeader(http.StatusOK)
+}
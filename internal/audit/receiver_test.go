@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// eventListJSON is a minimal audit.k8s.io/v1 EventList as posted by the
+// kube-apiserver webhook backend.
+const eventListJSON = `{
+  "kind": "EventList",
+  "apiVersion": "audit.k8s.io/v1",
+  "items": [{
+    "level": "Metadata",
+    "auditID": "0b1c",
+    "stage": "ResponseComplete",
+    "requestURI": "/api/v1/namespaces/ns1/secrets",
+    "verb": "list",
+    "user": {"username": "admin", "groups": ["system:masters"]},
+    "objectRef": {"resource": "secrets", "namespace": "ns1", "apiVersion": "v1"},
+    "responseStatus": {"code": 200},
+    "requestReceivedTimestamp": "2026-01-02T03:04:05.000000Z",
+    "stageTimestamp": "2026-01-02T03:04:05.100000Z"
+  }]
+}`
+
+func newTestReceiver(t *testing.T) *Receiver {
+	t.Helper()
+	r, err := NewReceiver(nil)
+	if err != nil {
+		t.Fatalf("NewReceiver: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := r.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	return r
+}
+
+func post(t *testing.T, url, body string) int {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post %s: %v", url, err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestReceiverDispatchesToRegisteredSink(t *testing.T) {
+	t.Parallel()
+	r := newTestReceiver(t)
+
+	var (
+		mu  sync.Mutex
+		got []Event
+	)
+	r.Register("inst-0-abcd", func(events []Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, events...)
+	})
+
+	if code := post(t, r.URL("inst-0-abcd"), eventListJSON); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("got %d events, want 1", len(got))
+	}
+	ev := got[0]
+	if ev.Verb != "list" || ev.ObjectRef == nil || ev.ObjectRef.Resource != "secrets" || ev.ObjectRef.Namespace != "ns1" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev.User.Username != "admin" {
+		t.Errorf("User.Username = %q, want admin", ev.User.Username)
+	}
+	if ev.ResponseStatus == nil || ev.ResponseStatus.Code != http.StatusOK {
+		t.Errorf("ResponseStatus = %+v, want code 200", ev.ResponseStatus)
+	}
+}
+
+func TestReceiverUnknownInstanceIsAcknowledged(t *testing.T) {
+	t.Parallel()
+	r := newTestReceiver(t)
+
+	called := false
+	r.Register("inst-1", func([]Event) { called = true })
+	r.Unregister("inst-1")
+
+	if code := post(t, r.URL("inst-1"), eventListJSON); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if called {
+		t.Error("sink called after Unregister")
+	}
+}
+
+func TestReceiverRejectsMalformedBody(t *testing.T) {
+	t.Parallel()
+	r := newTestReceiver(t)
+
+	if code := post(t, r.URL("inst-2"), "{not json"); code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", code)
+	}
+}
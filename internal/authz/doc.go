@@ -0,0 +1,8 @@
+// Package authz serves a kube-apiserver authorization webhook from the test
+// process.
+//
+// A Server exposes a caller-supplied http.Handler on a loopback HTTP listener.
+// kube-apiserver posts authorization.k8s.io/v1 SubjectAccessReviews to it and
+// expects the same object back with its status filled in. Func adapts a plain
+// Go function to that protocol.
+package authz
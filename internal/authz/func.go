@@ -0,0 +1,45 @@
+package authz
+
+import (
+	"encoding/json"
+	"net/http"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// maxReviewBytes bounds the size of a SubjectAccessReview request body.
+const maxReviewBytes = 1 << 20
+
+// Func decides a SubjectAccessReview. Returning a status with neither
+// Allowed nor Denied set means "no opinion", which lets the next authorizer
+// in the chain decide.
+//
+// Func implements http.Handler speaking the webhook protocol, so it can be
+// used wherever a webhook handler is expected. It is called from HTTP
+// handler goroutines and must be safe for concurrent use.
+type Func func(review *authorizationv1.SubjectAccessReview) authorizationv1.SubjectAccessReviewStatus
+
+// ServeHTTP decodes the SubjectAccessReview, calls f and writes the review
+// back with the returned status. Malformed requests get 400, which
+// kube-apiserver treats according to the webhook's failure policy.
+func (f Func) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review authorizationv1.SubjectAccessReview
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxReviewBytes)).Decode(&review); err != nil {
+		http.Error(w, "decode subject access review: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review.Status = f(&review)
+	// kube-apiserver requires the response to carry the request's
+	// apiVersion and kind.
+	review.APIVersion = authorizationv1.SchemeGroupVersion.String()
+	review.Kind = "SubjectAccessReview"
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&review) //nolint:errcheck,gosec // a failed write means the client is gone; nothing to report
+}
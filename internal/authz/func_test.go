@@ -0,0 +1,89 @@
+package authz
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// TestServerFunc verifies the webhook round trip through a Server: the
+// decoded review reaches the Func and its status is returned.
+func TestServerFunc(t *testing.T) {
+	t.Parallel()
+
+	srv, err := NewServer(Func(func(review *authorizationv1.SubjectAccessReview) authorizationv1.SubjectAccessReviewStatus {
+		if review.Spec.User == "alice" {
+			return authorizationv1.SubjectAccessReviewStatus{Allowed: true}
+		}
+		return authorizationv1.SubjectAccessReviewStatus{Denied: true, Reason: "not alice"}
+	}), nil)
+	if err != nil {
+		t.Fatalf("NewServer() error: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("Close() error: %v", err)
+		}
+	})
+
+	tests := map[string]struct {
+		user        string
+		wantAllowed bool
+		wantDenied  bool
+	}{
+		"allowed": {user: "alice", wantAllowed: true},
+		"denied":  {user: "bob", wantDenied: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			body, err := json.Marshal(authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{User: tc.user},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.Post(srv.URL(), "application/json", bytes.NewReader(body)) //nolint:noctx // loopback test request
+			if err != nil {
+				t.Fatalf("POST review: %v", err)
+			}
+			defer resp.Body.Close() //nolint:errcheck // test cleanup
+
+			var got authorizationv1.SubjectAccessReview
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.Kind != "SubjectAccessReview" || got.APIVersion != "authorization.k8s.io/v1" {
+				t.Errorf("response type = %s/%s, want authorization.k8s.io/v1/SubjectAccessReview", got.APIVersion, got.Kind)
+			}
+			if got.Status.Allowed != tc.wantAllowed || got.Status.Denied != tc.wantDenied {
+				t.Errorf("status = %+v, want allowed=%v denied=%v", got.Status, tc.wantAllowed, tc.wantDenied)
+			}
+		})
+	}
+}
+
+func TestFuncRejectsMalformedReview(t *testing.T) {
+	t.Parallel()
+
+	srv, err := NewServer(Func(func(*authorizationv1.SubjectAccessReview) authorizationv1.SubjectAccessReviewStatus {
+		t.Error("Func called for malformed review")
+		return authorizationv1.SubjectAccessReviewStatus{}
+	}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() }) //nolint:errcheck,gosec // test cleanup
+
+	resp, err := http.Post(srv.URL(), "application/json", bytes.NewReader([]byte("{"))) //nolint:noctx // loopback test request
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint:errcheck,gosec // test cleanup
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status code = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
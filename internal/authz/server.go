@@ -0,0 +1,82 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// readHeaderTimeout bounds how long the server waits for request headers,
+// protecting the listener from stalled connections.
+const readHeaderTimeout = 10 * time.Second
+
+// shutdownTimeout bounds how long Close waits for in-flight webhook requests
+// to finish before closing their connections.
+const shutdownTimeout = 5 * time.Second
+
+// Server serves an authorization webhook handler on a loopback listener
+// shared by every instance of a manager.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+	log      *slog.Logger
+
+	// serveDone is closed when the Serve goroutine returns.
+	serveDone chan struct{}
+}
+
+// NewServer starts serving handler on an ephemeral loopback port. The
+// listener is held open for the lifetime of the Server. If logger is nil,
+// slog.Default() is used.
+func NewServer(handler http.Handler, logger *slog.Logger) (*Server, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen for authorization webhook: %w", err)
+	}
+
+	s := &Server{
+		listener: listener,
+		log:      logger,
+		server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+		serveDone: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.serveDone)
+		if serveErr := s.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			s.log.Warn("authorization webhook stopped unexpectedly", "error", serveErr)
+		}
+	}()
+
+	return s, nil
+}
+
+// URL returns the endpoint kube-apiserver posts SubjectAccessReviews to.
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String() + "/authorize"
+}
+
+// Close stops the listener and waits for in-flight requests to complete,
+// bounded by shutdownTimeout.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	<-s.serveDone
+	if err != nil {
+		return fmt.Errorf("shutdown authorization webhook: %w", err)
+	}
+	return nil
+}
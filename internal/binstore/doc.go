@@ -0,0 +1,4 @@
+// Package binstore defines the on-disk layout of the binaries store shared by
+// package binaries, which installs into it, and the k8senv root package,
+// which falls back to it when kine or kube-apiserver is not on $PATH.
+package binstore
@@ -0,0 +1,89 @@
+package binstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/giantswarm/k8senv/internal/sentinel"
+)
+
+// Binary names installed by the store.
+const (
+	Kine          = "kine"
+	KubeAPIServer = "kube-apiserver"
+)
+
+// Versions pinned by this release of k8senv.
+const (
+	DefaultKineVersion          = "v0.14.12"
+	DefaultKubeAPIServerVersion = "v1.35.2"
+)
+
+// DirEnv overrides the default store directory.
+const DirEnv = "K8SENV_STORE_DIR"
+
+// ErrNotInstalled is returned when a binary version is not in the store.
+const ErrNotInstalled = sentinel.Error("binary not installed")
+
+// ErrInvalidVersion is returned for a version that cannot name a directory
+// in the store, e.g. one containing a path separator or "..".
+const ErrInvalidVersion = sentinel.Error("invalid binary version")
+
+// DefaultDir returns $K8SENV_STORE_DIR if set, and otherwise k8senv/binaries
+// in the user cache directory (e.g. ~/.cache/k8senv/binaries on Linux).
+func DefaultDir() string {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	return filepath.Join(cache, "k8senv", "binaries")
+}
+
+// Path returns the path of the installed binary name at version for the
+// current platform in the store in dir.
+//
+// Returns ErrInvalidVersion if version is not a valid path element, and
+// ErrNotInstalled if the binary has not been installed.
+func Path(dir, name, version string) (string, error) {
+	ref, err := RefPath(dir, name, version)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(ref)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s %s", ErrNotInstalled, name, version)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read ref for %s %s: %w", name, version, err)
+	}
+	path := ObjectPath(dir, strings.TrimSpace(string(data)), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w: %s %s: %w", ErrNotInstalled, name, version, err)
+	}
+	return path, nil
+}
+
+// ObjectPath returns the path of the binary name with digest in the store in
+// dir.
+func ObjectPath(dir, digest, name string) string {
+	return filepath.Join(dir, "sha256", digest, name)
+}
+
+// RefPath returns the path of the ref of name at version for the current
+// platform in the store in dir.
+//
+// Returns ErrInvalidVersion if version is empty or contains a path
+// separator or "..", so it cannot point outside the store.
+func RefPath(dir, name, version string) (string, error) {
+	if version == "" || strings.ContainsAny(version, `/\`) || strings.Contains(version, "..") {
+		return "", fmt.Errorf("%w %q", ErrInvalidVersion, version)
+	}
+	return filepath.Join(dir, "refs", name, version, runtime.GOOS+"-"+runtime.GOARCH), nil
+}
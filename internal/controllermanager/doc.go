@@ -0,0 +1,9 @@
+// Package controllermanager provides process management for an optional
+// kube-controller-manager running alongside kube-apiserver.
+//
+// The controller manager connects with the instance kubeconfig, runs only the
+// selected controllers, and signs service account tokens with the key written
+// by kube-apiserver. Readiness is determined by polling its /healthz HTTPS
+// endpoint, and shutdown goes through process.BaseProcess like kine and
+// kube-apiserver.
+package controllermanager
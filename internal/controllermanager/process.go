@@ -0,0 +1,249 @@
+package controllermanager
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/k8senv/internal/process"
+)
+
+// DefaultControllers are the controllers started when none are selected:
+// namespace deletion, ownerReference garbage collection, and service account
+// token population.
+var DefaultControllers = []string{"namespace", "garbagecollector", "serviceaccount-token"}
+
+// healthCheckTimeout is the per-request timeout for the HTTP client used to
+// poll the kube-controller-manager /healthz endpoint.
+const healthCheckTimeout = 5 * time.Second
+
+// readinessPollInterval is the interval between consecutive /healthz checks.
+// kube-controller-manager typically needs 1-2s to start, so the same 100ms
+// interval as kube-apiserver keeps polling overhead negligible.
+const readinessPollInterval = 100 * time.Millisecond
+
+// Compile-time interface satisfaction check.
+var _ process.Stoppable = (*Process)(nil)
+
+// Config holds the configuration for a kube-controller-manager process.
+type Config struct {
+	Binary         string // Path to kube-controller-manager binary
+	DataDir        string // Working directory for logs and serving certificates
+	Port           int    // Secure port serving /healthz
+	KubeconfigPath string // Kubeconfig for the instance's kube-apiserver
+
+	// CAFile is the cluster CA published to namespaces as kube-root-ca.crt
+	// and embedded in service account token secrets.
+	CAFile string
+
+	// ServiceAccountKeyFile is kube-apiserver's service account signing key,
+	// used by the serviceaccount-token controller.
+	ServiceAccountKeyFile string
+
+	// Controllers are passed via --controllers. Empty uses DefaultControllers.
+	Controllers []string
+
+	// StopTimeout is the timeout used by Close when auto-stopping a process
+	// that was not explicitly stopped. Zero uses process.DefaultStopTimeout.
+	StopTimeout time.Duration
+
+	// LogStream optionally re-emits the process output through Logger.
+	LogStream process.LogStream
+
+	// LogRotation optionally caps the size of the log files.
+	LogRotation process.LogRotation
+
+	// Logger (optional, defaults to slog.Default())
+	Logger *slog.Logger
+}
+
+// Process manages a kube-controller-manager process lifecycle.
+type Process struct {
+	config Config
+	base   process.BaseProcess
+}
+
+// validate checks that all required Config fields are set and returns an error
+// describing every violation found. It uses errors.Join to report multiple
+// issues at once, allowing callers to fix all problems in a single pass rather
+// than playing whack-a-mole with one error at a time.
+func (c Config) validate() error {
+	var errs []error
+
+	if c.Binary == "" {
+		errs = append(errs, errors.New("binary path must not be empty"))
+	}
+	if c.DataDir == "" {
+		errs = append(errs, errors.New("data dir must not be empty"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, errors.New("port must be between 1 and 65535"))
+	}
+	if c.KubeconfigPath == "" {
+		errs = append(errs, errors.New("kubeconfig path must not be empty"))
+	}
+	if c.CAFile == "" {
+		errs = append(errs, errors.New("CA file must not be empty"))
+	}
+	if c.ServiceAccountKeyFile == "" {
+		errs = append(errs, errors.New("service account key file must not be empty"))
+	}
+	if slices.Contains(c.Controllers, "") {
+		errs = append(errs, errors.New("controller name must not be empty"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// New creates a new kube-controller-manager Process with the given
+// configuration. It returns an error if any required field is missing or
+// invalid.
+func New(cfg Config) (*Process, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid controller manager config: %w", err)
+	}
+	base := process.NewBaseProcess("kube-controller-manager", cfg.Logger, cfg.StopTimeout)
+	base.SetLogStream(cfg.LogStream)
+	base.SetLogRotation(cfg.LogRotation)
+	return &Process{config: cfg, base: base}, nil
+}
+
+// Start launches the kube-controller-manager process. kube-apiserver must be
+// ready and the kubeconfig, CA and service account key written.
+func (p *Process) Start(ctx context.Context) error {
+	if p.base.IsStarted() {
+		return process.ErrAlreadyStarted
+	}
+
+	cmd := exec.CommandContext( //nolint:gosec // G204: binary path is from config, not user input
+		ctx,
+		p.config.Binary,
+		p.buildArgs()...)
+	if err := p.base.SetupAndStart(cmd, p.config.DataDir); err != nil {
+		return fmt.Errorf("setup and start controller manager process: %w", err)
+	}
+	return nil
+}
+
+// buildArgs constructs the kube-controller-manager command-line arguments.
+func (p *Process) buildArgs() []string {
+	controllers := p.config.Controllers
+	if len(controllers) == 0 {
+		controllers = DefaultControllers
+	}
+	return []string{
+		// Connection and delegated authentication/authorization for the
+		// serving endpoint all use the admin kubeconfig.
+		"--kubeconfig=" + p.config.KubeconfigPath,
+		"--authentication-kubeconfig=" + p.config.KubeconfigPath,
+		"--authorization-kubeconfig=" + p.config.KubeconfigPath,
+		// Do not fail startup if the client CA ConfigMap is not yet
+		// published; /healthz is served anonymously either way.
+		"--authentication-tolerate-lookup-failure=true",
+
+		// Serving: loopback only, with a self-signed certificate written to
+		// the data directory.
+		"--bind-address=127.0.0.1",
+		fmt.Sprintf("--secure-port=%d", p.config.Port),
+		"--cert-dir=" + filepath.Join(p.config.DataDir, "controller-manager-certs"),
+
+		"--controllers=" + strings.Join(controllers, ","),
+
+		// A single controller manager per apiserver: skip leader election,
+		// which would otherwise delay controllers by the lease duration.
+		"--leader-elect=false",
+
+		"--root-ca-file=" + p.config.CAFile,
+		"--service-account-private-key-file=" + p.config.ServiceAccountKeyFile,
+
+		// Logging
+		"--v=2",
+	}
+}
+
+// WaitReady polls the /healthz endpoint until it returns 200.
+func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			// kube-controller-manager serves a self-signed certificate
+			// generated at startup, so there is no CA to verify it against.
+			// The connection never leaves the loopback interface.
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, //nolint:gosec // G402: self-signed loopback health check
+				MinVersion:         tls.VersionTLS12,
+			},
+			DisableKeepAlives: true,
+		},
+		Timeout: healthCheckTimeout,
+	}
+	defer httpClient.CloseIdleConnections()
+
+	healthURL := fmt.Sprintf("https://127.0.0.1:%d/healthz", p.config.Port)
+
+	log := p.base.Logger()
+	if err := process.WaitReady(ctx, process.WaitReadyConfig{
+		Interval:      readinessPollInterval,
+		Timeout:       timeout,
+		Name:          "kube-controller-manager",
+		Port:          p.config.Port,
+		Logger:        log,
+		ProcessExited: p.base.Exited(),
+	}, func(checkCtx context.Context, attempt int) (bool, error) {
+		req, err := http.NewRequestWithContext(checkCtx, http.MethodGet, healthURL, http.NoBody)
+		if err != nil {
+			return false, fmt.Errorf("create health check request: %w", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			if log.Enabled(checkCtx, slog.LevelDebug) {
+				log.Debug("waitForControllerManager attempt", "port", p.config.Port, "attempt", attempt, "error", err)
+			}
+			return false, nil
+		}
+		defer func() {
+			_, _ = io.Copy(io.Discard, resp.Body) // best-effort drain
+			_ = resp.Body.Close()
+		}()
+
+		if resp.StatusCode == http.StatusOK {
+			return true, nil
+		}
+		if log.Enabled(checkCtx, slog.LevelDebug) {
+			log.Debug("waitForControllerManager attempt", "port", p.config.Port, "attempt", attempt, "status", resp.StatusCode)
+		}
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("controller manager not ready: %w", err)
+	}
+	return nil
+}
+
+// Stop terminates the kube-controller-manager process with the given timeout.
+func (p *Process) Stop(timeout time.Duration) error {
+	return p.base.Stop(timeout)
+}
+
+// Close releases log file handles held by the process.
+func (p *Process) Close() {
+	p.base.Close()
+}
+
+// Name returns the process name, "kube-controller-manager", which also prefixes its log files.
+func (p *Process) Name() string {
+	return p.base.Name()
+}
+
+// ExitCode returns the exit code of a kube-controller-manager process that exited on its own,
+// or -1 while it runs. See [process.BaseProcess.ExitCode].
+func (p *Process) ExitCode() int {
+	return p.base.ExitCode()
+}
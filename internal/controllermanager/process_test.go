@@ -0,0 +1,79 @@
+package controllermanager
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func validConfig() Config {
+	return Config{
+		Binary:                "kube-controller-manager",
+		DataDir:               "/tmp/inst",
+		Port:                  10257,
+		KubeconfigPath:        "/tmp/inst/kubeconfig.yaml",
+		CAFile:                "/tmp/inst/certs/ca.crt",
+		ServiceAccountKeyFile: "/tmp/inst/certs/sa.key",
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := validConfig().validate(); err != nil {
+		t.Fatalf("valid config: unexpected error: %v", err)
+	}
+
+	err := Config{Controllers: []string{""}}.validate()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, part := range []string{
+		"binary path", "data dir", "port", "kubeconfig path", "CA file",
+		"service account key file", "controller name",
+	} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should mention %q", err.Error(), part)
+		}
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		controllers []string
+		want        string
+	}{
+		"default controllers": {want: "--controllers=namespace,garbagecollector,serviceaccount-token"},
+		"selected controllers": {
+			controllers: []string{"namespace", "garbagecollector"},
+			want:        "--controllers=namespace,garbagecollector",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cfg := validConfig()
+			cfg.Controllers = tc.controllers
+			p, err := New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := p.buildArgs()
+			for _, want := range []string{
+				tc.want,
+				"--kubeconfig=/tmp/inst/kubeconfig.yaml",
+				"--secure-port=10257",
+				"--leader-elect=false",
+				"--root-ca-file=/tmp/inst/certs/ca.crt",
+				"--service-account-private-key-file=/tmp/inst/certs/sa.key",
+			} {
+				if !slices.Contains(args, want) {
+					t.Errorf("buildArgs() = %q, missing %q", args, want)
+				}
+			}
+		})
+	}
+}
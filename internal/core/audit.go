@@ -0,0 +1,68 @@
+package core
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/giantswarm/k8senv/internal/audit"
+	"github.com/giantswarm/k8senv/internal/sentinel"
+)
+
+// ErrAuditNotEnabled is returned by AuditEvents when the manager was created
+// without an audit policy.
+const ErrAuditNotEnabled = sentinel.Error("audit logging not enabled")
+
+// auditLog accumulates the audit events delivered for one instance during
+// the current lease. The audit receiver appends from HTTP handler
+// goroutines while the lease holder reads, so access is mutex-guarded.
+type auditLog struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+// append records events delivered by the audit receiver. It is the
+// audit.Sink registered for the instance.
+func (l *auditLog) append(events []audit.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, events...)
+}
+
+// reset discards all recorded events, starting a new lease.
+func (l *auditLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = nil
+}
+
+// snapshot returns a copy of the recorded events.
+func (l *auditLog) snapshot() []audit.Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.events)
+}
+
+// AuditEvents returns the audit events kube-apiserver emitted for this
+// instance since it was acquired, in delivery order. It must be called while
+// the instance is acquired (between Acquire and Release).
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrAuditNotEnabled if no audit policy is configured.
+func (i *Instance) AuditEvents() ([]audit.Event, error) {
+	if !i.IsBusy() {
+		return nil, ErrInstanceReleased
+	}
+	if i.audit == nil {
+		return nil, ErrAuditNotEnabled
+	}
+	return i.audit.snapshot(), nil
+}
+
+// recordAudit is the audit.Sink for this instance. Events are recorded
+// regardless of lease state; beginLease discards anything delivered before
+// the current acquisition (e.g., requests made during startup).
+func (i *Instance) recordAudit(events []audit.Event) {
+	if i.audit != nil {
+		i.audit.append(events)
+	}
+}
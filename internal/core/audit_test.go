@@ -0,0 +1,121 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/audit"
+	"github.com/giantswarm/k8senv/internal/netutil"
+)
+
+// newAuditTestInstance creates an Instance with an audit policy configured so
+// that NewInstance allocates an audit log. receiver may be nil.
+func newAuditTestInstance(t *testing.T, receiver *audit.Receiver) *Instance {
+	t.Helper()
+	cfg := validInstanceConfig()
+	cfg.APIServerOptions.AuditPolicyFile = "/etc/audit-policy.yaml"
+	return NewInstance(NewInstanceParams{
+		ID:       "test-inst",
+		DataDir:  t.TempDir(),
+		Releaser: &fakeReleaser{},
+		Ports:    netutil.NewPortRegistry(),
+		Config:   cfg,
+
+		AuditReceiver: receiver,
+	})
+}
+
+func TestInstanceAuditEventsNotEnabled(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	inst.markAcquired()
+
+	if _, err := inst.AuditEvents(); !errors.Is(err, ErrAuditNotEnabled) {
+		t.Errorf("AuditEvents() error = %v, want ErrAuditNotEnabled", err)
+	}
+}
+
+func TestInstanceAuditEventsReleased(t *testing.T) {
+	t.Parallel()
+
+	inst := newAuditTestInstance(t, nil)
+
+	if _, err := inst.AuditEvents(); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("AuditEvents() error = %v, want ErrInstanceReleased", err)
+	}
+}
+
+// TestInstanceAuditEventsScopedToLease verifies that events delivered before
+// the current lease began are discarded and later events are returned in order.
+func TestInstanceAuditEventsScopedToLease(t *testing.T) {
+	t.Parallel()
+
+	inst := newAuditTestInstance(t, nil)
+	inst.recordAudit([]audit.Event{{AuditID: "startup"}})
+
+	inst.markAcquired()
+	if err := inst.beginLease(); err != nil {
+		t.Fatal(err)
+	}
+	inst.recordAudit([]audit.Event{{AuditID: "a"}, {AuditID: "b"}})
+
+	events, err := inst.AuditEvents()
+	if err != nil {
+		t.Fatalf("AuditEvents() unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].AuditID != "a" || events[1].AuditID != "b" {
+		t.Errorf("AuditEvents() = %+v, want events a, b", events)
+	}
+}
+
+// TestInstanceStopUnregistersAudit verifies that Stop removes the instance
+// from the audit receiver, so events posted for a stopped instance are no
+// longer recorded and the receiver does not keep stale sinks.
+func TestInstanceStopUnregistersAudit(t *testing.T) {
+	t.Parallel()
+
+	receiver, err := audit.NewReceiver(Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = receiver.Close() })
+
+	inst := newAuditTestInstance(t, receiver)
+	// doStart registers the instance before launching kube-apiserver.
+	receiver.Register(inst.id, inst.recordAudit)
+	inst.markAcquired()
+	if err := inst.beginLease(); err != nil {
+		t.Fatal(err)
+	}
+
+	postEvent := func(id string) {
+		t.Helper()
+		body := `{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[{"auditID":"` + id + `"}]}`
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, receiver.URL(inst.id), strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	postEvent("before-stop")
+	if err := inst.Stop(t.Context()); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	postEvent("after-stop")
+
+	events, err := inst.AuditEvents()
+	if err != nil {
+		t.Fatalf("AuditEvents() unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].AuditID != "before-stop" {
+		t.Errorf("AuditEvents() = %+v, want only the event posted before Stop", events)
+	}
+}
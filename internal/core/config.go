@@ -1,9 +1,20 @@
 package core
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"time"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
+	"github.com/giantswarm/k8senv/internal/nodesim"
+	"github.com/giantswarm/k8senv/internal/process"
+	"k8s.io/apimachinery/pkg/util/version"
 )
 
 // ManagerConfig holds configuration for Manager instances.
@@ -55,6 +66,136 @@ type ManagerConfig struct {
 	// in-flight ReleaseToPool operations to complete before proceeding
 	// with instance teardown. Default: 30 seconds.
 	ShutdownDrainTimeout time.Duration
+
+	// EnableAdmissionPlugins and DisableAdmissionPlugins are passed to
+	// kube-apiserver's --enable-admission-plugins and
+	// --disable-admission-plugins flags. ServiceAccount stays disabled
+	// unless it is listed in EnableAdmissionPlugins. Default: empty.
+	EnableAdmissionPlugins  []string
+	DisableAdmissionPlugins []string
+
+	// AdmissionConfigPath is an AdmissionConfiguration file passed via
+	// --admission-control-config-file. Relative paths are resolved against
+	// the working directory during Initialize. Default: empty (none).
+	AdmissionConfigPath string
+
+	// AdmissionConfig is an AdmissionConfiguration document written to each
+	// instance's data directory instead of AdmissionConfigPath. Default:
+	// empty (none).
+	AdmissionConfig []byte
+
+	// AuditPolicyPath is an audit Policy file passed via --audit-policy-file.
+	// When set, audit events are delivered to an in-process webhook receiver
+	// and exposed per lease. Default: empty (auditing disabled).
+	AuditPolicyPath string
+
+	// AuditPolicy is an audit Policy document written to each instance's
+	// data directory instead of AuditPolicyPath. Default: empty (none).
+	AuditPolicy []byte
+
+	// EncryptionProvider enables encryption at rest for EncryptionResources
+	// using a key generated during Initialize. One of apiserver.EncryptionAESCBC
+	// or apiserver.EncryptionSecretbox. Default: empty (no encryption).
+	EncryptionProvider string
+
+	// EncryptionResources lists the resources encrypted by EncryptionProvider.
+	// Default: empty, which means "secrets" when EncryptionProvider is set.
+	EncryptionResources []string
+
+	// JWTAuthenticator enables JWT authentication against an OIDC issuer
+	// started by Initialize, with the given audiences, claim mappings and
+	// validation rules. Default: nil (JWT authentication disabled).
+	JWTAuthenticator *apiserver.JWTAuthenticator
+
+	// Authorizer serves the authorization webhook consulted before
+	// AlwaysAllow. Initialize starts it on a loopback listener. Default: nil
+	// (AlwaysAllow only).
+	Authorizer http.Handler
+
+	// AuthorizerMatchConditions are CEL expressions selecting the requests
+	// sent to Authorizer. Default: empty (all requests).
+	AuthorizerMatchConditions []string
+
+	// ClientCertAuth makes instance configs authenticate with an admin
+	// client certificate instead of the static bearer token. Default: false.
+	ClientCertAuth bool
+
+	// ServiceAccounts keeps the ServiceAccount admission plugin enabled, for
+	// use with Instance.ServiceAccountConfig. Default: false.
+	ServiceAccounts bool
+
+	// ControllerManagerBinary, when set, runs kube-controller-manager next to
+	// every instance's kube-apiserver. Controllers selects the controllers;
+	// empty uses controllermanager.DefaultControllers. Default: empty (no
+	// controller manager).
+	ControllerManagerBinary string
+	Controllers             []string
+
+	// InProcessControllers runs a lightweight namespace lifecycle controller
+	// and ownerReference garbage collector in the test process for each
+	// lease. Mutually exclusive with ControllerManagerBinary. Default: false.
+	InProcessControllers bool
+
+	// FakeNodes, when non-empty, registers these Nodes for each lease and
+	// runs the node simulator, which schedules Pods onto them and drives
+	// their status according to PodRules. PodRules requires FakeNodes.
+	// Default: empty (no nodes; Pods stay Pending).
+	FakeNodes []nodesim.Node
+	PodRules  []nodesim.PodRule
+
+	// KeepOnFailure keeps the instance of a failed test running, unpurged
+	// and out of the pool, when it is released through a testing helper,
+	// for up to MaxKeptInstances instances. Default: false, 1.
+	KeepOnFailure    bool
+	MaxKeptInstances int
+
+	// ArtifactsDir receives the diagnostics bundle written when an instance
+	// fails to start or to be purged on release. Default: empty, which
+	// writes bundles to a diagnostics directory in the instance data
+	// directory.
+	ArtifactsDir string
+
+	// ProcessLogs re-emits the output of kine, kube-apiserver and
+	// kube-controller-manager through Logger(), dropping lines below
+	// ProcessLogLevel. Default: false.
+	ProcessLogs     bool
+	ProcessLogLevel slog.Level
+
+	// APIServerVerbosity is the kube-apiserver klog verbosity (--v).
+	// Default: 2.
+	APIServerVerbosity int
+
+	// MaxLogSize caps the size in bytes of each process log file; a file
+	// reaching it is rotated, keeping MaxLogSegments rotated files.
+	// Default: 0 (unbounded), 0.
+	MaxLogSize     int64
+	MaxLogSegments int
+
+	// KubeAPIServerVersions maps Kubernetes versions (e.g. "1.33") to the
+	// kube-apiserver binary for that version. When set, NewVersionManagers
+	// derives one Manager per entry and KubeAPIServerBinary is unused.
+	// Default: nil (a single kube-apiserver).
+	KubeAPIServerVersions map[string]string
+
+	// KineTCP makes kine listen on a loopback TCP port instead of a unix
+	// socket in the instance data directory. Default: false (the socket is
+	// used where supported, TCP otherwise).
+	KineTCP bool
+
+	// NetworkNamespace runs each instance's kine and kube-apiserver in a
+	// Linux user and network namespace of their own, where kube-apiserver
+	// binds a fixed port; the test process reaches it through a forwarder
+	// on a loopback port. Linux only, and incompatible with KineTCP and
+	// with features kube-apiserver calls back into the test process for
+	// (audit policy, authorizer, JWT authenticator). Default: false.
+	NetworkNamespace bool
+
+	// PortLockDir holds per-port lock files shared by every k8senv process
+	// on the host, so concurrently running test binaries never allocate the
+	// same port. Default: empty, which coordinates allocations within the
+	// process only; NewManager sets a directory in the system temp
+	// directory.
+	PortLockDir string
 }
 
 // Validate checks all ManagerConfig invariants and returns an error describing
@@ -80,10 +221,172 @@ func (c ManagerConfig) Validate() error {
 	if c.PoolSize < 0 {
 		errs = append(errs, fmt.Errorf("pool size must not be negative, got %d", c.PoolSize))
 	}
+	if c.APIServerVerbosity < 0 {
+		errs = append(errs, fmt.Errorf("kube-apiserver verbosity must not be negative, got %d", c.APIServerVerbosity))
+	}
+	if c.MaxLogSize < 0 {
+		errs = append(errs, fmt.Errorf("max log size must not be negative, got %d", c.MaxLogSize))
+	}
+	if c.MaxLogSegments < 0 {
+		errs = append(errs, fmt.Errorf("max log segments must not be negative, got %d", c.MaxLogSegments))
+	}
+	for _, name := range c.EnableAdmissionPlugins {
+		if slices.Contains(c.DisableAdmissionPlugins, name) {
+			errs = append(errs, fmt.Errorf("admission plugin %q is both enabled and disabled", name))
+		}
+	}
+	if c.ServiceAccounts && slices.Contains(c.DisableAdmissionPlugins, apiserver.ServiceAccountAdmissionPlugin) {
+		errs = append(errs, fmt.Errorf("admission plugin %q must not be disabled when service accounts are enabled",
+			apiserver.ServiceAccountAdmissionPlugin))
+	}
+	switch c.EncryptionProvider {
+	case "", apiserver.EncryptionAESCBC, apiserver.EncryptionSecretbox:
+	default:
+		errs = append(errs, fmt.Errorf("unknown encryption provider %q", c.EncryptionProvider))
+	}
+	if len(c.Controllers) > 0 && c.ControllerManagerBinary == "" {
+		errs = append(errs, errors.New("controllers require a controller manager binary"))
+	}
+	if c.InProcessControllers && c.ControllerManagerBinary != "" {
+		errs = append(errs, errors.New("in-process controllers and a controller manager are mutually exclusive"))
+	}
+	if c.KeepOnFailure && c.MaxKeptInstances < 1 {
+		errs = append(errs, fmt.Errorf("max kept instances must be at least 1, got %d", c.MaxKeptInstances))
+	}
+	if len(c.PodRules) > 0 && len(c.FakeNodes) == 0 {
+		errs = append(errs, errors.New("pod rules require fake nodes"))
+	}
+	if err := nodesim.ValidateNodes(c.FakeNodes); err != nil {
+		errs = append(errs, err)
+	}
+	if err := nodesim.ValidateRules(c.PodRules); err != nil {
+		errs = append(errs, err)
+	}
+	if len(c.AuthorizerMatchConditions) > 0 && c.Authorizer == nil {
+		errs = append(errs, errors.New("authorizer match conditions require an authorizer"))
+	}
+	if c.JWTAuthenticator != nil {
+		if err := c.JWTAuthenticator.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.NetworkNamespace {
+		errs = append(errs, c.validateNetworkNamespace()...)
+	}
+	for label, binary := range c.KubeAPIServerVersions {
+		if _, err := version.ParseGeneric(label); err != nil {
+			errs = append(errs, fmt.Errorf("kube-apiserver version %q: %w", label, err))
+		}
+		if binary == "" {
+			errs = append(errs, fmt.Errorf("kube-apiserver binary path for version %q must not be empty", label))
+		}
+	}
 
 	return errors.Join(errs...)
 }
 
+// validateNetworkNamespace checks the options that cannot be combined with
+// NetworkNamespace: kube-apiserver cannot reach servers in the test process
+// from its namespace, and kine must listen on a unix socket. Platform
+// support is checked by Initialize instead, so that a NewManager shared by
+// tests on every platform does not panic.
+func (c ManagerConfig) validateNetworkNamespace() []error {
+	var errs []error
+	if c.KineTCP {
+		errs = append(errs, errors.New("network namespace and kine TCP are mutually exclusive"))
+	}
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"an audit policy", c.AuditPolicyPath != "" || len(c.AuditPolicy) > 0},
+		{"an authorizer", c.Authorizer != nil},
+		{"a JWT authenticator", c.JWTAuthenticator != nil},
+	} {
+		if f.set {
+			errs = append(errs, fmt.Errorf("network namespace cannot be used with %s, which kube-apiserver reaches in the test process", f.name))
+		}
+	}
+	return errs
+}
+
+// apiServerOptions derives the kube-apiserver options shared by every pool
+// instance. File paths are made absolute and checked for existence because
+// kube-apiserver runs with the instance data directory as its working
+// directory, and a missing file would otherwise surface only as a retried
+// startup failure.
+func (c ManagerConfig) apiServerOptions() (apiserver.Options, error) {
+	opts := apiserver.Options{
+		EnableAdmissionPlugins:  slices.Clone(c.EnableAdmissionPlugins),
+		DisableAdmissionPlugins: slices.Clone(c.DisableAdmissionPlugins),
+	}
+
+	if c.AdmissionConfigPath != "" {
+		path, err := resolveConfigFile(c.AdmissionConfigPath)
+		if err != nil {
+			return apiserver.Options{}, fmt.Errorf("admission config: %w", err)
+		}
+		opts.AdmissionConfigFile = path
+	}
+	opts.AdmissionConfig = slices.Clone(c.AdmissionConfig)
+
+	if c.AuditPolicyPath != "" {
+		path, err := resolveConfigFile(c.AuditPolicyPath)
+		if err != nil {
+			return apiserver.Options{}, fmt.Errorf("audit policy: %w", err)
+		}
+		opts.AuditPolicyFile = path
+	}
+	opts.AuditPolicy = slices.Clone(c.AuditPolicy)
+
+	if c.EncryptionProvider != "" {
+		key := make([]byte, apiserver.EncryptionKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return apiserver.Options{}, fmt.Errorf("generate encryption key: %w", err)
+		}
+		opts.EncryptionProvider = c.EncryptionProvider
+		opts.EncryptionResources = slices.Clone(c.EncryptionResources)
+		if len(opts.EncryptionResources) == 0 {
+			opts.EncryptionResources = []string{defaultEncryptionResource}
+		}
+		opts.EncryptionKey = key
+	}
+
+	opts.AuthorizationMatchConditions = slices.Clone(c.AuthorizerMatchConditions)
+	opts.ClientCertAuth = c.ClientCertAuth
+	opts.ServiceAccounts = c.ServiceAccounts
+	verbosity := c.APIServerVerbosity
+	opts.Verbosity = &verbosity
+
+	if c.JWTAuthenticator != nil {
+		jwt := *c.JWTAuthenticator
+		opts.JWT = &jwt
+	}
+
+	return opts, nil
+}
+
+// defaultEncryptionResource is encrypted when encryption at rest is enabled
+// without an explicit resource list.
+const defaultEncryptionResource = "secrets"
+
+// resolveConfigFile returns the absolute form of path after verifying that
+// it refers to an existing regular file.
+func resolveConfigFile(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("stat %s: %w", abs, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", abs)
+	}
+	return abs, nil
+}
+
 // InstanceConfig holds configuration for Instance objects.
 // All fields are immutable after construction via NewInstance.
 type InstanceConfig struct {
@@ -91,8 +394,10 @@ type InstanceConfig struct {
 	StartTimeout time.Duration
 	// StopTimeout is the maximum time per-process for graceful shutdown.
 	// This timeout is passed to [kubestack.Stack.Stop], which applies it
-	// independently to each of kube-apiserver and kine. The worst-case
-	// total stop duration is therefore 2*StopTimeout.
+	// independently to each of kube-apiserver and kine (and
+	// kube-controller-manager, when configured). The worst-case total stop
+	// duration is therefore 2*StopTimeout, or 3*StopTimeout with a
+	// controller manager.
 	StopTimeout time.Duration
 	// CleanupTimeout is the maximum time for SQLite purge during release.
 	CleanupTimeout time.Duration
@@ -104,6 +409,33 @@ type InstanceConfig struct {
 	CachedDBPath        string
 	KineBinary          string
 	KubeAPIServerBinary string
+	// APIServerOptions holds optional kube-apiserver features derived from
+	// ManagerConfig (admission plugins, ...). The zero value is the default.
+	APIServerOptions apiserver.Options
+	// ControllerManagerBinary and Controllers configure the optional
+	// kube-controller-manager. An empty binary runs none.
+	ControllerManagerBinary string
+	Controllers             []string
+	// InProcessControllers starts the lifecycle controller for every lease.
+	InProcessControllers bool
+	// FakeNodes and PodRules configure the node simulator started for every
+	// lease. No nodes runs none.
+	FakeNodes []nodesim.Node
+	PodRules  []nodesim.PodRule
+	// ArtifactsDir receives diagnostics bundles; empty uses the data
+	// directory.
+	ArtifactsDir string
+	// LogStream configures streaming of the process output through the
+	// instance logger. The zero value disables it.
+	LogStream process.LogStream
+	// LogRotation caps the size of the process log files. The zero value
+	// disables rotation.
+	LogRotation process.LogRotation
+	// KineTCP makes kine listen on a TCP port instead of a unix socket.
+	KineTCP bool
+	// NetworkNamespace runs kine and kube-apiserver in Linux network
+	// namespaces of their own.
+	NetworkNamespace bool
 }
 
 // Validate checks all InstanceConfig invariants and returns an error describing
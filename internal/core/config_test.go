@@ -1,10 +1,17 @@
 package core
 
 import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
+	"github.com/giantswarm/k8senv/internal/nodesim"
 )
 
 // Binary names used by the config fixtures below. They mirror the defaults in
@@ -95,10 +102,101 @@ func TestManagerConfigValidate(t *testing.T) {
 			modify:       func(c *ManagerConfig) { c.PoolSize = -1 },
 			wantContains: "pool size",
 		},
+		"negative kube-apiserver verbosity": {
+			modify:       func(c *ManagerConfig) { c.APIServerVerbosity = -1 },
+			wantContains: "verbosity",
+		},
+		"negative max log size": {
+			modify:       func(c *ManagerConfig) { c.MaxLogSize = -1 },
+			wantContains: "max log size",
+		},
+		"negative max log segments": {
+			modify:       func(c *ManagerConfig) { c.MaxLogSegments = -1 },
+			wantContains: "max log segments",
+		},
 		"zero shutdown drain timeout": {
 			modify:       func(c *ManagerConfig) { c.ShutdownDrainTimeout = 0 },
 			wantContains: "shutdown drain timeout",
 		},
+		"admission plugin enabled and disabled": {
+			modify: func(c *ManagerConfig) {
+				c.EnableAdmissionPlugins = []string{"PodSecurity"}
+				c.DisableAdmissionPlugins = []string{"PodSecurity"}
+			},
+			wantContains: "both enabled and disabled",
+		},
+		"invalid JWT authenticator": {
+			modify: func(c *ManagerConfig) {
+				c.JWTAuthenticator = &apiserver.JWTAuthenticator{
+					UserValidationRules: []apiserver.UserValidationRule{{}},
+				}
+			},
+			wantContains: "jwt user validation rule",
+		},
+		"service accounts with ServiceAccount plugin disabled": {
+			modify: func(c *ManagerConfig) {
+				c.ServiceAccounts = true
+				c.DisableAdmissionPlugins = []string{"ServiceAccount"}
+			},
+			wantContains: "service accounts are enabled",
+		},
+		"controllers without controller manager binary": {
+			modify:       func(c *ManagerConfig) { c.Controllers = []string{"namespace"} },
+			wantContains: "controller manager binary",
+		},
+		"in-process controllers with controller manager": {
+			modify: func(c *ManagerConfig) {
+				c.InProcessControllers = true
+				c.ControllerManagerBinary = "kube-controller-manager"
+			},
+			wantContains: "mutually exclusive",
+		},
+		"keep on failure without kept instances": {
+			modify:       func(c *ManagerConfig) { c.KeepOnFailure = true },
+			wantContains: "max kept instances",
+		},
+		"pod rules without fake nodes": {
+			modify:       func(c *ManagerConfig) { c.PodRules = []nodesim.PodRule{{ExitCode: 1}} },
+			wantContains: "require fake nodes",
+		},
+		"duplicate fake node": {
+			modify:       func(c *ManagerConfig) { c.FakeNodes = []nodesim.Node{{Name: "a"}, {Name: "a"}} },
+			wantContains: "duplicate fake node",
+		},
+		"authorizer match conditions without authorizer": {
+			modify:       func(c *ManagerConfig) { c.AuthorizerMatchConditions = []string{"true"} },
+			wantContains: "require an authorizer",
+		},
+		"unknown encryption provider": {
+			modify:       func(c *ManagerConfig) { c.EncryptionProvider = "aesgcm" },
+			wantContains: "encryption provider",
+		},
+		"unparsable kube-apiserver version": {
+			modify:       func(c *ManagerConfig) { c.KubeAPIServerVersions = map[string]string{"latest": "/opt/kube-apiserver"} },
+			wantContains: `kube-apiserver version "latest"`,
+		},
+		"empty versioned kube-apiserver binary": {
+			modify:       func(c *ManagerConfig) { c.KubeAPIServerVersions = map[string]string{"1.33": ""} },
+			wantContains: `binary path for version "1.33"`,
+		},
+		"network namespace with kine TCP": {
+			modify:       func(c *ManagerConfig) { c.NetworkNamespace, c.KineTCP = true, true },
+			wantContains: "network namespace and kine TCP",
+		},
+		"network namespace with audit policy": {
+			modify:       func(c *ManagerConfig) { c.NetworkNamespace, c.AuditPolicyPath = true, "audit.yaml" },
+			wantContains: "cannot be used with an audit policy",
+		},
+		"network namespace with audit policy object": {
+			modify:       func(c *ManagerConfig) { c.NetworkNamespace, c.AuditPolicy = true, []byte(`{"kind":"Policy"}`) },
+			wantContains: "cannot be used with an audit policy",
+		},
+		"network namespace with authorizer": {
+			modify: func(c *ManagerConfig) {
+				c.NetworkNamespace, c.Authorizer = true, http.NotFoundHandler()
+			},
+			wantContains: "cannot be used with an authorizer",
+		},
 	}
 
 	for name, tc := range tests {
@@ -274,7 +372,7 @@ func TestInstanceConfig_Validate(t *testing.T) {
 //  2. Update expectedFields below to match the new count
 func TestManagerConfigFieldCount(t *testing.T) {
 	t.Parallel()
-	const expectedFields = 12 // Update this when adding new fields to ManagerConfig.
+	const expectedFields = 42 // Update this when adding new fields to ManagerConfig.
 
 	actual := reflect.TypeFor[ManagerConfig]().NumField()
 	if actual != expectedFields {
@@ -293,7 +391,7 @@ func TestManagerConfigFieldCount(t *testing.T) {
 //  3. Update expectedFields below to match the new count
 func TestInstanceConfigFieldCount(t *testing.T) {
 	t.Parallel()
-	const expectedFields = 7 // Update this when adding new fields to InstanceConfig.
+	const expectedFields = 18 // Update this when adding new fields to InstanceConfig.
 
 	actual := reflect.TypeFor[InstanceConfig]().NumField()
 	if actual != expectedFields {
@@ -302,3 +400,78 @@ func TestInstanceConfigFieldCount(t *testing.T) {
 			actual, expectedFields)
 	}
 }
+
+func TestManagerConfigAPIServerOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolves admission config to absolute path", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "admission.yaml")
+		if err := os.WriteFile(path, []byte("kind: AdmissionConfiguration\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := ManagerConfig{
+			EnableAdmissionPlugins: []string{"EventRateLimit"},
+			AdmissionConfigPath:    path,
+		}
+		opts, err := cfg.apiServerOptions()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.AdmissionConfigFile != path {
+			t.Errorf("AdmissionConfigFile = %q, want %q", opts.AdmissionConfigFile, path)
+		}
+		if !reflect.DeepEqual(opts.EnableAdmissionPlugins, cfg.EnableAdmissionPlugins) {
+			t.Errorf("EnableAdmissionPlugins = %v, want %v", opts.EnableAdmissionPlugins, cfg.EnableAdmissionPlugins)
+		}
+	})
+
+	t.Run("copies admission config object", func(t *testing.T) {
+		t.Parallel()
+		cfg := ManagerConfig{AdmissionConfig: []byte(`{"kind":"AdmissionConfiguration"}`)}
+		opts, err := cfg.apiServerOptions()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(opts.AdmissionConfig) != string(cfg.AdmissionConfig) || opts.AdmissionConfigFile != "" {
+			t.Errorf("AdmissionConfig = %q, AdmissionConfigFile = %q, want the object only",
+				opts.AdmissionConfig, opts.AdmissionConfigFile)
+		}
+	})
+
+	t.Run("copies audit policy object", func(t *testing.T) {
+		t.Parallel()
+		cfg := ManagerConfig{AuditPolicy: []byte(`{"kind":"Policy"}`)}
+		opts, err := cfg.apiServerOptions()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(opts.AuditPolicy) != string(cfg.AuditPolicy) || opts.AuditPolicyFile != "" || !opts.AuditEnabled() {
+			t.Errorf("AuditPolicy = %q, AuditPolicyFile = %q, want the object only", opts.AuditPolicy, opts.AuditPolicyFile)
+		}
+	})
+
+	t.Run("encryption defaults to secrets with a generated key", func(t *testing.T) {
+		t.Parallel()
+		cfg := ManagerConfig{EncryptionProvider: apiserver.EncryptionAESCBC}
+		opts, err := cfg.apiServerOptions()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(opts.EncryptionResources, []string{"secrets"}) {
+			t.Errorf("EncryptionResources = %v, want [secrets]", opts.EncryptionResources)
+		}
+		if len(opts.EncryptionKey) != apiserver.EncryptionKeySize {
+			t.Errorf("len(EncryptionKey) = %d, want %d", len(opts.EncryptionKey), apiserver.EncryptionKeySize)
+		}
+	})
+
+	t.Run("missing admission config", func(t *testing.T) {
+		t.Parallel()
+		cfg := ManagerConfig{AdmissionConfigPath: filepath.Join(t.TempDir(), "missing.yaml")}
+		if _, err := cfg.apiServerOptions(); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("apiServerOptions() error = %v, want os.ErrNotExist", err)
+		}
+	})
+}
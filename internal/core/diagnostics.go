@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/k8senv/internal/fileutil"
+	"github.com/giantswarm/k8senv/internal/kubestack"
+	"github.com/giantswarm/k8senv/internal/process"
+)
+
+// maxLogTail is the number of bytes kept from the end of each process log in
+// a diagnostics bundle.
+const maxLogTail = 1 << 20
+
+// versionTimeout bounds each "<binary> --version" call, made during
+// Initialize and for diagnostics bundles.
+const versionTimeout = 10 * time.Second
+
+// DiagnosticsError wraps an instance startup or release failure with the
+// directory of the diagnostics bundle written for it. Use errors.As to
+// retrieve Dir, for example to upload it as a CI artifact.
+type DiagnosticsError struct {
+	// Err is the failure the bundle was written for.
+	Err error
+	// Dir is the bundle directory.
+	Dir string
+}
+
+// Error returns the failure message followed by the bundle directory.
+func (e *DiagnosticsError) Error() string {
+	return fmt.Sprintf("%v (diagnostics: %s)", e.Err, e.Dir)
+}
+
+// Unwrap returns the failure the bundle was written for.
+func (e *DiagnosticsError) Unwrap() error {
+	return e.Err
+}
+
+// withDiagnostics writes a diagnostics bundle for cause and returns cause
+// wrapped in a *DiagnosticsError referencing it. Cancellation by the caller
+// is not a failure worth a bundle, so cause is returned unchanged. A bundle
+// that could only be written in part is still referenced; what is missing
+// is logged.
+func (i *Instance) withDiagnostics(cause error) error {
+	if errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	dir, err := i.writeDiagnostics(cause)
+	if dir == "" {
+		i.log.Warn("write diagnostics bundle", "error", err)
+		return cause
+	}
+	if err != nil {
+		i.log.Warn("diagnostics bundle incomplete", "dir", dir, "error", err)
+	}
+	i.log.Info("wrote diagnostics bundle", "dir", dir)
+	return &DiagnosticsError{Err: cause, Dir: dir}
+}
+
+// writeDiagnostics collects the state of the instance into a new directory
+// under the artifacts directory (the instance data directory when none is
+// configured) and returns its path. The bundle holds:
+//
+//   - error.txt: the failure message
+//   - the last maxLogTail bytes of every process log and of its newest
+//     rotated segment
+//   - the command line of every process, as started
+//   - ports.json: the allocated ports
+//   - kubeconfig.yaml
+//   - a snapshot of the kine SQLite database, taken with VACUUM INTO
+//   - versions.txt: the --version output of every binary
+//
+// Missing files are skipped, since a startup can fail before they are
+// written. The returned path is empty if the directory could not be created.
+func (i *Instance) writeDiagnostics(cause error) (string, error) {
+	root := i.cfg.ArtifactsDir
+	if root == "" {
+		root = filepath.Join(i.dataDir, "diagnostics")
+	}
+	dir := filepath.Join(root, i.id+"-"+time.Now().Format("20060102-150405.000"))
+	if err := fileutil.EnsureDir(dir); err != nil {
+		return "", fmt.Errorf("create diagnostics dir: %w", err)
+	}
+
+	var errs []error
+	if err := os.WriteFile(filepath.Join(dir, "error.txt"), []byte(cause.Error()+"\n"), 0o600); err != nil {
+		errs = append(errs, err)
+	}
+
+	var logs []string
+	for _, pattern := range []string{"*.log", process.SegmentFile("*.log", 1)} {
+		matches, err := filepath.Glob(filepath.Join(i.dataDir, pattern))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		logs = append(logs, matches...)
+	}
+	for _, path := range logs {
+		if err := copyTail(path, filepath.Join(dir, filepath.Base(path)), maxLogTail); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	cmdlines, err := filepath.Glob(filepath.Join(i.dataDir, "*-cmdline.txt"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	copies := append(cmdlines,
+		filepath.Join(i.dataDir, kubestack.PortsFile),
+		i.kubeconfig,
+	)
+	for _, path := range copies {
+		err := fileutil.CopyFile(path, filepath.Join(dir, filepath.Base(path)), &fileutil.CopyFileOptions{Mode: 0o600})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	err = snapshotSQLite(context.Background(), i.sqlitePath, filepath.Join(dir, filepath.Base(i.sqlitePath)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "versions.txt"), []byte(i.binaryVersions()), 0o600); err != nil {
+		errs = append(errs, err)
+	}
+
+	return dir, errors.Join(errs...)
+}
+
+// binaryVersions returns one "<binary>: <version>" line per configured
+// binary, reporting the error instead of the version for binaries that
+// cannot be run.
+func (i *Instance) binaryVersions() string {
+	binaries := []string{i.cfg.KineBinary, i.cfg.KubeAPIServerBinary}
+	if i.cfg.ControllerManagerBinary != "" {
+		binaries = append(binaries, i.cfg.ControllerManagerBinary)
+	}
+
+	var b strings.Builder
+	for _, binary := range binaries {
+		version, err := runVersion(context.Background(), binary)
+		if err != nil {
+			version = fmt.Sprintf("error: %v", err)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", binary, version)
+	}
+	return b.String()
+}
+
+// copyTail copies the last n bytes of src to dst. A missing src is not an
+// error.
+func copyTail(src, dst string, n int64) error {
+	in, err := openTail(src, n)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // G304: path is in the bundle directory
+	if err != nil {
+		return fmt.Errorf("create log tail: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("copy log tail: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close log tail: %w", err)
+	}
+	return nil
+}
+
+// openTail opens path positioned n bytes before its end, or at its start if
+// it is shorter.
+func openTail(path string, n int64) (*os.File, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: paths are from the instance data directory
+	if err != nil {
+		return nil, fmt.Errorf("open log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("stat log: %w", err)
+	}
+	if size := info.Size(); size > n {
+		if _, err := f.Seek(size-n, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("seek log: %w", err)
+		}
+	}
+	return f, nil
+}
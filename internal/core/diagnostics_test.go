@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/netutil"
+)
+
+func TestStartFailureWritesDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	cfg := validInstanceConfig()
+	cfg.KineBinary = "/nonexistent/kine"
+	cfg.ArtifactsDir = t.TempDir()
+	inst := NewInstance(NewInstanceParams{
+		ID:       "test-inst",
+		DataDir:  t.TempDir(),
+		Releaser: &fakeReleaser{},
+		Ports:    netutil.NewPortRegistry(),
+		Config:   cfg,
+	})
+
+	err := inst.Start(context.Background())
+	var diagErr *DiagnosticsError
+	if !errors.As(err, &diagErr) {
+		t.Fatalf("Start() error = %v, want *DiagnosticsError", err)
+	}
+	if filepath.Dir(diagErr.Dir) != cfg.ArtifactsDir {
+		t.Errorf("bundle dir = %s, want it in %s", diagErr.Dir, cfg.ArtifactsDir)
+	}
+	if !strings.Contains(err.Error(), diagErr.Dir) {
+		t.Errorf("error %q does not reference the bundle", err)
+	}
+
+	msg, readErr := os.ReadFile(filepath.Join(diagErr.Dir, "error.txt"))
+	if readErr != nil || !strings.Contains(string(msg), "kine binary not found") {
+		t.Errorf("error.txt = %q, %v; want the start error", msg, readErr)
+	}
+	versions, readErr := os.ReadFile(filepath.Join(diagErr.Dir, "versions.txt"))
+	if readErr != nil || !strings.Contains(string(versions), "/nonexistent/kine: error:") {
+		t.Errorf("versions.txt = %q, %v; want an error line for the missing binary", versions, readErr)
+	}
+}
+
+func TestStartCanceledWritesNoDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := inst.Start(ctx)
+	var diagErr *DiagnosticsError
+	if err == nil || errors.As(err, &diagErr) {
+		t.Errorf("Start(canceled) error = %v, want a plain error", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(inst.dataDir, "diagnostics")); !errors.Is(statErr, os.ErrNotExist) {
+		t.Errorf("diagnostics dir exists after cancellation: %v", statErr)
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	files := map[string]string{
+		"kine-stderr.log":            "kine output\n",
+		"kine-stderr.log.1":          "rotated kine output\n",
+		"kube-apiserver-cmdline.txt": "kube-apiserver --secure-port=1\n",
+		"ports.json":                 `{"kine":1,"apiserver":2}`,
+		"kubeconfig.yaml":            "apiVersion: v1\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(inst.dataDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Keep a writer open in WAL mode, as kine does, so the row only lives
+	// in the -wal file when the bundle is written.
+	if err := os.MkdirAll(filepath.Dir(inst.sqlitePath), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	db := openTestKineDB(t, inst.sqlitePath+"?_pragma=journal_mode(WAL)")
+	if _, err := db.Exec(`INSERT INTO kine (name, created, deleted, create_revision, value)
+		VALUES ('/registry/configmaps/default/a', 1, 0, 0, 'v1')`); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := inst.writeDiagnostics(errors.New("boom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(dir) != filepath.Join(inst.dataDir, "diagnostics") {
+		t.Errorf("bundle dir = %s, want it in the data dir", dir)
+	}
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.Base(name)))
+		if err != nil || string(got) != content {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, content)
+		}
+	}
+	for _, name := range []string{"state.db-wal", "state.db-shm"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("bundle has %s: %v; want a self-contained snapshot", name, err)
+		}
+	}
+	records, err := readRawStorage(t.Context(), filepath.Join(dir, "state.db"))
+	if err != nil || len(records) != 1 || records[0].Key != "/registry/configmaps/default/a" {
+		t.Errorf("snapshot records = %+v, %v; want configmap a", records, err)
+	}
+}
+
+func TestCopyTail(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.log")
+	if err := os.WriteFile(src, []byte("0123456789"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "dst.log")
+	if err := copyTail(src, dst, 4); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "6789" {
+		t.Errorf("tail = %q, want 6789", got)
+	}
+	if err := copyTail(filepath.Join(dir, "missing.log"), dst, 4); err != nil {
+		t.Errorf("copyTail(missing) = %v, want nil", err)
+	}
+}
@@ -10,9 +10,14 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/giantswarm/k8senv/internal/audit"
 	"github.com/giantswarm/k8senv/internal/fileutil"
 	"github.com/giantswarm/k8senv/internal/kubestack"
+	"github.com/giantswarm/k8senv/internal/lifecycle"
 	"github.com/giantswarm/k8senv/internal/netutil"
+	"github.com/giantswarm/k8senv/internal/nodesim"
+	"github.com/giantswarm/k8senv/internal/oidc"
+	"github.com/giantswarm/k8senv/internal/process"
 	"github.com/giantswarm/k8senv/internal/sentinel"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -79,6 +84,12 @@ type InstanceReleaser interface {
 	// from the pool. The token is the generation value from markAcquired.
 	// The instance is stopped and never returned to the free channel.
 	ReleaseFailed(i *Instance, token uint64)
+
+	// Keep detaches the instance from the pool without stopping it, so it
+	// can be inspected after its test failed. Returns false, leaving the
+	// instance acquired, if keeping is disabled, the limit of kept
+	// instances is reached or the manager is shutting down.
+	Keep(i *Instance, token uint64) bool
 }
 
 // clientCache groups lazily-built Kubernetes clients that share the same
@@ -142,6 +153,10 @@ type Instance struct {
 	cancel context.CancelFunc
 	// stack is the kine + kube-apiserver process stack. Protected by startMu only.
 	stack *kubestack.Stack
+	// launches counts how often doStart has launched kine and kube-apiserver
+	// across its namespace retry rounds, reported as StartError.Attempts.
+	// Reset by doStart. Protected by startMu only.
+	launches int
 
 	// purge is a persistent SQLite connection and prepared DELETE statement
 	// for purge operations. Opened eagerly during startup (after system
@@ -152,6 +167,39 @@ type Instance struct {
 	// between Acquire and Release.
 	purge *purgeHandle
 
+	// audit holds the audit events of the current lease. nil when no audit
+	// policy is configured. Set once at construction.
+	audit *auditLog
+
+	// auditReceiver routes the audit events posted for this instance to
+	// recordAudit while it runs: doStart registers the instance and Stop
+	// unregisters it, both under startMu. nil when no audit policy is
+	// configured. Set once at construction.
+	auditReceiver *audit.Receiver
+
+	// issuer signs tokens for ConfigForJWTClaims. nil when no JWT
+	// authenticator is configured. Set once at construction.
+	issuer *oidc.Issuer
+
+	// controllers is the in-process namespace and garbage collection
+	// controller of the current lease. Started by beginLease and swapped
+	// out by stopControllers, which Release and Stop call.
+	controllers atomic.Pointer[lifecycle.Controller]
+
+	// discovered caches API discovery for the in-process controllers. A
+	// purge leaves the served resources unchanged, so discovery runs once
+	// per started process rather than once per lease; Stop clears it.
+	discovered atomic.Pointer[lifecycle.Resources]
+
+	// nodes is the node simulator of the current lease, managed like
+	// controllers.
+	nodes atomic.Pointer[nodesim.Simulator]
+
+	// logMarks holds the size and rotation count of each process log file
+	// when the current lease began, keyed by path. Written by beginLease and read by the
+	// holder, which the pool contract serializes.
+	logMarks map[string]logMark
+
 	// log is the instance-scoped logger.
 	log *slog.Logger
 }
@@ -225,13 +273,22 @@ func (i *Instance) setErr(e error) {
 }
 
 // NewInstanceParams holds the parameters for creating a new Instance.
-// All fields are required.
+// All fields except TokenIssuer and AuditReceiver are required.
 type NewInstanceParams struct {
 	ID       string
 	DataDir  string
 	Releaser InstanceReleaser
 	Ports    *netutil.PortRegistry
 	Config   InstanceConfig
+
+	// TokenIssuer signs tokens for ConfigForJWTClaims. It must be the issuer
+	// configured in Config.APIServerOptions; nil when JWT authentication is
+	// disabled.
+	TokenIssuer *oidc.Issuer
+
+	// AuditReceiver receives the audit events posted to
+	// Config.APIServerOptions.AuditWebhookURL; nil when auditing is disabled.
+	AuditReceiver *audit.Receiver
 }
 
 // NewInstance creates a new Instance from the given parameters.
@@ -255,7 +312,7 @@ func NewInstance(params NewInstanceParams) *Instance {
 	if err := params.Config.Validate(); err != nil {
 		panic(fmt.Sprintf("k8senv: invalid instance config: %v", err))
 	}
-	return &Instance{
+	inst := &Instance{
 		cfg:        params.Config,
 		id:         params.ID,
 		dataDir:    params.DataDir,
@@ -263,7 +320,85 @@ func NewInstance(params NewInstanceParams) *Instance {
 		kubeconfig: filepath.Join(params.DataDir, "kubeconfig.yaml"),
 		releaser:   params.Releaser,
 		ports:      params.Ports,
-		log:        Logger().With("id", params.ID),
+		issuer:     params.TokenIssuer,
+
+		auditReceiver: params.AuditReceiver,
+		log:           Logger().With("id", params.ID),
+	}
+	if params.Config.APIServerOptions.AuditEnabled() {
+		inst.audit = &auditLog{}
+	}
+	if inst.cfg.LogRotation.Enabled() {
+		// Counted per instance and kept across restarts, so log windows
+		// can follow their files through rotations.
+		inst.cfg.LogRotation.Counter = &process.RotationCounter{}
+	}
+	return inst
+}
+
+// beginLease resets per-lease state after the instance has been acquired and
+// started, so that the new holder only observes activity from its own lease,
+// and starts the in-process controllers and node simulator when configured.
+// Called by Manager.Acquire; the pool contract guarantees no other holder.
+// On error, anything already started is stopped again.
+func (i *Instance) beginLease() error {
+	i.markLogs()
+	if i.audit != nil {
+		i.audit.reset()
+	}
+	if !i.cfg.InProcessControllers && len(i.cfg.FakeNodes) == 0 {
+		return nil
+	}
+	cfg, err := i.getOrBuildRestConfig()
+	if err != nil {
+		return fmt.Errorf("build config for in-process controllers: %w", err)
+	}
+	if i.cfg.InProcessControllers {
+		res, err := i.getOrDiscoverResources(cfg)
+		if err != nil {
+			return fmt.Errorf("start in-process controllers: %w", err)
+		}
+		ctrl, err := lifecycle.Start(cfg, res, i.log)
+		if err != nil {
+			return fmt.Errorf("start in-process controllers: %w", err)
+		}
+		i.controllers.Store(ctrl)
+	}
+	if len(i.cfg.FakeNodes) > 0 {
+		sim, err := nodesim.Start(cfg, i.cfg.FakeNodes, i.cfg.PodRules, i.log)
+		if err != nil {
+			i.stopControllers()
+			return fmt.Errorf("start node simulator: %w", err)
+		}
+		i.nodes.Store(sim)
+	}
+	return nil
+}
+
+// getOrDiscoverResources returns the cached API discovery result, running
+// discovery on first use after a start. Only the lease holder calls it, so
+// a plain Load/Store is sufficient.
+func (i *Instance) getOrDiscoverResources(cfg *rest.Config) (*lifecycle.Resources, error) {
+	if res := i.discovered.Load(); res != nil {
+		return res, nil
+	}
+	res, err := lifecycle.Discover(cfg)
+	if err != nil {
+		return nil, err
+	}
+	i.discovered.Store(res)
+	return res, nil
+}
+
+// stopControllers stops the in-process controllers and node simulator of the
+// current lease, if running. Release calls it before purging so that nothing
+// acts on rows being deleted, and the next lease starts with fresh caches.
+func (i *Instance) stopControllers() {
+	if sim := i.nodes.Swap(nil); sim != nil {
+		sim.Stop()
+	}
+	if ctrl := i.controllers.Swap(nil); ctrl != nil {
+		ctrl.Stop()
 	}
 }
 
@@ -272,6 +407,9 @@ func NewInstance(params NewInstanceParams) *Instance {
 // actually launches processes; subsequent callers see started==true.
 // Retry logic for transient failures (e.g., port conflicts) is handled
 // by [kubestack.StartWithRetry] inside doStart.
+//
+// A failed start writes a diagnostics bundle and returns a
+// *DiagnosticsError referencing it, unless ctx was canceled.
 func (i *Instance) Start(ctx context.Context) error {
 	i.startMu.Lock()
 	defer i.startMu.Unlock()
@@ -280,7 +418,10 @@ func (i *Instance) Start(ctx context.Context) error {
 		return nil // Already started
 	}
 
-	return i.doStart(ctx)
+	if err := i.doStart(ctx); err != nil {
+		return i.withDiagnostics(err)
+	}
+	return nil
 }
 
 // doStart performs the startup sequence with retries for namespace readiness.
@@ -296,9 +437,14 @@ func (i *Instance) doStart(ctx context.Context) error {
 	// Setup data directory
 	i.log.Debug("setting up directories")
 	if err := fileutil.EnsureDir(i.dataDir); err != nil {
-		return fmt.Errorf("mkdir data dir: %w", err)
+		return &StartError{Phase: PhaseSetup, ExitCode: -1, Err: fmt.Errorf("mkdir data dir: %w", err)}
+	}
+
+	if i.auditReceiver != nil {
+		i.auditReceiver.Register(i.id, i.recordAudit)
 	}
 
+	i.launches = 0
 	var lastNSErr error
 	for attempt := 1; attempt <= maxNamespaceRetries; attempt++ {
 		done, err := i.tryStartAttempt(ctx, attempt)
@@ -312,12 +458,12 @@ func (i *Instance) doStart(ctx context.Context) error {
 		lastNSErr = err
 	}
 
-	return fmt.Errorf("wait for system namespaces: %w", lastNSErr)
+	return lastNSErr
 }
 
 // tryStartAttempt performs a single start-and-verify cycle. It launches the
-// kubestack, then waits for system namespaces to appear. The return values
-// tell the caller how to proceed:
+// kubestack, then waits for system namespaces to appear. Errors are
+// *StartError values. The return values tell the caller how to proceed:
 //   - (true, nil): success — instance is started and ready.
 //   - (true, err): fatal error — stop retrying.
 //   - (false, err): retryable failure — caller should try again.
@@ -330,31 +476,47 @@ func (i *Instance) tryStartAttempt(ctx context.Context, attempt int) (bool, erro
 	// Create and start stack with retry logic for transient port conflicts.
 	// Each retry creates a fresh kubestack with new port allocations.
 	stack, err := kubestack.StartWithRetry(processCtx, ctx, kubestack.Config{
-		DataDir:               i.dataDir,
-		SQLitePath:            i.sqlitePath,
-		KubeconfigPath:        i.kubeconfig,
-		KineBinary:            i.cfg.KineBinary,
-		APIServerBinary:       i.cfg.KubeAPIServerBinary,
-		CachedDBPath:          i.cfg.CachedDBPath,
-		KineReadyTimeout:      i.cfg.StartTimeout,
-		APIServerReadyTimeout: i.cfg.StartTimeout,
-		StopTimeout:           i.cfg.StopTimeout,
-		PortRegistry:          i.ports,
-		Logger:                i.log,
+		DataDir:                 i.dataDir,
+		SQLitePath:              i.sqlitePath,
+		KubeconfigPath:          i.kubeconfig,
+		KineBinary:              i.cfg.KineBinary,
+		APIServerBinary:         i.cfg.KubeAPIServerBinary,
+		CachedDBPath:            i.cfg.CachedDBPath,
+		APIServerOptions:        i.cfg.APIServerOptions,
+		ControllerManagerBinary: i.cfg.ControllerManagerBinary,
+		Controllers:             i.cfg.Controllers,
+		KineReadyTimeout:        i.cfg.StartTimeout,
+		APIServerReadyTimeout:   i.cfg.StartTimeout,
+		StopTimeout:             i.cfg.StopTimeout,
+		LogStream:               i.cfg.LogStream,
+		LogRotation:             i.cfg.LogRotation,
+		KineTCP:                 i.cfg.KineTCP,
+		NetworkNamespace:        i.cfg.NetworkNamespace,
+		PortRegistry:            i.ports,
+		Logger:                  i.log,
 	}, i.cfg.MaxStartRetries)
 	if err != nil {
 		cancel()
 		// StartWithRetry already exhausted its internal retries for port
 		// conflicts — no point retrying at this level.
-		return true, fmt.Errorf("start kubestack: %w", err)
+		return true, i.newStartError(fmt.Errorf("start kubestack: %w", err), i.launches)
 	}
+	i.launches += stack.Attempts()
 
 	// Wait for all system namespaces to exist before marking started.
 	// /livez returns 200 before the namespace controller creates them;
 	// this closes that gap (~10-50ms) so consumers never see missing namespaces.
 	if err := i.waitForSystemNamespaces(ctx); err != nil {
+		port := stack.APIServerPort()
 		i.teardownFailedAttempt(cancel, stack, attempt, err)
-		return false, err
+		return false, &StartError{
+			Phase:      PhaseSystemNamespaces,
+			Attempts:   i.launches,
+			ExitCode:   -1,
+			StderrTail: i.stderrTail(apiServerProcess),
+			Port:       port,
+			Err:        fmt.Errorf("wait for system namespaces: %w", err),
+		}
 	}
 
 	// Open the purge handle eagerly so the baseline ID (MAX(id)) is captured
@@ -363,7 +525,7 @@ func (i *Instance) tryStartAttempt(ctx context.Context, attempt int) (bool, erro
 	h, err := openPurgeHandle(ctx, i.sqlitePath)
 	if err != nil {
 		i.teardownFailedAttempt(cancel, stack, attempt, err)
-		return false, fmt.Errorf("open purge handle: %w", err)
+		return false, &StartError{Phase: PhaseSetup, Attempts: i.launches, ExitCode: -1, Err: fmt.Errorf("open purge handle: %w", err)}
 	}
 	i.purge = h
 
@@ -525,6 +687,8 @@ func (i *Instance) Stop(ctx context.Context) error {
 	// the alternative (select on ctx.Done + TryLock loop) would add
 	// significant complexity for a scenario that only arises when Start and
 	// Stop race, which the pool layer already prevents.
+	i.stopControllers()
+
 	i.log.Debug("stop: waiting for startMu")
 	i.startMu.Lock()
 	i.log.Debug("stop: acquired startMu")
@@ -543,7 +707,11 @@ func (i *Instance) Stop(ctx context.Context) error {
 	i.cancel = nil
 	i.purge = nil
 	i.clients.Store(nil)
+	i.discovered.Store(nil)
 	i.started.Store(false)
+	if i.auditReceiver != nil {
+		i.auditReceiver.Unregister(i.id)
+	}
 
 	i.startMu.Unlock()
 
@@ -605,13 +773,15 @@ func (i *Instance) effectiveStopTimeout(ctx context.Context) time.Duration {
 	return timeout
 }
 
-// failRelease wraps err with msg, records it on the instance, and marks the
-// instance as permanently failed in the pool. It returns the wrapped error.
+// failRelease wraps err with msg, writes a diagnostics bundle while the
+// processes are still running, records the error on the instance, and marks
+// the instance as permanently failed in the pool. It returns the wrapped
+// error, which references the bundle.
 // This consolidates the repeated error-handling pattern in Release where
 // cleanup or stop failures must be recorded and the instance removed from
 // the pool before returning.
 func (i *Instance) failRelease(token uint64, msg string, err error) error {
-	wrapped := fmt.Errorf("%s: %w", msg, err)
+	wrapped := i.withDiagnostics(fmt.Errorf("%s: %w", msg, err))
 	i.setErr(wrapped)
 	i.releaser.ReleaseFailed(i, token)
 	return wrapped
@@ -687,6 +857,7 @@ func (i *Instance) Release(token uint64) error {
 	}
 
 	// Purge user data from kine's SQLite database.
+	i.stopControllers()
 	if err := i.releasePurge(token); err != nil {
 		return err
 	}
@@ -700,6 +871,31 @@ func (i *Instance) Release(token uint64) error {
 	return nil
 }
 
+// Keep hands the instance over for post-mortem inspection instead of
+// releasing it: it is not purged, its processes (and in-process controllers)
+// keep running until Manager.Shutdown, and it is never acquired again.
+// Returns false if the releaser declines to keep it; the caller then
+// releases it normally.
+//
+// Panics if token is stale, like Release.
+func (i *Instance) Keep(token uint64) bool {
+	if !i.isCurrentToken(token) {
+		panic("k8senv: double-release of instance " + i.id)
+	}
+	if !i.releaser.Keep(i, token) {
+		return false
+	}
+	i.log.Warn("keeping instance of failed test for inspection",
+		"kubeconfig", i.kubeconfig, "data_dir", i.dataDir)
+	return true
+}
+
+// DataDir returns the instance data directory, which holds the kine
+// database, the kubeconfig and the process logs.
+func (i *Instance) DataDir() string {
+	return i.dataDir
+}
+
 // releasePurge deletes user data directly from kine's SQLite database,
 // bypassing the Kubernetes API entirely. Both kine and kube-apiserver stay
 // running. Because --watch-cache=false, subsequent API calls see the cleaned
@@ -39,6 +39,7 @@ type fakeReleaser struct{}
 
 func (f *fakeReleaser) ReleaseToPool(_ *Instance, _ uint64) bool { return true }
 func (f *fakeReleaser) ReleaseFailed(_ *Instance, _ uint64)      {}
+func (f *fakeReleaser) Keep(_ *Instance, _ uint64) bool          { return false }
 
 // newTestInstance creates an Instance with valid construction params.
 func newTestInstance(t *testing.T) *Instance {
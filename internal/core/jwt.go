@@ -0,0 +1,51 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/k8senv/internal/sentinel"
+	"k8s.io/client-go/rest"
+)
+
+// ErrJWTNotEnabled is returned by ConfigForJWTClaims when the manager was
+// created without a JWT authenticator.
+const ErrJWTNotEnabled = sentinel.Error("JWT authentication not enabled")
+
+// ConfigForJWTClaims returns a *rest.Config that authenticates to this
+// instance with a JWT carrying claims, signed by the manager's OIDC issuer.
+// The "iss", "aud", "iat" and "exp" claims default to the issuer URL, the
+// first configured audience, now, and one hour from now; any of them can be
+// overridden through claims, e.g. to test rejection of expired tokens.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, ErrNotStarted if it has not been started yet, and ErrJWTNotEnabled if
+// no JWT authenticator is configured.
+func (i *Instance) ConfigForJWTClaims(claims map[string]any) (*rest.Config, error) {
+	if !i.IsBusy() {
+		return nil, ErrInstanceReleased
+	}
+	if i.issuer == nil || i.cfg.APIServerOptions.JWT == nil {
+		return nil, ErrJWTNotEnabled
+	}
+	cfg, err := i.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := i.issuer.Token(i.cfg.APIServerOptions.JWT.EffectiveAudiences()[0], claims)
+	if err != nil {
+		return nil, fmt.Errorf("issue jwt: %w", err)
+	}
+
+	// Replace the admin credentials (bearer token or client certificate)
+	// with the JWT, keeping the CA so the server is still verified.
+	cfg.BearerToken = token
+	cfg.BearerTokenFile = ""
+	cfg.Username = ""
+	cfg.Password = ""
+	cfg.CertData = nil
+	cfg.CertFile = ""
+	cfg.KeyData = nil
+	cfg.KeyFile = ""
+	return cfg, nil
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
+	"github.com/giantswarm/k8senv/internal/netutil"
+	"github.com/giantswarm/k8senv/internal/oidc"
+)
+
+// testKubeconfig is a minimal token-authenticated kubeconfig standing in for
+// the one written by kube-apiserver startup.
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: default
+  cluster:
+    server: https://127.0.0.1:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+current-context: default
+users:
+- name: default
+  user:
+    token: test-token
+`
+
+func TestInstanceConfigForJWTClaimsNotEnabled(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	if _, err := inst.ConfigForJWTClaims(nil); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("ConfigForJWTClaims() on free instance error = %v, want ErrInstanceReleased", err)
+	}
+
+	inst.markAcquired()
+	if _, err := inst.ConfigForJWTClaims(nil); !errors.Is(err, ErrJWTNotEnabled) {
+		t.Errorf("ConfigForJWTClaims() error = %v, want ErrJWTNotEnabled", err)
+	}
+}
+
+// TestInstanceConfigForJWTClaims verifies that the returned config carries a
+// token from the issuer instead of the admin token.
+func TestInstanceConfigForJWTClaims(t *testing.T) {
+	t.Parallel()
+
+	issuer, err := oidc.NewIssuer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { issuer.Close() }) //nolint:errcheck,gosec // test cleanup
+
+	cfg := validInstanceConfig()
+	cfg.APIServerOptions.JWT = &apiserver.JWTAuthenticator{}
+	cfg.APIServerOptions.JWTIssuerURL = issuer.URL()
+	cfg.APIServerOptions.JWTIssuerCA = issuer.CAPEM()
+	inst := NewInstance(NewInstanceParams{
+		ID:          "test-inst",
+		DataDir:     t.TempDir(),
+		Releaser:    &fakeReleaser{},
+		Ports:       netutil.NewPortRegistry(),
+		Config:      cfg,
+		TokenIssuer: issuer,
+	})
+	if err := os.WriteFile(inst.kubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	inst.markAcquired()
+	inst.started.Store(true)
+
+	restCfg, err := inst.ConfigForJWTClaims(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("ConfigForJWTClaims() unexpected error: %v", err)
+	}
+	if restCfg.BearerToken == "test-token" || strings.Count(restCfg.BearerToken, ".") != 2 {
+		t.Errorf("BearerToken = %q, want a JWT", restCfg.BearerToken)
+	}
+
+	// The cached admin config must be unaffected.
+	adminCfg, err := inst.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adminCfg.BearerToken != "test-token" {
+		t.Errorf("Config().BearerToken = %q, want test-token", adminCfg.BearerToken)
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"testing"
+)
+
+// newKeepTestManager returns a ready Manager with a bounded pool of one
+// instance and keep-on-failure limited to maxKept instances.
+func newKeepTestManager(t *testing.T, maxKept int) (*Manager, *Pool) {
+	t.Helper()
+	m := &Manager{
+		cfg:          ManagerConfig{KeepOnFailure: true, MaxKeptInstances: maxKept},
+		inflightDone: make(chan struct{}),
+	}
+	pool := NewPool(noopFactory(t), 1)
+	m.pool.Store(pool)
+	m.storeState(managerReady)
+	return m, pool
+}
+
+// TestManagerKeepDetachesInstance verifies that a kept instance is released
+// without returning to the free stack and that its pool slot is freed, so
+// the next Acquire creates a new instance.
+func TestManagerKeepDetachesInstance(t *testing.T) {
+	t.Parallel()
+
+	m, pool := newKeepTestManager(t, 1)
+	inst, token, err := pool.Acquire(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.Keep(inst, token) {
+		t.Fatal("Keep() = false, want true")
+	}
+	if inst.IsBusy() {
+		t.Error("kept instance is still busy")
+	}
+	if got := m.KeptInstances(); got != 1 {
+		t.Errorf("KeptInstances() = %d, want 1", got)
+	}
+
+	next, _, err := pool.Acquire(t.Context())
+	if err != nil {
+		t.Fatalf("Acquire after Keep: %v", err)
+	}
+	if next == inst {
+		t.Error("Acquire returned the kept instance")
+	}
+	if got := len(pool.Instances()); got != 2 {
+		t.Errorf("pool tracks %d instances, want 2 (kept one stopped at Shutdown)", got)
+	}
+}
+
+func TestManagerKeepLimit(t *testing.T) {
+	t.Parallel()
+
+	m, pool := newKeepTestManager(t, 1)
+	first, token, err := pool.Acquire(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Keep(first, token) {
+		t.Fatal("Keep(first) = false, want true")
+	}
+
+	second, token, err := pool.Acquire(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Keep(second, token) {
+		t.Error("Keep(second) = true beyond MaxKeptInstances")
+	}
+	if !second.IsBusy() {
+		t.Error("declined Keep released the instance")
+	}
+}
+
+func TestManagerKeepDisabledOrShuttingDown(t *testing.T) {
+	t.Parallel()
+
+	m, pool := newKeepTestManager(t, 1)
+	inst, token, err := pool.Acquire(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.cfg.KeepOnFailure = false
+	if m.Keep(inst, token) {
+		t.Error("Keep() = true with KeepOnFailure disabled")
+	}
+
+	m.cfg.KeepOnFailure = true
+	m.storeState(managerShuttingDown)
+	if m.Keep(inst, token) {
+		t.Error("Keep() = true while shutting down")
+	}
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeconfigPath returns the path of the kubeconfig file kube-apiserver's
+// start wrote into the instance data directory. It has the same lifetime as
+// Config: the file is valid while the instance is acquired, and is rewritten
+// with a new CA and port if the instance is restarted.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrNotStarted if it has not been started yet.
+func (i *Instance) KubeconfigPath() (string, error) {
+	if !i.IsBusy() {
+		return "", ErrInstanceReleased
+	}
+	if !i.started.Load() {
+		return "", ErrNotStarted
+	}
+	return i.kubeconfig, nil
+}
+
+// Kubeconfig returns the contents of the kubeconfig file at KubeconfigPath.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrNotStarted if it has not been started yet.
+func (i *Instance) Kubeconfig() ([]byte, error) {
+	path, err := i.KubeconfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read kubeconfig: %w", err)
+	}
+	return data, nil
+}
+
+// WriteMergedKubeconfig writes a kubeconfig file at path with one context per
+// running instance, named after the instance ID, so a developer can inspect
+// any instance with kubectl --context <id>. The current context is the
+// instance with the lowest pool index. Instances that are not started,
+// including free instances that were stopped, are left out; free running
+// instances are included.
+//
+// Returns ErrNotInitialized if Initialize has not completed.
+func (m *Manager) WriteMergedKubeconfig(path string) error {
+	return WriteMergedKubeconfig(path, m)
+}
+
+// WriteMergedKubeconfig is Manager.WriteMergedKubeconfig over the instances
+// of several managers, such as those of a version matrix.
+//
+// Returns ErrNotInitialized if any manager has not completed Initialize.
+func WriteMergedKubeconfig(path string, managers ...*Manager) error {
+	sources := make(map[string]string)
+	for _, m := range managers {
+		pool := m.pool.Load()
+		if pool == nil {
+			return ErrNotInitialized
+		}
+		for _, inst := range pool.Instances() {
+			if inst.IsStarted() {
+				sources[inst.ID()] = inst.kubeconfig
+			}
+		}
+	}
+	merged, err := mergeKubeconfigs(sources)
+	if err != nil {
+		return err
+	}
+	if err := clientcmd.WriteToFile(*merged, path); err != nil {
+		return fmt.Errorf("write merged kubeconfig to %s: %w", path, err)
+	}
+	return nil
+}
+
+// mergeKubeconfigs loads the kubeconfig file of each instance in sources
+// (instance ID to path) and returns one config holding each file's current
+// context under the instance ID. A file that no longer exists, because its
+// instance stopped after sources was collected, is skipped.
+func mergeKubeconfigs(sources map[string]string) (*clientcmdapi.Config, error) {
+	merged := clientcmdapi.NewConfig()
+	ids := make([]string, 0, len(sources))
+	for id := range sources {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, compareInstanceIDs)
+
+	for _, id := range ids {
+		cfg, err := clientcmd.LoadFromFile(sources[id])
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load kubeconfig of %s: %w", id, err)
+		}
+		kctx, ok := cfg.Contexts[cfg.CurrentContext]
+		if !ok {
+			return nil, fmt.Errorf("kubeconfig of %s has no current context", id)
+		}
+		cluster, ok := cfg.Clusters[kctx.Cluster]
+		if !ok {
+			return nil, fmt.Errorf("kubeconfig of %s has no cluster %q", id, kctx.Cluster)
+		}
+		authInfo, ok := cfg.AuthInfos[kctx.AuthInfo]
+		if !ok {
+			return nil, fmt.Errorf("kubeconfig of %s has no user %q", id, kctx.AuthInfo)
+		}
+
+		merged.Clusters[id] = cluster
+		merged.AuthInfos[id] = authInfo
+		merged.Contexts[id] = &clientcmdapi.Context{Cluster: id, AuthInfo: id}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = id
+		}
+	}
+	return merged, nil
+}
+
+// compareInstanceIDs orders instance IDs by their numeric pool index, so that
+// inst-2 sorts before inst-10, and by the full ID among equal indexes.
+func compareInstanceIDs(a, b string) int {
+	return cmp.Or(cmp.Compare(instanceIndex(a), instanceIndex(b)), strings.Compare(a, b))
+}
+
+// instanceIndex returns the pool index encoded in an instanceID, or -1 if id
+// is not in that format.
+func instanceIndex(id string) int {
+	rest, ok := strings.CutPrefix(id, "inst-")
+	if !ok {
+		return -1
+	}
+	digits, _, _ := strings.Cut(rest, "-")
+	index, err := strconv.Atoi(digits)
+	if err != nil {
+		return -1
+	}
+	return index
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// writeTestKubeconfig writes a single-context kubeconfig for server to a
+// file in a temporary directory and returns its path.
+func writeTestKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubeconfig.yaml")
+	cfg := clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{"k8senv": {Server: server}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"k8senv": {Token: "token"}},
+		Contexts:       map[string]*clientcmdapi.Context{"k8senv": {Cluster: "k8senv", AuthInfo: "k8senv"}},
+		CurrentContext: "k8senv",
+	}
+	if err := clientcmd.WriteToFile(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInstanceKubeconfigReleased(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	if _, err := inst.KubeconfigPath(); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("KubeconfigPath() error = %v, want ErrInstanceReleased", err)
+	}
+	if _, err := inst.Kubeconfig(); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("Kubeconfig() error = %v, want ErrInstanceReleased", err)
+	}
+}
+
+func TestInstanceKubeconfigNotStarted(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	inst.markAcquired()
+	if _, err := inst.Kubeconfig(); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("Kubeconfig() error = %v, want ErrNotStarted", err)
+	}
+}
+
+func TestMergeKubeconfigs(t *testing.T) {
+	t.Parallel()
+
+	merged, err := mergeKubeconfigs(map[string]string{
+		"inst-10-a": writeTestKubeconfig(t, "https://127.0.0.1:10"),
+		"inst-2-b":  writeTestKubeconfig(t, "https://127.0.0.1:2"),
+		"inst-3-c":  filepath.Join(t.TempDir(), "missing.yaml"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// inst-2 sorts before inst-10 by pool index, not lexically.
+	if merged.CurrentContext != "inst-2-b" {
+		t.Errorf("current context = %q, want inst-2-b", merged.CurrentContext)
+	}
+	if len(merged.Contexts) != 2 {
+		t.Errorf("contexts = %d, want 2 (missing file skipped)", len(merged.Contexts))
+	}
+	for id, server := range map[string]string{"inst-10-a": "https://127.0.0.1:10", "inst-2-b": "https://127.0.0.1:2"} {
+		kctx := merged.Contexts[id]
+		if kctx == nil || kctx.Cluster != id || kctx.AuthInfo != id {
+			t.Errorf("context %s = %+v, want cluster and user %s", id, kctx, id)
+			continue
+		}
+		if got := merged.Clusters[id].Server; got != server {
+			t.Errorf("cluster %s server = %q, want %q", id, got, server)
+		}
+	}
+}
+
+func TestWriteMergedKubeconfigNotInitialized(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{}
+	if err := m.WriteMergedKubeconfig(filepath.Join(t.TempDir(), "kubeconfig")); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("WriteMergedKubeconfig() error = %v, want ErrNotInitialized", err)
+	}
+}
+
+func TestCompareInstanceIDs(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"inst-10-a", "inst-2-c", "other", "inst-2-b", "inst-1-z"}
+	slices.SortFunc(ids, compareInstanceIDs)
+	want := []string{"other", "inst-1-z", "inst-2-b", "inst-2-c", "inst-10-a"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("sorted IDs = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/giantswarm/k8senv/internal/process"
+)
+
+// Process names, which prefix the log files in the instance data directory.
+const (
+	kineProcess              = "kine"
+	apiServerProcess         = "kube-apiserver"
+	controllerManagerProcess = "kube-controller-manager"
+)
+
+// LeaseLogs holds the output each process wrote during one lease: standard
+// output followed by standard error.
+type LeaseLogs struct {
+	Kine              []byte
+	APIServer         []byte
+	ControllerManager []byte // empty unless a controller manager runs
+}
+
+// LogWindow records the byte range each process log file grew by during one
+// lease, so the output can be read after the lease ends. With log rotation,
+// output rotated into segments during the lease is included, and ranges
+// follow their files as later rotations rename them.
+type LogWindow struct {
+	ranges   []logRange
+	rotation process.LogRotation
+}
+
+// logRange is the [start, end) byte range of one log file of process:
+// the current file at path if segment is 0, or rotated segment n of it.
+// rotations is the rotation count of path when the range was recorded.
+type logRange struct {
+	process    string
+	path       string
+	segment    int
+	rotations  int
+	start, end int64
+}
+
+// logMark is the size and rotation count of a log file when a lease began.
+type logMark struct {
+	size      int64
+	rotations int
+}
+
+// logPaths returns the stdout and stderr log paths of every process the
+// instance runs, keyed by process name.
+func (i *Instance) logPaths() map[string][]string {
+	names := []string{kineProcess, apiServerProcess}
+	if i.cfg.ControllerManagerBinary != "" {
+		names = append(names, controllerManagerProcess)
+	}
+	paths := make(map[string][]string, len(names))
+	for _, name := range names {
+		paths[name] = []string{
+			filepath.Join(i.dataDir, process.StdoutFile(name)),
+			filepath.Join(i.dataDir, process.StderrFile(name)),
+		}
+	}
+	return paths
+}
+
+// markLogs records the current size of every log file as the start of the
+// lease. Called by beginLease; the pool contract guarantees no concurrent
+// reader.
+func (i *Instance) markLogs() {
+	marks := make(map[string]logMark)
+	for _, paths := range i.logPaths() {
+		for _, path := range paths {
+			marks[path] = logMark{size: fileSize(path), rotations: i.cfg.LogRotation.Counter.Get(path)}
+		}
+	}
+	i.logMarks = marks
+}
+
+// LogWindow returns the byte ranges the log files grew by since the current
+// lease began. Capture it before Release to read the lease's output later
+// with LogWindow.Read.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrNotStarted if it has not been started yet.
+func (i *Instance) LogWindow() (LogWindow, error) {
+	if !i.IsBusy() {
+		return LogWindow{}, ErrInstanceReleased
+	}
+	if !i.started.Load() {
+		return LogWindow{}, ErrNotStarted
+	}
+	w := LogWindow{rotation: i.cfg.LogRotation}
+	for name, paths := range i.logPaths() {
+		for _, path := range paths {
+			w.ranges = append(w.ranges, w.rangesSince(name, path, i.logMarks[path])...)
+		}
+	}
+	return w, nil
+}
+
+// rangesSince returns the ranges of the log file at path written since mark,
+// oldest first. Without a rotation since mark, that is the growth of the
+// current file. Otherwise the file of the mark is now segment n, where n is
+// the number of rotations, and the output continues through the newer
+// segments into the current file; segments rotated away are skipped.
+func (w LogWindow) rangesSince(processName, path string, mark logMark) []logRange {
+	rotations := w.rotation.Counter.Get(path)
+	current := logRange{process: processName, path: path, rotations: rotations, start: mark.size, end: fileSize(path)}
+	n := rotations - mark.rotations
+	if n == 0 {
+		return []logRange{current}
+	}
+
+	var out []logRange
+	for seg := min(n, w.rotation.Segments); seg >= 1; seg-- {
+		r := logRange{
+			process:   processName,
+			path:      path,
+			segment:   seg,
+			rotations: rotations,
+			end:       fileSize(process.SegmentFile(path, seg)),
+		}
+		if seg == n {
+			r.start = mark.size
+		}
+		out = append(out, r)
+	}
+	current.start = 0
+	return append(out, current)
+}
+
+// Logs returns the kine, kube-apiserver and kube-controller-manager output
+// written since the current lease began.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrNotStarted if it has not been started yet.
+func (i *Instance) Logs() (LeaseLogs, error) {
+	w, err := i.LogWindow()
+	if err != nil {
+		return LeaseLogs{}, err
+	}
+	return w.Read()
+}
+
+// Read returns the output recorded in the window. A log file that shrank
+// since, because the instance restarted, yields what is left of the range;
+// one rotated away since yields nothing.
+func (w LogWindow) Read() (LeaseLogs, error) {
+	var logs LeaseLogs
+	for _, r := range w.ranges {
+		data, err := r.read(w.rotation)
+		if err != nil {
+			return LeaseLogs{}, err
+		}
+		switch r.process {
+		case kineProcess:
+			logs.Kine = append(logs.Kine, data...)
+		case apiServerProcess:
+			logs.APIServer = append(logs.APIServer, data...)
+		case controllerManagerProcess:
+			logs.ControllerManager = append(logs.ControllerManager, data...)
+		}
+	}
+	return logs, nil
+}
+
+// read returns the bytes of the range, or nil if the file does not exist.
+// Rotations since the range was recorded have shifted its file to a later
+// segment, which rotation locates.
+func (r logRange) read(rotation process.LogRotation) ([]byte, error) {
+	if r.end <= r.start {
+		return nil, nil
+	}
+	path := r.path
+	if seg := r.segment + rotation.Counter.Get(r.path) - r.rotations; seg > rotation.Segments {
+		return nil, nil
+	} else if seg > 0 {
+		path = process.SegmentFile(r.path, seg)
+	}
+	f, err := os.Open(path) //nolint:gosec // G304: paths are from the instance data directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.NewSectionReader(f, r.start, r.end-r.start))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return data, nil
+}
+
+// fileSize returns the size of path, or 0 if it cannot be determined (for
+// example because the process has not created it yet).
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
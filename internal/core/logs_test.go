@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/process"
+)
+
+// appendLog appends data to the log file name in the instance data directory.
+func appendLog(t *testing.T, inst *Instance, name, data string) {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(inst.dataDir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogsCurrentLeaseOnly(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	inst.markAcquired()
+	inst.started.Store(true)
+	appendLog(t, inst, "kine-stderr.log", "before\n")
+	appendLog(t, inst, "kube-apiserver-stderr.log", "before\n")
+
+	inst.markLogs()
+	appendLog(t, inst, "kine-stderr.log", "kine lease\n")
+	appendLog(t, inst, "kube-apiserver-stdout.log", "out\n")
+	appendLog(t, inst, "kube-apiserver-stderr.log", "err\n")
+
+	window, err := inst.LogWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLog(t, inst, "kine-stderr.log", "after window\n")
+
+	logs, err := window.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logs.Kine) != "kine lease\n" {
+		t.Errorf("Kine = %q, want only the lease output", logs.Kine)
+	}
+	if string(logs.APIServer) != "out\nerr\n" {
+		t.Errorf("APIServer = %q, want stdout then stderr of the lease", logs.APIServer)
+	}
+	if logs.ControllerManager != nil {
+		t.Errorf("ControllerManager = %q, want nil without a controller manager", logs.ControllerManager)
+	}
+
+	current, err := inst.Logs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current.Kine) != "kine lease\nafter window\n" {
+		t.Errorf("Logs().Kine = %q, want all output since the lease began", current.Kine)
+	}
+}
+
+func TestLogWindowTruncatedFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "kine-stderr.log")
+	if err := os.WriteFile(path, []byte("restarted"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w := LogWindow{ranges: []logRange{{process: kineProcess, path: path, start: 4, end: 100}}}
+	logs, err := w.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logs.Kine) != "arted" {
+		t.Errorf("Kine = %q, want the rest of the shrunk file", logs.Kine)
+	}
+}
+
+// rotateLog rotates the log file name in the instance data directory the way
+// the process log sinks do with two segments, and counts the rotation.
+func rotateLog(t *testing.T, inst *Instance, name string) {
+	t.Helper()
+	path := filepath.Join(inst.dataDir, name)
+	err := os.Rename(process.SegmentFile(path, 1), process.SegmentFile(path, 2))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, process.SegmentFile(path, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	inst.cfg.LogRotation.Counter.Add(path)
+}
+
+func TestLogWindowRotation(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	inst.cfg.LogRotation = process.LogRotation{MaxSize: 1 << 20, Segments: 2, Counter: &process.RotationCounter{}}
+	inst.markAcquired()
+	inst.started.Store(true)
+	appendLog(t, inst, "kine-stderr.log", "before\n")
+
+	inst.markLogs()
+	appendLog(t, inst, "kine-stderr.log", "lease 1\n")
+	rotateLog(t, inst, "kine-stderr.log")
+	appendLog(t, inst, "kine-stderr.log", "lease 2\n")
+
+	window, err := inst.LogWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotateLog(t, inst, "kine-stderr.log")
+	appendLog(t, inst, "kine-stderr.log", "after window\n")
+
+	logs, err := window.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logs.Kine) != "lease 1\nlease 2\n" {
+		t.Errorf("Kine = %q, want the lease output across the rotation", logs.Kine)
+	}
+
+	// Another rotation deletes the segment holding "lease 1".
+	rotateLog(t, inst, "kine-stderr.log")
+	logs, err = window.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logs.Kine) != "lease 2\n" {
+		t.Errorf("Kine = %q, want only the output still kept", logs.Kine)
+	}
+}
+
+func TestLogsReleased(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	if _, err := inst.Logs(); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("Logs() error = %v, want ErrInstanceReleased", err)
+	}
+}
@@ -7,13 +7,19 @@ import (
 	"log/slog"
 	"math/rand/v2"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/giantswarm/k8senv/internal/audit"
+	"github.com/giantswarm/k8senv/internal/authz"
 	"github.com/giantswarm/k8senv/internal/crdcache"
 	"github.com/giantswarm/k8senv/internal/fileutil"
+	"github.com/giantswarm/k8senv/internal/netns"
 	"github.com/giantswarm/k8senv/internal/netutil"
+	"github.com/giantswarm/k8senv/internal/oidc"
+	"github.com/giantswarm/k8senv/internal/process"
 	"github.com/giantswarm/k8senv/internal/sentinel"
 )
 
@@ -33,6 +39,10 @@ const ErrShuttingDown = sentinel.Error("manager is shutting down")
 // ErrNotInitialized is returned by Acquire when Initialize has not been called.
 const ErrNotInitialized = sentinel.Error("manager not initialized")
 
+// ErrNetworkNamespaceUnsupported is returned by Initialize when
+// NetworkNamespace is set on a platform other than Linux.
+const ErrNetworkNamespaceUnsupported = netns.ErrUnsupported
+
 // Verify Manager implements InstanceReleaser at compile time.
 var _ InstanceReleaser = (*Manager)(nil)
 
@@ -70,10 +80,30 @@ type Manager struct {
 
 	pool atomic.Pointer[Pool]
 
+	// audit is the in-process audit webhook receiver shared by all
+	// instances. Set during Initialize when an audit policy is configured,
+	// closed by Shutdown (or by a failed Initialize). nil otherwise.
+	audit atomic.Pointer[audit.Receiver]
+
+	// issuer is the OIDC issuer trusted by every instance's JWT
+	// authenticator. Set during Initialize when a JWT authenticator is
+	// configured, closed by Shutdown (or by a failed Initialize). nil
+	// otherwise.
+	issuer atomic.Pointer[oidc.Issuer]
+
+	// authz serves the authorization webhook. Set during Initialize when an
+	// authorizer is configured, closed by Shutdown (or by a failed
+	// Initialize). nil otherwise.
+	authz atomic.Pointer[authz.Server]
+
+	// versions holds the binary versions detected during Initialize. nil
+	// until Initialize succeeds.
+	versions atomic.Pointer[Versions]
+
 	state atomic.Uint32 // managerState; zero value is managerCreated
 
-	// inflight counts goroutines inside tryReleaseToPool's check-and-release
-	// window. Shutdown waits for inflight to reach zero before proceeding,
+	// inflight counts goroutines inside the check-and-release window of
+	// tryReleaseToPool or Keep. Shutdown waits for inflight to reach zero before proceeding,
 	// preventing the TOCTOU race where Shutdown could set the state between
 	// tryReleaseToPool's state check and pool.Release call.
 	inflight atomic.Int64
@@ -86,6 +116,10 @@ type Manager struct {
 	// if multiple goroutines decrement inflight to zero concurrently.
 	inflightDoneOnce sync.Once
 
+	// kept counts instances detached by Keep, bounded by
+	// cfg.MaxKeptInstances.
+	kept atomic.Int64
+
 	// initMu serializes concurrent Initialize calls. Pool reads use
 	// atomic.Pointer and do not require initMu.
 	initMu sync.Mutex
@@ -114,11 +148,20 @@ func NewManagerWithConfig(cfg ManagerConfig) *Manager {
 	return &Manager{
 		cfg:          cfg,
 		cachedDBPath: cfg.PrepopulateDBPath,
-		ports:        netutil.NewPortRegistry(),
+		ports:        newPortRegistry(cfg.PortLockDir),
 		inflightDone: make(chan struct{}),
 	}
 }
 
+// newPortRegistry returns a registry shared through lockDir with other
+// processes, or one local to this process if lockDir is empty.
+func newPortRegistry(lockDir string) *netutil.PortRegistry {
+	if lockDir == "" {
+		return netutil.NewPortRegistry()
+	}
+	return netutil.NewSharedPortRegistry(lockDir)
+}
+
 // Initialize performs expensive initialization operations.
 // Must be called before Acquire. Returns error instead of panicking.
 // Safe to call multiple times: after a successful initialization, subsequent
@@ -159,6 +202,7 @@ func (m *Manager) Initialize(ctx context.Context) error {
 			)
 		}
 		m.pool.Store(nil)
+		m.closeSidecars()
 		// Reset cachedDBPath so a retry doesn't use a stale path
 		// pointing to a cache that may have been cleaned up.
 		m.cachedDBPath = m.cfg.PrepopulateDBPath
@@ -172,20 +216,75 @@ func (m *Manager) Initialize(ctx context.Context) error {
 
 // doInitialize contains the actual initialization logic.
 func (m *Manager) doInitialize(ctx context.Context) error {
+	if m.cfg.NetworkNamespace && !netns.Supported {
+		return ErrNetworkNamespaceUnsupported
+	}
 	if err := fileutil.EnsureDir(m.cfg.BaseDataDir); err != nil {
 		return fmt.Errorf("init base dir: %w", err)
 	}
 
+	// The kube-apiserver version selects the flags every instance (and the
+	// CRD cache stack) is started with.
+	versions, apiVersion, err := m.detectVersions(ctx)
+	if err != nil {
+		return err
+	}
+	Logger().Info("detected binary versions", "kube-apiserver", versions.KubeAPIServer, "kine", versions.Kine)
+
+	// Resolve apiserver options before the (potentially slow) CRD cache
+	// build so that a missing config file fails fast.
+	apiOpts, err := m.cfg.apiServerOptions()
+	if err != nil {
+		return err
+	}
+	apiOpts.Version = apiVersion
+
+	if apiOpts.AuditEnabled() {
+		receiver, err := audit.NewReceiver(Logger())
+		if err != nil {
+			return fmt.Errorf("start audit receiver: %w", err)
+		}
+		m.audit.Store(receiver)
+	}
+
+	if apiOpts.JWT != nil {
+		issuer, err := oidc.NewIssuer(Logger())
+		if err != nil {
+			return fmt.Errorf("start oidc issuer: %w", err)
+		}
+		m.issuer.Store(issuer)
+		apiOpts.JWTIssuerURL = issuer.URL()
+		apiOpts.JWTIssuerCA = issuer.CAPEM()
+	}
+
+	if m.cfg.Authorizer != nil {
+		server, err := authz.NewServer(m.cfg.Authorizer, Logger())
+		if err != nil {
+			return fmt.Errorf("start authorization webhook: %w", err)
+		}
+		m.authz.Store(server)
+		apiOpts.AuthorizationWebhookURL = server.URL()
+	}
+
+	if err := apiOpts.CheckVersion(); err != nil {
+		return fmt.Errorf("kube-apiserver %s: %w", versions.KubeAPIServer, err)
+	}
+
 	if m.cfg.CRDDir != "" {
 		result, err := crdcache.EnsureCache(ctx, crdcache.Config{
-			CRDDir:              m.cfg.CRDDir,
-			CacheDir:            m.cfg.BaseDataDir,
-			KineBinary:          m.cfg.KineBinary,
-			KubeAPIServerBinary: m.cfg.KubeAPIServerBinary,
-			Timeout:             m.cfg.CRDCacheTimeout,
-			StopTimeout:         m.cfg.InstanceStopTimeout,
-			PortRegistry:        m.ports,
-			Logger:              Logger(),
+			CRDDir:               m.cfg.CRDDir,
+			CacheDir:             m.cfg.BaseDataDir,
+			KineBinary:           m.cfg.KineBinary,
+			KubeAPIServerBinary:  m.cfg.KubeAPIServerBinary,
+			APIServerOptions:     apiOpts,
+			KubeAPIServerVersion: versions.KubeAPIServer,
+			KineVersion:          versions.Kine,
+			Timeout:              m.cfg.CRDCacheTimeout,
+			StopTimeout:          m.cfg.InstanceStopTimeout,
+			KineTCP:              m.cfg.KineTCP,
+			NetworkNamespace:     m.cfg.NetworkNamespace,
+			PortRegistry:         m.ports,
+			Logger:               Logger(),
 		})
 		if err != nil {
 			return fmt.Errorf("ensure CRD cache: %w", err)
@@ -201,8 +300,27 @@ func (m *Manager) doInitialize(ctx context.Context) error {
 		CachedDBPath:        m.cachedDBPath,
 		KineBinary:          m.cfg.KineBinary,
 		KubeAPIServerBinary: m.cfg.KubeAPIServerBinary,
+		APIServerOptions:    apiOpts,
+
+		ControllerManagerBinary: m.cfg.ControllerManagerBinary,
+		Controllers:             slices.Clone(m.cfg.Controllers),
+		InProcessControllers:    m.cfg.InProcessControllers,
+		FakeNodes:               slices.Clone(m.cfg.FakeNodes),
+		PodRules:                slices.Clone(m.cfg.PodRules),
+		ArtifactsDir:            m.cfg.ArtifactsDir,
+		LogStream: process.LogStream{
+			Enabled:  m.cfg.ProcessLogs,
+			MinLevel: m.cfg.ProcessLogLevel,
+		},
+		LogRotation: process.LogRotation{
+			MaxSize:  m.cfg.MaxLogSize,
+			Segments: m.cfg.MaxLogSegments,
+		},
+		KineTCP:          m.cfg.KineTCP,
+		NetworkNamespace: m.cfg.NetworkNamespace,
 	}
 
+	m.versions.Store(&versions)
 	factory := m.instanceFactory(m.cfg.BaseDataDir, instCfg)
 	m.pool.Store(NewPool(factory, m.cfg.PoolSize))
 
@@ -217,21 +335,78 @@ func genID() string {
 	)
 }
 
+// instanceID returns the ID, and data directory name, of the instance at
+// index in the pool with the random suffix id.
+func instanceID(index int, id string) string {
+	return fmt.Sprintf("inst-%d-%s", index, id)
+}
+
 // instanceFactory returns an InstanceFactory that creates instances with the
 // given base data directory and configuration. The factory generates unique IDs,
 // constructs per-instance directories, and wires the manager as the releaser.
 func (m *Manager) instanceFactory(baseDataDir string, cfg InstanceConfig) InstanceFactory {
 	return func(index int) (*Instance, error) {
-		id := genID()
-		instID := fmt.Sprintf("inst-%d-%s", index, id)
+		instID := instanceID(index, genID())
 		instDir := filepath.Join(baseDataDir, instID)
-		return NewInstance(NewInstanceParams{
-			ID:       instID,
-			DataDir:  instDir,
-			Releaser: m,
-			Ports:    m.ports,
-			Config:   cfg,
-		}), nil
+
+		// Each instance posts audit events to its own receiver path so that
+		// they can be routed to the lease that owns the instance.
+		instCfg := cfg
+		receiver := m.audit.Load()
+		if receiver != nil {
+			instCfg.APIServerOptions.AuditWebhookURL = receiver.URL(instID)
+		}
+
+		inst := NewInstance(NewInstanceParams{
+			ID:          instID,
+			DataDir:     instDir,
+			Releaser:    m,
+			Ports:       m.ports,
+			Config:      instCfg,
+			TokenIssuer: m.issuer.Load(),
+
+			AuditReceiver: receiver,
+		})
+		return inst, nil
+	}
+}
+
+// closeSidecars stops the in-process servers started by doInitialize for the
+// apiservers to call back into (audit receiver, OIDC issuer, authorization
+// webhook).
+func (m *Manager) closeSidecars() {
+	m.closeAuditReceiver()
+	m.closeIssuer()
+	m.closeAuthorizer()
+}
+
+// closeAuthorizer stops the authorization webhook server, if any, and clears
+// it so a subsequent Initialize can start a fresh one.
+func (m *Manager) closeAuthorizer() {
+	if server := m.authz.Swap(nil); server != nil {
+		if err := server.Close(); err != nil {
+			Logger().Warn("close authorization webhook", "error", err)
+		}
+	}
+}
+
+// closeIssuer stops the OIDC issuer, if any, and clears it so a subsequent
+// Initialize can start a fresh one.
+func (m *Manager) closeIssuer() {
+	if issuer := m.issuer.Swap(nil); issuer != nil {
+		if err := issuer.Close(); err != nil {
+			Logger().Warn("close oidc issuer", "error", err)
+		}
+	}
+}
+
+// closeAuditReceiver stops the audit receiver, if any, and clears it so a
+// subsequent Initialize can start a fresh one.
+func (m *Manager) closeAuditReceiver() {
+	if receiver := m.audit.Swap(nil); receiver != nil {
+		if err := receiver.Close(); err != nil {
+			Logger().Warn("close audit receiver", "error", err)
+		}
 	}
 }
 
@@ -310,6 +485,14 @@ func (m *Manager) Acquire(ctx context.Context) (*Instance, uint64, error) {
 		}
 	}
 
+	if err := inst.beginLease(); err != nil {
+		inst.setErr(err)
+		pool.ReleaseFailed( //nolint:contextcheck // ReleaseFailed is fire-and-forget cleanup; it creates its own bounded context internally
+			inst,
+			token,
+		)
+		return nil, 0, err
+	}
 	return inst, token, nil
 }
 
@@ -395,26 +578,69 @@ func (m *Manager) stopInstanceDuringShutdown(ctx context.Context, i *Instance, t
 // Returns true if the instance was returned to the pool, false if the manager
 // is shutting down.
 func (m *Manager) tryReleaseToPool(i *Instance, token uint64) bool {
+	defer m.enterInflight()()
+
+	if m.loadState() == managerShuttingDown {
+		return false
+	}
+
+	pool := m.pool.Load()
+	if pool == nil {
+		return false
+	}
+
+	pool.Release(i, token)
+	return true
+}
+
+// enterInflight increments the inflight counter and returns the function that
+// decrements it, closing inflightDone when it drops to zero during shutdown.
+// Intended use: defer m.enterInflight()()
+func (m *Manager) enterInflight() func() {
 	m.inflight.Add(1)
-	defer func() {
+	return func() {
 		if m.inflight.Add(-1) == 0 && m.loadState() == managerShuttingDown {
 			m.inflightDoneOnce.Do(func() { close(m.inflightDone) })
 		}
-	}()
+	}
+}
 
-	if m.loadState() == managerShuttingDown {
+// Keep detaches the instance from the pool, leaving it running and unpurged
+// until Shutdown, if KeepOnFailure is enabled and fewer than
+// MaxKeptInstances instances are kept. Like tryReleaseToPool, the state
+// check and detach run inside the inflight window so Shutdown cannot miss
+// the instance.
+//
+// Implements InstanceReleaser.
+func (m *Manager) Keep(i *Instance, token uint64) bool {
+	if !m.cfg.KeepOnFailure {
 		return false
 	}
+	defer m.enterInflight()()
 
+	if m.loadState() == managerShuttingDown {
+		return false
+	}
 	pool := m.pool.Load()
 	if pool == nil {
 		return false
 	}
-
-	pool.Release(i, token)
+	if m.kept.Add(1) > int64(m.cfg.MaxKeptInstances) {
+		m.kept.Add(-1)
+		i.log.Info("not keeping instance of failed test: limit of kept instances reached",
+			"max_kept_instances", m.cfg.MaxKeptInstances)
+		return false
+	}
+	pool.Detach(i, token)
 	return true
 }
 
+// KeptInstances returns the number of instances detached by Keep. They run
+// until Shutdown.
+func (m *Manager) KeptInstances() int {
+	return int(m.kept.Load())
+}
+
 // ReleaseFailed marks the instance as permanently failed and removes it from
 // the pool. Delegates to Pool.ReleaseFailed.
 //
@@ -507,7 +733,14 @@ func (m *Manager) Shutdown() error {
 		}
 	}
 
-	return stopAllInstances(instances, m.cfg.InstanceStopTimeout)
+	err := stopAllInstances(instances, m.cfg.InstanceStopTimeout)
+
+	// Close the in-process servers only after every apiserver has stopped:
+	// in blocking mode an apiserver waits on the audit webhook for each
+	// request, and authentication and authorization depend on the others.
+	m.closeSidecars()
+
+	return err
 }
 
 // stopAllInstances stops all non-nil instances in parallel using background
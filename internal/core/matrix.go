@@ -0,0 +1,63 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/giantswarm/k8senv/internal/sentinel"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// ErrUnknownVersion is returned when an instance is requested for a
+// kube-apiserver version that is not part of the version matrix.
+const ErrUnknownVersion = sentinel.Error("unknown kube-apiserver version")
+
+// NewVersionManagers creates one Manager per entry of
+// cfg.KubeAPIServerVersions, each running that version's kube-apiserver
+// binary with its own pool and CRD cache. The managers share a port
+// registry, so instances of different versions never race for a port, and
+// keep their data under cfg.BaseDataDir/<version>. All other settings,
+// including PoolSize, apply to each manager.
+//
+// The versions are returned in ascending order, so the last one is the
+// newest. Like NewManagerWithConfig, NewVersionManagers performs no I/O and
+// panics if cfg.Validate() reports any errors.
+func NewVersionManagers(cfg ManagerConfig) ([]string, map[string]*Manager) {
+	if err := cfg.Validate(); err != nil {
+		panic(fmt.Sprintf("k8senv: invalid manager config: %v", err))
+	}
+
+	labels := slices.SortedFunc(maps.Keys(cfg.KubeAPIServerVersions), compareVersionLabels)
+	ports := newPortRegistry(cfg.PortLockDir)
+	managers := make(map[string]*Manager, len(labels))
+	for _, label := range labels {
+		vcfg := cfg
+		vcfg.KubeAPIServerBinary = cfg.KubeAPIServerVersions[label]
+		vcfg.KubeAPIServerVersions = nil
+		vcfg.BaseDataDir = filepath.Join(cfg.BaseDataDir, label)
+		m := NewManagerWithConfig(vcfg)
+		m.ports = ports
+		managers[label] = m
+	}
+	return labels, managers
+}
+
+// compareVersionLabels orders version labels, which Validate has checked to
+// parse, by version, falling back to the label text for equal versions such
+// as "1.33" and "1.33.0".
+func compareVersionLabels(a, b string) int {
+	va, errA := version.ParseGeneric(a)
+	vb, errB := version.ParseGeneric(b)
+	if errA == nil && errB == nil {
+		switch {
+		case va.LessThan(vb):
+			return -1
+		case vb.LessThan(va):
+			return 1
+		}
+	}
+	return cmp.Compare(a, b)
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewVersionManagers(t *testing.T) {
+	t.Parallel()
+
+	base := t.TempDir()
+	cfg := ManagerConfig{
+		KineBinary:           testKineBinary,
+		KubeAPIServerBinary:  testKubeAPIServerBinary,
+		AcquireTimeout:       30 * time.Second,
+		BaseDataDir:          base,
+		InstanceStartTimeout: 5 * time.Minute,
+		InstanceStopTimeout:  10 * time.Second,
+		CleanupTimeout:       30 * time.Second,
+		CRDCacheTimeout:      5 * time.Minute,
+		ShutdownDrainTimeout: 30 * time.Second,
+		PoolSize:             2,
+		KubeAPIServerVersions: map[string]string{
+			"1.35": "/bin/1.35/kube-apiserver",
+			"1.9":  "/bin/1.9/kube-apiserver",
+			"1.33": "/bin/1.33/kube-apiserver",
+		},
+	}
+
+	order, managers := NewVersionManagers(cfg)
+	if want := []string{"1.9", "1.33", "1.35"}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+
+	ports := managers["1.35"].ports
+	for _, v := range order {
+		m := managers[v]
+		if m.cfg.KubeAPIServerBinary != cfg.KubeAPIServerVersions[v] {
+			t.Errorf("%s: KubeAPIServerBinary = %q", v, m.cfg.KubeAPIServerBinary)
+		}
+		if want := filepath.Join(base, v); m.cfg.BaseDataDir != want {
+			t.Errorf("%s: BaseDataDir = %q, want %q", v, m.cfg.BaseDataDir, want)
+		}
+		if m.cfg.KubeAPIServerVersions != nil || m.cfg.PoolSize != 2 {
+			t.Errorf("%s: KubeAPIServerVersions = %v, PoolSize = %d; want nil, 2",
+				v, m.cfg.KubeAPIServerVersions, m.cfg.PoolSize)
+		}
+		if m.ports != ports {
+			t.Errorf("%s: port registry not shared", v)
+		}
+	}
+}
+
+func TestCompareVersionLabels(t *testing.T) {
+	t.Parallel()
+
+	labels := []string{"v1.33.4", "1.33", "1.29", "1.100"}
+	slices.SortFunc(labels, compareVersionLabels)
+	if want := []string{"1.29", "1.33", "v1.33.4", "1.100"}; !slices.Equal(labels, want) {
+		t.Errorf("sorted = %v, want %v", labels, want)
+	}
+}
@@ -228,6 +228,15 @@ func (p *Pool) ReleaseFailed(i *Instance, token uint64) {
 	p.returnSlot()
 }
 
+// Detach releases the acquisition identified by token without returning the
+// Instance to the free stack, leaving it running. The instance stays in the
+// all slice so Shutdown stops it, and its semaphore slot is returned so the
+// pool can create a replacement.
+func (p *Pool) Detach(i *Instance, token uint64) {
+	i.mustRelease(token)
+	p.returnSlot()
+}
+
 // Close marks the pool as closed. Subsequent Acquire calls return ErrPoolClosed
 // and Release calls stop instances instead of returning them to the free stack.
 // Safe to call multiple times (idempotent).
@@ -0,0 +1,413 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
+	"github.com/giantswarm/k8senv/internal/fileutil"
+	"github.com/giantswarm/k8senv/internal/kine"
+	"github.com/giantswarm/k8senv/internal/kubestack"
+	"github.com/giantswarm/k8senv/internal/netns"
+	"github.com/giantswarm/k8senv/internal/process"
+	"github.com/giantswarm/k8senv/internal/sentinel"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// ErrPreflightFailed is wrapped by PreflightReport.Err for every failed
+// check.
+const ErrPreflightFailed = sentinel.Error("preflight check failed")
+
+// Preflight thresholds. They are rough per-instance budgets for kine plus
+// kube-apiserver, used to warn before a pool runs into a host limit.
+const (
+	// minFreeBytes is the free space below which the data directory check
+	// warns.
+	minFreeBytes = 1 << 30
+
+	// openFilesPerInstance is the open file budget of one instance.
+	openFilesPerInstance = 512
+
+	// threadsPerInstance is the thread budget of one instance, checked
+	// against the process limit, which counts threads on Linux.
+	threadsPerInstance = 128
+)
+
+// kineProgressNotifyVersion is the first kine release that answers etcd
+// watch progress requests. kube-apiserver 1.31 and later serve consistent
+// lists from the watch cache only when the storage does, and fall back to
+// reading kine otherwise.
+var (
+	kineProgressNotifyVersion = version.MustParseGeneric("v0.11.0")
+	consistentListVersion     = version.MajorMinor(1, 31)
+)
+
+// CheckStatus is the outcome of a preflight check.
+type CheckStatus int
+
+// Preflight check outcomes, in increasing severity.
+const (
+	// CheckOK means the check passed.
+	CheckOK CheckStatus = iota
+	// CheckWarn means k8senv will run, but something may go wrong under
+	// load or is degraded.
+	CheckWarn
+	// CheckFail means Initialize or instance startup will fail.
+	CheckFail
+)
+
+// String returns "ok", "warn" or "fail".
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckOK:
+		return "ok"
+	case CheckWarn:
+		return "warn"
+	case CheckFail:
+		return "fail"
+	default:
+		return "CheckStatus(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// PreflightCheck is the result of one preflight check.
+type PreflightCheck struct {
+	// Name identifies the check, e.g. "kube-apiserver binary".
+	Name string
+	// Status is the outcome.
+	Status CheckStatus
+	// Detail describes what was found and, for warnings and failures, what
+	// to do about it.
+	Detail string
+}
+
+// PreflightReport holds the results of Manager.Preflight, in the order the
+// checks ran.
+type PreflightReport struct {
+	Checks []PreflightCheck
+}
+
+// Failed reports whether any check failed.
+func (r *PreflightReport) Failed() bool {
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil if no check failed, and otherwise joins an error wrapping
+// ErrPreflightFailed per failed check.
+func (r *PreflightReport) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			errs = append(errs, fmt.Errorf("%w: %s: %s", ErrPreflightFailed, c.Name, c.Detail))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteTo writes the report to w, one "[status] name: detail" line per
+// check.
+func (r *PreflightReport) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, c := range r.Checks {
+		n, err := fmt.Fprintf(w, "[%-4s] %s: %s\n", c.Status, c.Name, c.Detail)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (r *PreflightReport) add(name string, status CheckStatus, format string, args ...any) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Preflight checks that the host can run the manager's instances without
+// starting any: the binaries exist and report usable versions, kine and
+// kube-apiserver are compatible, the base data directory is writable and
+// has free space, loopback ports can be bound, and the parent-death signal
+// and resource limits suit the pool size. It can be called at any time,
+// including before Initialize. It does not change the manager's state, but
+// creates the base data directory if it does not exist yet.
+func (m *Manager) Preflight(ctx context.Context) *PreflightReport {
+	return Preflight(ctx, m)
+}
+
+// Preflight is Manager.Preflight over several managers, such as those of a
+// version matrix. Binaries and data directories shared by several managers
+// are checked once, and the resource checks cover all their pools.
+func Preflight(ctx context.Context, managers ...*Manager) *PreflightReport {
+	r := &PreflightReport{}
+	// Versions by binary; a nil entry marks a binary already checked that
+	// reported no usable version.
+	kineVersions := make(map[string]*version.Version)
+	apiVersions := make(map[string]*version.Version)
+	seenDirs := make(map[string]bool)
+	seenControllerManagers := make(map[string]bool)
+	instances, ports := 0, 0
+	namespaces := false
+
+	for _, m := range managers {
+		cfg := m.cfg
+		instances += max(cfg.PoolSize, 1)
+		ports += max(cfg.PoolSize, 1) * portsPerInstance(cfg)
+		checkKineSocket(r, cfg)
+		namespaces = namespaces || cfg.NetworkNamespace
+
+		kineVer, ok := kineVersions[cfg.KineBinary]
+		if !ok {
+			kineVer = checkKine(ctx, r, cfg.KineBinary)
+			kineVersions[cfg.KineBinary] = kineVer
+		}
+		apiVer, ok := apiVersions[cfg.KubeAPIServerBinary]
+		if !ok {
+			apiVer = checkKubeAPIServer(ctx, r, cfg.KubeAPIServerBinary)
+			apiVersions[cfg.KubeAPIServerBinary] = apiVer
+			if kineVer != nil && apiVer != nil {
+				checkCompatibility(r, kineVer, apiVer)
+			}
+		}
+		if cfg.ControllerManagerBinary != "" && !seenControllerManagers[cfg.ControllerManagerBinary] {
+			seenControllerManagers[cfg.ControllerManagerBinary] = true
+			checkBinary(r, "kube-controller-manager binary", cfg.ControllerManagerBinary)
+		}
+
+		if !seenDirs[cfg.BaseDataDir] {
+			seenDirs[cfg.BaseDataDir] = true
+			checkDataDir(r, cfg.BaseDataDir)
+		}
+		if cfg.PortLockDir != "" && !seenDirs[cfg.PortLockDir] {
+			seenDirs[cfg.PortLockDir] = true
+			checkWritable(r, "port lock directory", cfg.PortLockDir)
+		}
+	}
+
+	checkPorts(ctx, r, ports)
+	if namespaces {
+		checkNetworkNamespace(ctx, r)
+	}
+	checkPdeathsig(r)
+	checkLimits(r, instances)
+	return r
+}
+
+// longestDataDir returns the data directory of the instance of cfg with the
+// longest path.
+func longestDataDir(cfg ManagerConfig) string {
+	return filepath.Join(cfg.BaseDataDir, instanceID(max(cfg.PoolSize, 1)-1, genID()))
+}
+
+// longestKineSocketPath returns the kine socket path of the instance of cfg
+// with the longest data directory, or "" if it falls back to TCP.
+func longestKineSocketPath(cfg ManagerConfig) string {
+	return kine.SocketPath(longestDataDir(cfg))
+}
+
+// portsPerInstance returns the number of loopback ports an instance of cfg
+// allocates: one for kube-apiserver, one for kine unless it listens on a
+// unix socket, and one for kube-controller-manager if configured.
+func portsPerInstance(cfg ManagerConfig) int {
+	n := 1
+	if cfg.KineTCP || longestKineSocketPath(cfg) == "" {
+		n++
+	}
+	if cfg.ControllerManagerBinary != "" {
+		n++
+	}
+	return n
+}
+
+// checkKineSocket reports how kine and kube-apiserver will connect.
+func checkKineSocket(r *PreflightReport, cfg ManagerConfig) {
+	const name = "kine listener"
+	switch {
+	case cfg.NetworkNamespace && (longestKineSocketPath(cfg) == "" ||
+		netns.SocketPath(longestDataDir(cfg), kubestack.APIServerSocketFile) == ""):
+		r.add(name, CheckFail, "network namespaces need unix sockets, but the socket paths under %s are too long; use a shorter base data directory",
+			cfg.BaseDataDir)
+	case cfg.KineTCP:
+		r.add(name, CheckOK, "TCP port (KineTCP)")
+	case longestKineSocketPath(cfg) == "":
+		r.add(name, CheckWarn, "TCP port: unix sockets are unsupported on %s or the socket path under %s is too long; use a shorter base data directory to save a port per instance",
+			runtime.GOOS, cfg.BaseDataDir)
+	default:
+		r.add(name, CheckOK, "unix socket %s in each instance data directory", kine.SocketFile)
+	}
+}
+
+// checkBinary looks up binary on $PATH and reports whether it was found. It
+// returns the resolved path, or "" if it was not.
+func checkBinary(r *PreflightReport, name, binary string) string {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		r.add(name, CheckFail, "%v", err)
+		return ""
+	}
+	r.add(name, CheckOK, "%s", path)
+	return path
+}
+
+// checkKine checks the kine binary and returns its version, or nil if it is
+// missing or reported none.
+func checkKine(ctx context.Context, r *PreflightReport, binary string) *version.Version {
+	path := checkBinary(r, "kine binary", binary)
+	if path == "" {
+		return nil
+	}
+	out, err := runVersion(ctx, path)
+	if err != nil {
+		r.add("kine version", CheckWarn, "%v", err)
+		return nil
+	}
+	v, err := version.ParseGeneric(kineVersion(out))
+	if err != nil {
+		first, _, _ := strings.Cut(out, "\n")
+		r.add("kine version", CheckWarn, "no version in %q", first)
+		return nil
+	}
+	r.add("kine version", CheckOK, "v%s", v)
+	return v
+}
+
+// checkKubeAPIServer checks the kube-apiserver binary and returns its
+// version, or nil if it is missing or reported none.
+func checkKubeAPIServer(ctx context.Context, r *PreflightReport, binary string) *version.Version {
+	path := checkBinary(r, "kube-apiserver binary", binary)
+	if path == "" {
+		return nil
+	}
+	out, err := runVersion(ctx, path)
+	if err != nil {
+		r.add("kube-apiserver version", CheckFail, "%v", err)
+		return nil
+	}
+	v, err := apiserver.ParseVersionOutput(out)
+	if err != nil {
+		r.add("kube-apiserver version", CheckFail, "%v", err)
+		return nil
+	}
+	if v.LessThan(apiserver.MinSupportedVersion) {
+		r.add("kube-apiserver version", CheckWarn, "v%s is older than v%s, the oldest version k8senv supports", v, apiserver.MinSupportedVersion)
+		return v
+	}
+	r.add("kube-apiserver version", CheckOK, "v%s", v)
+	return v
+}
+
+// checkCompatibility checks that the kine version suits the kube-apiserver
+// version.
+func checkCompatibility(r *PreflightReport, kineVer, apiVer *version.Version) {
+	const name = "kine/kube-apiserver compatibility"
+	if !apiVer.LessThan(consistentListVersion) && kineVer.LessThan(kineProgressNotifyVersion) {
+		r.add(name, CheckWarn, "kine v%s does not answer watch progress requests, so kube-apiserver v%s serves consistent lists from storage instead of its watch cache; use kine v%s or later",
+			kineVer, apiVer, kineProgressNotifyVersion)
+		return
+	}
+	r.add(name, CheckOK, "kine v%s with kube-apiserver v%s", kineVer, apiVer)
+}
+
+// checkDataDir checks that dir can be created and written to, and how much
+// space is free on its file system.
+func checkDataDir(r *PreflightReport, dir string) {
+	if !checkWritable(r, "data directory", dir) {
+		return
+	}
+
+	free, ok := freeBytes(dir)
+	switch {
+	case !ok:
+		r.add("free space", CheckOK, "not checked on %s", runtime.GOOS)
+	case free < minFreeBytes:
+		r.add("free space", CheckWarn, "%s free in %s, below %s", formatBytes(free), dir, formatBytes(minFreeBytes))
+	default:
+		r.add("free space", CheckOK, "%s free in %s", formatBytes(free), dir)
+	}
+}
+
+// checkWritable checks that dir can be created and written to, reporting
+// the result under name.
+func checkWritable(r *PreflightReport, name, dir string) bool {
+	if err := fileutil.EnsureDir(dir); err != nil {
+		r.add(name, CheckFail, "create %s: %v", dir, err)
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".preflight-*")
+	if err != nil {
+		r.add(name, CheckFail, "%s is not writable: %v", dir, err)
+		return false
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	r.add(name, CheckOK, "%s is writable", dir)
+	return true
+}
+
+// checkPorts binds n ephemeral loopback ports at once, as many as the pools
+// use when full.
+func checkPorts(ctx context.Context, r *PreflightReport, n int) {
+	var lc net.ListenConfig
+	listeners := make([]net.Listener, 0, n)
+	defer func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+	for range n {
+		l, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+		if err != nil {
+			r.add("loopback ports", CheckFail, "bound %d of %d ports on 127.0.0.1: %v", len(listeners), n, err)
+			return
+		}
+		listeners = append(listeners, l)
+	}
+	r.add("loopback ports", CheckOK, "bound %d ports on 127.0.0.1", n)
+}
+
+// checkNetworkNamespace creates a namespace through the netns helper to
+// check that the host allows unprivileged user and network namespaces.
+func checkNetworkNamespace(ctx context.Context, r *PreflightReport) {
+	const name = "network namespace"
+	if err := netns.Probe(ctx); err != nil {
+		r.add(name, CheckFail, "%v; unprivileged user namespaces may be disabled (sysctl kernel.unprivileged_userns_clone or kernel.apparmor_restrict_unprivileged_userns)", err)
+		return
+	}
+	r.add(name, CheckOK, "user and network namespaces can be created")
+}
+
+// checkPdeathsig reports whether kine and kube-apiserver die with the test
+// process.
+func checkPdeathsig(r *PreflightReport) {
+	if process.PdeathsigSupported {
+		r.add("parent-death signal", CheckOK, "child processes receive SIGTERM if the test process dies")
+		return
+	}
+	r.add("parent-death signal", CheckWarn, "not supported on %s: kine and kube-apiserver outlive a killed test process; clean them up with pkill", runtime.GOOS)
+}
+
+// formatBytes formats n in binary units, e.g. "1.5 GiB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatUint(n, 10) + " B"
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package core
+
+// freeBytes is not implemented on this platform.
+func freeBytes(string) (uint64, bool) { return 0, false }
+
+// checkLimits is not implemented on this platform.
+func checkLimits(*PreflightReport, int) {}
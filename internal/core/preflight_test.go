@@ -0,0 +1,302 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/netns"
+)
+
+// findCheck returns the check named name in r, failing the test if there is
+// none.
+func findCheck(t *testing.T, r *PreflightReport, name string) PreflightCheck {
+	t.Helper()
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check in report:\n%+v", name, r.Checks)
+	return PreflightCheck{}
+}
+
+func TestPreflight(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver", "Kubernetes v1.34.1", 0),
+		KineBinary:          writeFakeBinary(t, dir, "kine", "kine version v0.14.12 (a1b2c3d)", 0),
+		BaseDataDir:         filepath.Join(dir, "data"),
+		PortLockDir:         filepath.Join(dir, "ports"),
+		PoolSize:            2,
+	}}
+
+	r := m.Preflight(t.Context())
+	if r.Failed() {
+		t.Fatalf("Preflight failed: %v", r.Err())
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	for name, want := range map[string]string{
+		"kine version":                      "v0.14.12",
+		"kube-apiserver version":            "v1.34.1",
+		"kine/kube-apiserver compatibility": "kine v0.14.12 with kube-apiserver v1.34.1",
+		"loopback ports":                    "bound 2 ports",
+		"kine listener":                     "unix socket",
+		"port lock directory":               "is writable",
+	} {
+		if c := findCheck(t, r, name); c.Status != CheckOK || !strings.Contains(c.Detail, want) {
+			t.Errorf("%s = %s %q, want ok containing %q", name, c.Status, c.Detail, want)
+		}
+	}
+	if _, err := os.Stat(m.cfg.BaseDataDir); err != nil {
+		t.Errorf("data directory not created: %v", err)
+	}
+	findCheck(t, r, "parent-death signal")
+}
+
+func TestPreflightFailures(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	// A regular file where the data directory's parent should be.
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver", "unknown flag: --version", 1),
+		KineBinary:          filepath.Join(dir, "missing-kine"),
+		BaseDataDir:         filepath.Join(blocker, "data"),
+	}}
+
+	r := m.Preflight(t.Context())
+	if !r.Failed() {
+		t.Fatal("Preflight passed, want failures")
+	}
+	for _, name := range []string{"kine binary", "kube-apiserver version", "data directory"} {
+		if c := findCheck(t, r, name); c.Status != CheckFail {
+			t.Errorf("%s = %s %q, want fail", name, c.Status, c.Detail)
+		}
+	}
+	err := r.Err()
+	if !errors.Is(err, ErrPreflightFailed) {
+		t.Errorf("Err() = %v, want ErrPreflightFailed", err)
+	}
+	if got := strings.Count(err.Error(), ErrPreflightFailed.Error()); got != 3 {
+		t.Errorf("Err() holds %d failures, want 3: %v", got, err)
+	}
+}
+
+func TestPreflightVersionWarnings(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		apiserver string
+		kine      string
+		check     string
+	}{
+		"old kube-apiserver": {
+			apiserver: "Kubernetes v1.28.4",
+			kine:      "kine version v0.14.12",
+			check:     "kube-apiserver version",
+		},
+		"old kine": {
+			apiserver: "Kubernetes v1.32.0",
+			kine:      "kine version v0.10.3",
+			check:     "kine/kube-apiserver compatibility",
+		},
+		"kine without version": {
+			apiserver: "Kubernetes v1.32.0",
+			kine:      "kine",
+			check:     "kine version",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			m := &Manager{cfg: ManagerConfig{
+				KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver", tt.apiserver, 0),
+				KineBinary:          writeFakeBinary(t, dir, "kine", tt.kine, 0),
+				BaseDataDir:         dir,
+			}}
+			r := m.Preflight(t.Context())
+			if c := findCheck(t, r, tt.check); c.Status != CheckWarn {
+				t.Errorf("%s = %s %q, want warn", tt.check, c.Status, c.Detail)
+			}
+			if r.Failed() {
+				t.Errorf("Preflight failed on a warning: %v", r.Err())
+			}
+		})
+	}
+}
+
+func TestPreflightManagersShareChecks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	kine := writeFakeBinary(t, dir, "kine", "kine version v0.14.12", 0)
+	newer := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver-1.34", "Kubernetes v1.34.1", 0),
+		KineBinary:          kine,
+		BaseDataDir:         dir,
+	}}
+	older := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver-1.31", "Kubernetes v1.31.2", 0),
+		KineBinary:          kine,
+		BaseDataDir:         dir,
+	}}
+
+	counts := make(map[string]int)
+	for _, c := range Preflight(t.Context(), older, newer).Checks {
+		counts[c.Name]++
+	}
+	want := map[string]int{
+		"kine binary":                       1,
+		"kube-apiserver binary":             2,
+		"kine/kube-apiserver compatibility": 2,
+		"data directory":                    1,
+		"loopback ports":                    1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("%d %q checks, want %d", counts[name], name, n)
+		}
+	}
+}
+
+func TestPreflightControllerManagerPorts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary:     writeFakeBinary(t, dir, "kube-apiserver", "Kubernetes v1.34.1", 0),
+		KineBinary:              writeFakeBinary(t, dir, "kine", "kine version v0.14.12 (a1b2c3d)", 0),
+		ControllerManagerBinary: writeFakeBinary(t, dir, "kube-controller-manager", "Kubernetes v1.34.1", 0),
+		BaseDataDir:             filepath.Join(dir, "data"),
+		PoolSize:                2,
+	}}
+
+	if c := findCheck(t, m.Preflight(t.Context()), "loopback ports"); !strings.Contains(c.Detail, "bound 4 ports") {
+		t.Errorf("loopback ports = %s %q, want 4 ports for kube-controller-manager", c.Status, c.Detail)
+	}
+}
+
+func TestPreflightKineListener(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver", "Kubernetes v1.34.1", 0),
+		KineBinary:          writeFakeBinary(t, dir, "kine", "kine version v0.14.12", 0),
+		BaseDataDir:         dir,
+		PoolSize:            3,
+	}
+	tcp := base
+	tcp.KineTCP = true
+	long := base
+	long.BaseDataDir = filepath.Join(dir, strings.Repeat("d", 120))
+	namespace := base
+	namespace.NetworkNamespace = true
+	// A 93-byte instance data directory fits kine.sock (103 bytes) but not
+	// apiserver.sock (107 bytes).
+	namespaceLong := namespace
+	namespaceLong.BaseDataDir = filepath.Join(dir, strings.Repeat("d", 93-len(dir)-2-len(instanceID(2, "12345678"))))
+
+	tests := map[string]struct {
+		cfg       ManagerConfig
+		status    CheckStatus
+		wantPorts string
+	}{
+		"socket":        {cfg: base, status: CheckOK, wantPorts: "bound 3 ports"},
+		"KineTCP":       {cfg: tcp, status: CheckOK, wantPorts: "bound 6 ports"},
+		"path too long": {cfg: long, status: CheckWarn, wantPorts: "bound 6 ports"},
+		"namespace":     {cfg: namespace, status: CheckOK, wantPorts: "bound 3 ports"},
+		"namespace, path too long": {
+			cfg: namespaceLong, status: CheckFail, wantPorts: "bound 3 ports",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			r := (&Manager{cfg: tt.cfg}).Preflight(t.Context())
+			if c := findCheck(t, r, "kine listener"); c.Status != tt.status {
+				t.Errorf("kine listener = %s %q, want %s", c.Status, c.Detail, tt.status)
+			}
+			if c := findCheck(t, r, "loopback ports"); !strings.Contains(c.Detail, tt.wantPorts) {
+				t.Errorf("loopback ports = %q, want %q", c.Detail, tt.wantPorts)
+			}
+		})
+	}
+}
+
+func TestPreflightNetworkNamespace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver", "Kubernetes v1.34.1", 0),
+		KineBinary:          writeFakeBinary(t, dir, "kine", "kine version v0.14.12", 0),
+		BaseDataDir:         dir,
+	}
+	for _, c := range (&Manager{cfg: cfg}).Preflight(t.Context()).Checks {
+		if c.Name == "network namespace" {
+			t.Errorf("network namespace checked without NetworkNamespace: %s %q", c.Status, c.Detail)
+		}
+	}
+
+	cfg.NetworkNamespace = true
+	c := findCheck(t, (&Manager{cfg: cfg}).Preflight(t.Context()), "network namespace")
+	want := CheckOK
+	if err := netns.Probe(t.Context()); err != nil {
+		want = CheckFail
+	}
+	if c.Status != want {
+		t.Errorf("network namespace = %s %q, want %s", c.Status, c.Detail, want)
+	}
+}
+
+func TestPreflightReportWriteTo(t *testing.T) {
+	t.Parallel()
+
+	r := &PreflightReport{Checks: []PreflightCheck{
+		{Name: "kine binary", Status: CheckOK, Detail: "/usr/local/bin/kine"},
+		{Name: "free space", Status: CheckWarn, Detail: "512.0 MiB free"},
+	}}
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[ok  ] kine binary: /usr/local/bin/kine\n[warn] free space: 512.0 MiB free\n"
+	if buf.String() != want {
+		t.Errorf("WriteTo wrote %q, want %q", buf.String(), want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("WriteTo returned %d, want %d", n, len(want))
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[uint64]string{
+		512:           "512 B",
+		1536:          "1.5 KiB",
+		1 << 30:       "1.0 GiB",
+		5 << 40 / 2:   "2.5 TiB",
+		3<<20 + 1<<19: "3.5 MiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build linux || darwin
+
+package core
+
+import (
+	"math"
+
+	"golang.org/x/sys/unix"
+)
+
+// freeBytes returns the space available to unprivileged users on the file
+// system holding dir.
+func freeBytes(dir string) (uint64, bool) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, false
+	}
+	return st.Bavail * uint64(st.Bsize), true //nolint:gosec // G115: block size is positive
+}
+
+// checkLimits checks the open file and process limits against the budgets
+// of instances instances. kine and kube-apiserver raise their soft open
+// file limit to the hard limit at startup, like every Go program, so the
+// hard limit is what counts; the process limit is enforced on the soft
+// limit.
+func checkLimits(r *PreflightReport, instances int) {
+	var lim unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &lim); err != nil {
+		r.add("open files limit", CheckWarn, "getrlimit: %v", err)
+	} else {
+		checkLimit(r, "open files limit", "ulimit -Hn", lim.Max, uint64(instances*openFilesPerInstance), instances) //nolint:gosec // G115: small positive product
+	}
+	if err := unix.Getrlimit(unix.RLIMIT_NPROC, &lim); err != nil {
+		r.add("process limit", CheckWarn, "getrlimit: %v", err)
+	} else {
+		checkLimit(r, "process limit", "ulimit -u", lim.Cur, uint64(instances*threadsPerInstance), instances) //nolint:gosec // G115: small positive product
+	}
+}
+
+// checkLimit reports limit against need.
+func checkLimit(r *PreflightReport, name, command string, limit, need uint64, instances int) {
+	switch {
+	case limit == unix.RLIM_INFINITY || limit == math.MaxInt64:
+		r.add(name, CheckOK, "unlimited")
+	case limit < need:
+		r.add(name, CheckWarn, "%d, below %d for %d instances; raise it with %s", limit, need, instances, command)
+	default:
+		r.add(name, CheckOK, "%d", limit)
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/giantswarm/k8senv/internal/sentinel"
+)
+
+// ErrSystemNamespace is returned by ServiceAccountConfig for a system
+// namespace. Release does not purge system namespaces, so a ServiceAccount
+// created there would leak into later leases.
+const ErrSystemNamespace = sentinel.Error("service accounts cannot be created in a system namespace")
+
+// serviceAccountTokenTTL is the lifetime requested for tokens issued by
+// ServiceAccountConfig. One hour outlasts any reasonable test while keeping
+// the token bound to a single test run.
+const serviceAccountTokenTTL int64 = 3600
+
+// ServiceAccountConfig returns a *rest.Config that authenticates as the
+// ServiceAccount name in namespace ns. The namespace and ServiceAccount are
+// created if they do not exist, and the token is obtained from the
+// TokenRequest API, so it is a JWT signed by kube-apiserver's service
+// account key exactly as a kubelet-projected token would be.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, ErrNotStarted if it has not been started yet, and ErrSystemNamespace
+// if ns is a system namespace.
+func (i *Instance) ServiceAccountConfig(ctx context.Context, ns, name string) (*rest.Config, error) {
+	cfg, err := i.Config()
+	if err != nil {
+		return nil, err
+	}
+	if ns == "" || name == "" {
+		return nil, fmt.Errorf("service account namespace and name must not be empty, got %q/%q", ns, name)
+	}
+	if isSystemNamespace(ns) {
+		return nil, fmt.Errorf("service account %s/%s: %w", ns, name, ErrSystemNamespace)
+	}
+	client, err := i.getOrBuildInternalClient()
+	if err != nil {
+		return nil, fmt.Errorf("build client for service account: %w", err)
+	}
+
+	_, err = client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: ns},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("create namespace %s: %w", ns, err)
+	}
+	_, err = client.CoreV1().ServiceAccounts(ns).Create(ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("create service account %s/%s: %w", ns, name, err)
+	}
+
+	ttl := serviceAccountTokenTTL
+	tr, err := client.CoreV1().ServiceAccounts(ns).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &ttl},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("request token for service account %s/%s: %w", ns, name, err)
+	}
+
+	// Replace the admin credentials with the ServiceAccount token, keeping
+	// the CA so the server is still verified.
+	cfg.BearerToken = tr.Status.Token
+	cfg.BearerTokenFile = ""
+	cfg.CertData = nil
+	cfg.CertFile = ""
+	cfg.KeyData = nil
+	cfg.KeyFile = ""
+	return cfg, nil
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInstanceServiceAccountConfigPreconditions(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	if _, err := inst.ServiceAccountConfig(t.Context(), "default", "app"); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("ServiceAccountConfig() on free instance error = %v, want ErrInstanceReleased", err)
+	}
+
+	if err := os.WriteFile(inst.kubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	inst.markAcquired()
+	inst.started.Store(true)
+	for _, tc := range [][2]string{{"", "app"}, {"team-a", ""}} {
+		_, err := inst.ServiceAccountConfig(t.Context(), tc[0], tc[1])
+		if err == nil || !strings.Contains(err.Error(), "must not be empty") {
+			t.Errorf("ServiceAccountConfig(%q, %q) error = %v, want empty name error", tc[0], tc[1], err)
+		}
+	}
+	for _, ns := range SystemNamespaceNames() {
+		if _, err := inst.ServiceAccountConfig(t.Context(), ns, "app"); !errors.Is(err, ErrSystemNamespace) {
+			t.Errorf("ServiceAccountConfig(%q, \"app\") error = %v, want ErrSystemNamespace", ns, err)
+		}
+	}
+}
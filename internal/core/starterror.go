@@ -0,0 +1,132 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/giantswarm/k8senv/internal/kubestack"
+	"github.com/giantswarm/k8senv/internal/process"
+)
+
+// StartPhase identifies the step of instance startup that failed.
+type StartPhase string
+
+// Startup phases, in the order an instance goes through them.
+const (
+	// PhaseBinaryLookup: a kine, kube-apiserver or kube-controller-manager
+	// binary could not be found or executed.
+	PhaseBinaryLookup StartPhase = "binary lookup"
+
+	// PhaseSetup: preparing the data directory, ports, database, certificates
+	// or kubeconfig failed.
+	PhaseSetup StartPhase = "setup"
+
+	// PhaseKineReady: kine exited or did not accept connections in time.
+	PhaseKineReady StartPhase = "kine ready"
+
+	// PhaseAPIServerReady: kube-apiserver exited or did not report /livez in
+	// time.
+	PhaseAPIServerReady StartPhase = "apiserver ready"
+
+	// PhaseControllerManagerReady: kube-controller-manager exited or did not
+	// report /healthz in time.
+	PhaseControllerManagerReady StartPhase = "controller manager ready"
+
+	// PhaseSystemNamespaces: kube-apiserver was live but did not create the
+	// system namespaces in time.
+	PhaseSystemNamespaces StartPhase = "system namespaces"
+)
+
+// maxStderrTail is the number of bytes of stderr kept in a StartError.
+const maxStderrTail = 4 << 10
+
+// StartError is returned when an instance fails to start. Retrieve it with
+// errors.As to classify the failure, for example as a missing binary (Phase
+// PhaseBinaryLookup), a port conflict ("address already in use" in
+// StderrTail) or an OOM kill (ExitCode 137).
+type StartError struct {
+	// Phase is the step that failed.
+	Phase StartPhase
+	// Attempts is the number of times kine and kube-apiserver were launched.
+	Attempts int
+	// ExitCode is the exit code of the failed process if it exited before
+	// becoming ready (128 plus the signal number if it was killed), and -1
+	// otherwise.
+	ExitCode int
+	// StderrTail holds the last lines of the failed process's stderr (of
+	// kube-apiserver in PhaseSystemNamespaces), or is empty if no process
+	// was involved.
+	StderrTail string
+	// Port is the port of the failed process, or 0 if none was allocated,
+	// as for a kine listening on a unix socket.
+	Port int
+	// Err is the underlying failure.
+	Err error
+}
+
+// Error returns the phase, the attempt count and the underlying failure.
+func (e *StartError) Error() string {
+	return fmt.Sprintf("start failed in phase %q after %d attempt(s): %v", e.Phase, e.Attempts, e.Err)
+}
+
+// Unwrap returns the underlying failure.
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// newStartError classifies a failure of StartWithRetry. launches is the
+// number of launches made by earlier namespace retry rounds, to which the
+// failed call's own launches are added.
+func (i *Instance) newStartError(err error, launches int) *StartError {
+	se := &StartError{Phase: PhaseSetup, Attempts: launches, ExitCode: -1, Err: err}
+	if ae, ok := errors.AsType[*kubestack.AttemptError](err); ok {
+		se.Attempts += ae.Attempts
+	}
+
+	if _, ok := errors.AsType[*exec.Error](err); ok {
+		se.Phase = PhaseBinaryLookup
+		return se
+	}
+	pe, ok := errors.AsType[*kubestack.ProcessError](err)
+	if !ok {
+		return se
+	}
+	switch pe.Process {
+	case kineProcess:
+		se.Phase = PhaseKineReady
+	case apiServerProcess:
+		se.Phase = PhaseAPIServerReady
+	case controllerManagerProcess:
+		se.Phase = PhaseControllerManagerReady
+	}
+	se.Port = pe.Port
+	se.ExitCode = pe.ExitCode
+	se.StderrTail = i.stderrTail(pe.Process)
+	return se
+}
+
+// stderrTail returns the last maxStderrTail bytes of the stderr log of
+// processName in the data directory, starting at a line boundary, or "" if
+// it cannot be read.
+func (i *Instance) stderrTail(processName string) string {
+	f, err := openTail(filepath.Join(i.dataDir, process.StderrFile(processName)), maxStderrTail)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	tail, err := io.ReadAll(f)
+	if err != nil {
+		return ""
+	}
+	if len(tail) == maxStderrTail {
+		if nl := bytes.IndexByte(tail, '\n'); nl >= 0 {
+			tail = tail[nl+1:]
+		}
+	}
+	return string(tail)
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/kubestack"
+)
+
+func TestStartErrorBinaryLookup(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	inst.cfg.KubeAPIServerBinary = "/nonexistent/kube-apiserver"
+
+	err := inst.Start(context.Background())
+	se, ok := errors.AsType[*StartError](err)
+	if !ok {
+		t.Fatalf("Start() error = %v, want *StartError", err)
+	}
+	if se.Phase != PhaseBinaryLookup || se.ExitCode != -1 || se.Port != 0 {
+		t.Errorf("StartError = %+v, want binary lookup phase without exit code or port", se)
+	}
+}
+
+func TestNewStartErrorProcess(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	var stderr strings.Builder
+	for n := 0; stderr.Len() <= maxStderrTail; n++ {
+		fmt.Fprintf(&stderr, "line %d\n", n)
+	}
+	stderr.WriteString("bind: address already in use\n")
+	if err := os.WriteFile(filepath.Join(inst.dataDir, "kube-apiserver-stderr.log"), []byte(stderr.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := &kubestack.AttemptError{Attempts: 3, Err: &kubestack.ProcessError{
+		Process:  "kube-apiserver",
+		Port:     6443,
+		ExitCode: 1,
+		Err:      errors.New("apiserver readiness: process exited"),
+	}}
+	se := inst.newStartError(err, 2)
+
+	if se.Phase != PhaseAPIServerReady || se.Port != 6443 || se.ExitCode != 1 {
+		t.Errorf("StartError = %+v, want apiserver ready phase, port 6443, exit code 1", se)
+	}
+	if se.Attempts != 5 {
+		t.Errorf("Attempts = %d, want 5 (2 earlier launches plus 3)", se.Attempts)
+	}
+	if !strings.HasPrefix(se.StderrTail, "line ") || !strings.HasSuffix(se.StderrTail, "address already in use\n") {
+		t.Errorf("StderrTail does not hold whole lines up to the end: %q", se.StderrTail)
+	}
+	if len(se.StderrTail) > maxStderrTail {
+		t.Errorf("StderrTail is %d bytes, want at most %d", len(se.StderrTail), maxStderrTail)
+	}
+	if !errors.Is(se, err.Err) {
+		t.Error("StartError does not unwrap to the process error")
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// StorageRecord is the current row for one key in kine's SQLite database, as
+// written by kube-apiserver. Value holds the bytes exactly as stored: encoded
+// with the apiserver's storage codec (protobuf values start with "k8s\x00",
+// JSON values with "{") and, when encryption at rest applies, prefixed with
+// "k8s:enc:<provider>:v1:<key name>:".
+type StorageRecord struct {
+	// Key is the storage key, e.g. "/registry/secrets/default/my-secret".
+	Key string
+
+	// Revision is the revision (kine row ID) of the last write to Key.
+	Revision int64
+
+	// CreateRevision is the revision at which Key was created.
+	CreateRevision int64
+
+	// Value is the raw stored value.
+	Value []byte
+}
+
+// rawStorageQuery selects the latest row of every live key. kine appends a new
+// row per write, so the row with the highest ID for a name is current; names
+// whose latest row is a deletion tombstone are skipped. kine's internal
+// bookkeeping rows (compact_rev_key, gap markers) do not start with "/" and
+// are filtered out.
+const rawStorageQuery = `SELECT kv.name, kv.id, kv.create_revision, kv.value
+FROM kine AS kv
+JOIN (SELECT MAX(id) AS id FROM kine GROUP BY name) AS latest ON kv.id = latest.id
+WHERE kv.deleted = 0 AND kv.name LIKE '/%'
+ORDER BY kv.name`
+
+// RawStorage returns the current storage record of every key in the
+// instance's kine database, ordered by key. It reads SQLite directly through
+// a separate read-only connection, bypassing kube-apiserver, so values are
+// returned exactly as stored (e.g., encrypted when encryption at rest is
+// enabled).
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrNotStarted if it has not been started yet.
+func (i *Instance) RawStorage(ctx context.Context) ([]StorageRecord, error) {
+	if !i.IsBusy() {
+		return nil, ErrInstanceReleased
+	}
+	if !i.started.Load() {
+		return nil, ErrNotStarted
+	}
+	return readRawStorage(ctx, i.sqlitePath)
+}
+
+// openReadOnly opens a single read-only connection to sqlitePath. A fresh
+// connection is used per call, as with openPurgeHandle, with a busy timeout
+// so reads tolerate kine's writes.
+func openReadOnly(sqlitePath string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(%d)", sqlitePath, sqliteBusyTimeoutMs)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", sqlitePath, err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// readRawStorage opens sqlitePath read-only and returns the result of
+// rawStorageQuery.
+func readRawStorage(ctx context.Context, sqlitePath string) (records []StorageRecord, err error) {
+	db, err := openReadOnly(sqlitePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, db.Close())
+	}()
+
+	rows, err := db.QueryContext(ctx, rawStorageQuery)
+	if err != nil {
+		return nil, fmt.Errorf("query raw storage: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+
+	for rows.Next() {
+		var rec StorageRecord
+		if err := rows.Scan(&rec.Key, &rec.Revision, &rec.CreateRevision, &rec.Value); err != nil {
+			return nil, fmt.Errorf("scan raw storage row: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate raw storage rows: %w", err)
+	}
+	return records, nil
+}
+
+// snapshotSQLite writes a consistent copy of the database at sqlitePath to
+// dst with VACUUM INTO over a read-only connection. Copying the database,
+// -wal and -shm files instead could capture a torn state while kine writes.
+// Returns an error wrapping fs.ErrNotExist if there is no database yet.
+func snapshotSQLite(ctx context.Context, sqlitePath, dst string) (err error) {
+	// Opening read-only does not create the file, but fails with a less
+	// specific error; check first so callers can skip a missing database.
+	if _, err := os.Stat(sqlitePath); err != nil {
+		return fmt.Errorf("stat sqlite: %w", err)
+	}
+	db, err := openReadOnly(sqlitePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, db.Close())
+	}()
+
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", dst); err != nil {
+		return fmt.Errorf("snapshot sqlite %s: %w", sqlitePath, err)
+	}
+	if err := os.Chmod(dst, 0o600); err != nil {
+		return fmt.Errorf("chmod sqlite snapshot: %w", err)
+	}
+	return nil
+}
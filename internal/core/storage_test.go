@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// kineTestSchema is the subset of kine's SQLite schema read by rawStorageQuery.
+const kineTestSchema = `CREATE TABLE kine (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT,
+	created INTEGER,
+	deleted INTEGER,
+	create_revision INTEGER,
+	prev_revision INTEGER,
+	lease INTEGER,
+	value BLOB,
+	old_value BLOB
+)`
+
+// openTestKineDB opens (creating) the SQLite database at dsn and creates the
+// kine table. The connection is closed when the test ends.
+func openTestKineDB(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck,gosec // test cleanup
+	if _, err := db.Exec(kineTestSchema); err != nil {
+		t.Fatalf("create kine table: %v", err)
+	}
+	return db
+}
+
+func TestReadRawStorage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.db")
+	db := openTestKineDB(t, path)
+
+	stmts := []string{
+		`INSERT INTO kine (name, created, deleted, create_revision, value) VALUES ('compact_rev_key', 1, 0, 0, '')`,
+		`INSERT INTO kine (name, created, deleted, create_revision, value) VALUES ('/registry/configmaps/default/a', 1, 0, 0, 'v1')`,
+		`INSERT INTO kine (name, created, deleted, create_revision, value) VALUES ('/registry/configmaps/default/a', 0, 0, 3, 'v2')`,
+		`INSERT INTO kine (name, created, deleted, create_revision, value) VALUES ('/registry/secrets/default/gone', 1, 0, 0, 's')`,
+		`INSERT INTO kine (name, created, deleted, create_revision, value) VALUES ('/registry/secrets/default/gone', 0, 1, 5, 's')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+
+	records, err := readRawStorage(context.Background(), path)
+	if err != nil {
+		t.Fatalf("readRawStorage() unexpected error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("readRawStorage() returned %d records, want 1: %+v", len(records), records)
+	}
+	got := records[0]
+	if got.Key != "/registry/configmaps/default/a" || got.Revision != 3 || string(got.Value) != "v2" {
+		t.Errorf("readRawStorage()[0] = %+v, want latest revision of configmap a", got)
+	}
+}
+
+func TestInstanceRawStorageReleased(t *testing.T) {
+	t.Parallel()
+
+	inst := newTestInstance(t)
+	if _, err := inst.RawStorage(context.Background()); !errors.Is(err, ErrInstanceReleased) {
+		t.Errorf("RawStorage() error = %v, want ErrInstanceReleased", err)
+	}
+}
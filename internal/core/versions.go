@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
+	"k8s.io/apimachinery/pkg/util/version"
+	apiversion "k8s.io/apimachinery/pkg/version"
+)
+
+// Versions holds the versions of the binaries a Manager runs, as reported by
+// their --version flag during Initialize.
+type Versions struct {
+	// KubeAPIServer is the kube-apiserver version, e.g. "v1.34.1".
+	KubeAPIServer string
+	// Kine is the kine version, e.g. "v0.13.5", or empty if kine did not
+	// report one.
+	Kine string
+}
+
+// detectVersions runs "<binary> --version" for kube-apiserver and kine and
+// returns the reported versions along with the parsed kube-apiserver
+// version. A kube-apiserver version that cannot be determined is an error,
+// since the flags k8senv passes depend on it; a kine version is only
+// informational, so failing to get it is logged.
+func (m *Manager) detectVersions(ctx context.Context) (Versions, *version.Version, error) {
+	out, err := runVersion(ctx, m.cfg.KubeAPIServerBinary)
+	if err != nil {
+		return Versions{}, nil, fmt.Errorf("detect kube-apiserver version: %w", err)
+	}
+	parsed, err := apiserver.ParseVersionOutput(out)
+	if err != nil {
+		return Versions{}, nil, fmt.Errorf("detect kube-apiserver version: %w", err)
+	}
+	fields := strings.Fields(out)
+	versions := Versions{KubeAPIServer: fields[len(fields)-1]}
+
+	out, err = runVersion(ctx, m.cfg.KineBinary)
+	if err != nil {
+		Logger().Warn("detect kine version", "error", err)
+		return versions, parsed, nil
+	}
+	versions.Kine = kineVersion(out)
+	return versions, parsed, nil
+}
+
+// runVersion returns the trimmed output of "binary --version".
+func runVersion(ctx context.Context, binary string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+	//nolint:gosec // G204: binary path is from config, not user input
+	out, err := exec.CommandContext(ctx, binary, "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("run %s --version: %w", binary, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// kineVersion extracts the version from the output of "kine --version",
+// e.g. "kine version v0.13.5 (a1b2c3d)". It returns "" if the output holds
+// no "v<digit>..." word.
+func kineVersion(out string) string {
+	for field := range strings.FieldsSeq(out) {
+		if len(field) > 1 && field[0] == 'v' && field[1] >= '0' && field[1] <= '9' {
+			return field
+		}
+	}
+	return ""
+}
+
+// Versions returns the kube-apiserver and kine versions detected by
+// Initialize.
+//
+// Returns ErrNotInitialized if Initialize has not completed.
+func (m *Manager) Versions() (Versions, error) {
+	v := m.versions.Load()
+	if v == nil {
+		return Versions{}, ErrNotInitialized
+	}
+	return *v, nil
+}
+
+// ServerVersion returns the version kube-apiserver reports through the
+// discovery /version endpoint.
+//
+// Returns ErrInstanceReleased if the instance has been released back to the
+// pool, and ErrNotStarted if it has not been started yet.
+func (i *Instance) ServerVersion(ctx context.Context) (*apiversion.Info, error) {
+	if _, err := i.Config(); err != nil {
+		return nil, err
+	}
+	client, err := i.getOrBuildInternalClient()
+	if err != nil {
+		return nil, fmt.Errorf("build client for server version: %w", err)
+	}
+	data, err := client.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("get server version: %w", err)
+	}
+	var info apiversion.Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("decode server version: %w", err)
+	}
+	return &info, nil
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeFakeBinary writes a shell script named name to dir that prints output
+// and exits with code, and returns its path.
+func writeFakeBinary(t *testing.T, dir, name, output string, code int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\necho '" + output + "'\nexit " + strconv.Itoa(code) + "\n"
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil { //nolint:gosec // G306: test script must be executable
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDetectVersions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "kube-apiserver", "Kubernetes v1.31.2", 0),
+		KineBinary:          writeFakeBinary(t, dir, "kine", "kine version v0.13.5 (a1b2c3d)", 0),
+	}}
+
+	versions, parsed, err := m.detectVersions(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Versions{KubeAPIServer: "v1.31.2", Kine: "v0.13.5"}
+	if versions != want {
+		t.Errorf("versions = %+v, want %+v", versions, want)
+	}
+	if parsed.Major() != 1 || parsed.Minor() != 31 {
+		t.Errorf("parsed version = %s, want 1.31", parsed)
+	}
+}
+
+func TestDetectVersionsFailures(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	apiserver := writeFakeBinary(t, dir, "kube-apiserver", "Kubernetes v1.34.0", 0)
+
+	m := &Manager{cfg: ManagerConfig{
+		KubeAPIServerBinary: writeFakeBinary(t, dir, "broken-apiserver", "unknown flag: --version", 1),
+		KineBinary:          apiserver,
+	}}
+	if _, _, err := m.detectVersions(t.Context()); err == nil || !strings.Contains(err.Error(), "kube-apiserver version") {
+		t.Errorf("detectVersions() with failing kube-apiserver = %v, want error", err)
+	}
+
+	// A kine that reports no version is not an error.
+	m.cfg = ManagerConfig{
+		KubeAPIServerBinary: apiserver,
+		KineBinary:          writeFakeBinary(t, dir, "old-kine", "flag provided but not defined: -version", 1),
+	}
+	versions, _, err := m.detectVersions(t.Context())
+	if err != nil || versions.Kine != "" {
+		t.Errorf("detectVersions() with failing kine = %+v, %v; want empty kine version, nil", versions, err)
+	}
+}
+
+func TestKineVersion(t *testing.T) {
+	t.Parallel()
+
+	for out, want := range map[string]string{
+		"kine version v0.13.5 (a1b2c3d)": "v0.13.5",
+		"v0.11.0":                        "v0.11.0",
+		"kine version dev":               "",
+		"":                               "",
+	} {
+		if got := kineVersion(out); got != want {
+			t.Errorf("kineVersion(%q) = %q, want %q", out, got, want)
+		}
+	}
+}
+
+func TestManagerVersionsNotInitialized(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (&Manager{}).Versions(); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Versions() error = %v, want ErrNotInitialized", err)
+	}
+}
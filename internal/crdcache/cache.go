@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/giantswarm/k8senv/internal/apiserver"
 	"github.com/giantswarm/k8senv/internal/fileutil"
 	"github.com/giantswarm/k8senv/internal/kubestack"
 	"github.com/giantswarm/k8senv/internal/netutil"
@@ -24,14 +25,19 @@ import (
 
 // Config holds configuration for CRD cache initialization.
 type Config struct {
-	CRDDir              string                // Directory containing YAML files
-	CacheDir            string                // Directory to store cached databases
-	KineBinary          string                // Path to kine binary
-	KubeAPIServerBinary string                // Path to kube-apiserver binary
-	Timeout             time.Duration         // Overall timeout for cache creation
-	StopTimeout         time.Duration         // Timeout for stopping the temporary kube stack (zero uses 10s default)
-	PortRegistry        *netutil.PortRegistry // Shared port registry for cross-instance coordination
-	Logger              *slog.Logger          // Logger for operational messages (nil uses slog.Default)
+	CRDDir               string                // Directory containing YAML files
+	CacheDir             string                // Directory to store cached databases
+	KineBinary           string                // Path to kine binary
+	KubeAPIServerBinary  string                // Path to kube-apiserver binary
+	APIServerOptions     apiserver.Options     // Options of the manager's kube-apiserver; the cache is built with their StorageOptions
+	KubeAPIServerVersion string                // Reported kube-apiserver version for the cache key (empty hashes the binary)
+	KineVersion          string                // Reported kine version for the cache key (empty hashes the binary)
+	Timeout              time.Duration         // Overall timeout for cache creation
+	StopTimeout          time.Duration         // Timeout for stopping the temporary kube stack (zero uses 10s default)
+	KineTCP              bool                  // Make kine listen on a TCP port instead of a unix socket
+	NetworkNamespace     bool                  // Run kine and kube-apiserver in network namespaces of their own
+	PortRegistry         *netutil.PortRegistry // Shared port registry for cross-instance coordination
+	Logger               *slog.Logger          // Logger for operational messages (nil uses slog.Default)
 }
 
 // logger returns the configured logger or falls back to the default.
@@ -42,6 +48,13 @@ func (c Config) logger() *slog.Logger {
 	return slog.Default()
 }
 
+// apiServerOptions returns the options of the temporary kube-apiserver that
+// builds the cache: the storage-shaping subset of the manager's options, so
+// that cached rows match what its instances would write.
+func (c Config) apiServerOptions() apiserver.Options {
+	return c.APIServerOptions.StorageOptions()
+}
+
 // stopTimeout returns the configured stop timeout or the default.
 func (c Config) stopTimeout() time.Duration {
 	return process.ResolveStopTimeout(c.StopTimeout)
@@ -78,13 +91,17 @@ func (c Config) validate() error {
 
 // Result contains the outcome of cache initialization.
 type Result struct {
-	CachePath string // Path to the cached database file
-	Hash      string // Hash of the CRD directory contents
-	Created   bool   // true if cache was created, false if existing cache was used
+	CachePath string    // Path to the cached database file
+	Hash      string    // Cache key: CRD directory contents, binary versions and storage flags
+	Manifest  *Manifest // What the cache is built from, also written next to the database
+	Created   bool      // true if cache was created, false if existing cache was used
 }
 
 // EnsureCache checks for an existing cache or creates one.
-// If a cache with matching content hash exists, it returns immediately.
+// If a cache with a matching key exists, it returns immediately. The key
+// covers the CRD directory contents, the kube-apiserver and kine versions
+// and the storage-affecting kube-apiserver flags, so upgrading a binary
+// builds a new cache instead of reusing one written by another version.
 // Otherwise, it creates a new cache by spinning up a temporary kine + kube-apiserver,
 // applying the YAML files, and copying the resulting database.
 func EnsureCache(ctx context.Context, cfg Config) (*Result, error) {
@@ -95,10 +112,15 @@ func EnsureCache(ctx context.Context, cfg Config) (*Result, error) {
 	// Compute hash of CRD directory contents and collect file contents.
 	// The files (with their contents) are threaded through to applyYAMLFiles
 	// to avoid both a redundant directory walk and redundant disk reads.
-	hash, files, err := computeDirHash(cfg.CRDDir)
+	crdHash, files, err := computeDirHash(cfg.CRDDir)
 	if err != nil {
 		return nil, fmt.Errorf("compute dir hash: %w", err)
 	}
+	manifest, err := newManifest(cfg, crdHash, files)
+	if err != nil {
+		return nil, fmt.Errorf("compute cache key: %w", err)
+	}
+	hash := manifest.Key
 
 	cachePath := filepath.Join(cfg.CacheDir, fmt.Sprintf("cached-%s.db", hash))
 
@@ -107,7 +129,7 @@ func EnsureCache(ctx context.Context, cfg Config) (*Result, error) {
 	// Check if cache already exists
 	if _, err := os.Stat(cachePath); err == nil {
 		logger.Info("using existing CRD cache", "cache_path", cachePath, "hash", hash)
-		return &Result{CachePath: cachePath, Hash: hash, Created: false}, nil
+		return &Result{CachePath: cachePath, Hash: hash, Manifest: manifest, Created: false}, nil
 	} else if !errors.Is(err, os.ErrNotExist) {
 		return nil, fmt.Errorf("stat cache file %s: %w", cachePath, err)
 	}
@@ -126,18 +148,25 @@ func EnsureCache(ctx context.Context, cfg Config) (*Result, error) {
 	// Re-check cache (another process might have created it while we waited for lock)
 	if _, err := os.Stat(cachePath); err == nil {
 		logger.Info("using existing CRD cache (created while waiting)", "cache_path", cachePath, "hash", hash)
-		return &Result{CachePath: cachePath, Hash: hash, Created: false}, nil
+		return &Result{CachePath: cachePath, Hash: hash, Manifest: manifest, Created: false}, nil
 	} else if !errors.Is(err, os.ErrNotExist) {
 		return nil, fmt.Errorf("stat cache file %s after lock acquisition: %w", cachePath, err)
 	}
 
 	// Create cache
-	logger.Info("creating CRD cache", "crd_dir", cfg.CRDDir, "hash", hash)
+	logger.Info("creating CRD cache", "crd_dir", cfg.CRDDir, "hash", hash,
+		"kube_apiserver", manifest.KubeAPIServer, "kine", manifest.Kine)
 	if err := createCache(ctx, cfg, cachePath, files); err != nil {
 		return nil, fmt.Errorf("create cache: %w", err)
 	}
 
-	return &Result{CachePath: cachePath, Hash: hash, Created: true}, nil
+	// The manifest is informational: the database alone marks the cache as
+	// present, so a failure to write it does not fail the build.
+	if err := writeManifest(manifestPath(cachePath), manifest); err != nil {
+		logger.Warn("failed to write CRD cache manifest", "cache_path", cachePath, "err", err)
+	}
+
+	return &Result{CachePath: cachePath, Hash: hash, Manifest: manifest, Created: true}, nil
 }
 
 // createCache spins up a temporary kine + kube-apiserver, applies CRDs, and copies the DB.
@@ -211,9 +240,12 @@ func populateCache(ctx context.Context, cfg Config, tempDir, cachePath string, f
 		KubeconfigPath:        kubeconfigPath,
 		KineBinary:            cfg.KineBinary,
 		APIServerBinary:       cfg.KubeAPIServerBinary,
+		APIServerOptions:      cfg.apiServerOptions(),
 		KineReadyTimeout:      cfg.Timeout,
 		APIServerReadyTimeout: cfg.Timeout,
 		StopTimeout:           stopTimeout,
+		KineTCP:               cfg.KineTCP,
+		NetworkNamespace:      cfg.NetworkNamespace,
 		PortRegistry:          cfg.PortRegistry,
 		Logger:                logger,
 	}, maxKubestackStartRetries)
@@ -0,0 +1,136 @@
+package crdcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Manifest describes what a cached database was built from. It is written
+// as JSON next to the database (cached-<key>.json for cached-<key>.db), so a
+// cache directory can be inspected without reverse-engineering the key.
+//
+// Every field except Files and CreatedAt is part of the cache key: a cache
+// is only reused by a manager running the same binaries with the same
+// storage-affecting flags.
+type Manifest struct {
+	// Key is the cache key, the suffix of the database file name.
+	Key string `json:"key"`
+
+	// CRDHash is the hash of the CRD directory contents.
+	CRDHash string `json:"crdHash"`
+
+	// Files lists the YAML files applied, relative to the CRD directory.
+	Files []string `json:"files"`
+
+	// KubeAPIServer identifies the kube-apiserver binary: its reported
+	// version, or "sha256:<digest>" of the binary if it reported none.
+	KubeAPIServer string `json:"kubeAPIServer"`
+
+	// Kine identifies the kine binary, like KubeAPIServer.
+	Kine string `json:"kine"`
+
+	// StorageArgs are the kube-apiserver flags that shape stored objects.
+	StorageArgs []string `json:"storageArgs"`
+
+	// CreatedAt is when the database was built.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// newManifest returns the manifest of a cache built from files, whose
+// directory hash is crdHash, with the binaries and flags of cfg. Key is
+// derived from the other keyed fields.
+func newManifest(cfg Config, crdHash string, files []hashedFile) (*Manifest, error) {
+	apiserverID, err := binaryIdentity(cfg.KubeAPIServerVersion, cfg.KubeAPIServerBinary)
+	if err != nil {
+		return nil, fmt.Errorf("identify kube-apiserver: %w", err)
+	}
+	kineID, err := binaryIdentity(cfg.KineVersion, cfg.KineBinary)
+	if err != nil {
+		return nil, fmt.Errorf("identify kine: %w", err)
+	}
+
+	m := &Manifest{
+		CRDHash:       crdHash,
+		Files:         make([]string, 0, len(files)),
+		KubeAPIServer: apiserverID,
+		Kine:          kineID,
+		StorageArgs:   cfg.apiServerOptions().StorageArgs(),
+	}
+	for _, f := range files {
+		m.Files = append(m.Files, f.relPath)
+	}
+	m.Key = m.key()
+	return m, nil
+}
+
+// key hashes the keyed fields of m into a cache key, truncated to 16 hex
+// characters like the CRD directory hash.
+func (m *Manifest) key() string {
+	h := sha256.New()
+	for _, field := range []string{m.CRDHash, m.KubeAPIServer, m.Kine} {
+		h.Write([]byte(field + "\x00")) // hash.Hash.Write never returns an error
+	}
+	for _, arg := range m.StorageArgs {
+		h.Write([]byte(arg + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// binaryIdentity returns version, or, if the binary reported no version,
+// the SHA256 digest of the binary at path as "sha256:<hex>".
+func binaryIdentity(version, path string) (string, error) {
+	if version != "" {
+		return version, nil
+	}
+	resolved := path
+	if !strings.ContainsRune(path, filepath.Separator) {
+		lookedUp, err := exec.LookPath(path)
+		if err != nil {
+			return "", fmt.Errorf("look up binary: %w", err)
+		}
+		resolved = lookedUp
+	}
+	f, err := os.Open(resolved) //nolint:gosec // G304: binary path is from config, not user input
+	if err != nil {
+		return "", fmt.Errorf("open binary: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash binary %s: %w", resolved, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// manifestPath returns the path of the manifest of the database at cachePath.
+func manifestPath(cachePath string) string {
+	return strings.TrimSuffix(cachePath, filepath.Ext(cachePath)) + ".json"
+}
+
+// writeManifest writes m, stamped with the current time, to path. The file
+// is written to a temporary name and renamed so readers never see a partial
+// manifest.
+func writeManifest(path string, m *Manifest) error {
+	m.CreatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package crdcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
+	"github.com/giantswarm/k8senv/internal/netutil"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestNewManifest_KeyInputs(t *testing.T) {
+	t.Parallel()
+
+	files := []hashedFile{{relPath: "a.yaml"}, {relPath: "sub/b.yaml"}}
+	base := Config{
+		KineBinary:           "kine",
+		KubeAPIServerBinary:  "kube-apiserver",
+		KubeAPIServerVersion: "v1.34.1",
+		KineVersion:          "v0.13.5",
+	}
+	baseManifest, err := newManifest(base, "crdhash", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(baseManifest.Files, []string{"a.yaml", "sub/b.yaml"}) {
+		t.Errorf("Files = %v", baseManifest.Files)
+	}
+	if len(baseManifest.Key) != 16 {
+		t.Errorf("Key = %q, want 16 hex characters", baseManifest.Key)
+	}
+
+	again, err := newManifest(base, "crdhash", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Key != baseManifest.Key {
+		t.Errorf("Key not deterministic: %q != %q", again.Key, baseManifest.Key)
+	}
+
+	tests := map[string]struct {
+		modify  func(c *Config)
+		crdHash string
+	}{
+		"CRD contents":           {modify: func(_ *Config) {}, crdHash: "otherhash"},
+		"kube-apiserver version": {modify: func(c *Config) { c.KubeAPIServerVersion = "v1.33.4" }},
+		"kine version":           {modify: func(c *Config) { c.KineVersion = "v0.14.0" }},
+		"admission plugins": {modify: func(c *Config) {
+			c.APIServerOptions.DisableAdmissionPlugins = []string{"NamespaceLifecycle"}
+		}},
+		"admission config": {modify: func(c *Config) {
+			c.APIServerOptions.AdmissionConfig = []byte(`{"kind":"AdmissionConfiguration"}`)
+		}},
+		"encryption": {modify: func(c *Config) {
+			c.APIServerOptions.EncryptionProvider = apiserver.EncryptionAESCBC
+			c.APIServerOptions.EncryptionResources = []string{"customresourcedefinitions.apiextensions.k8s.io"}
+			c.APIServerOptions.EncryptionKey = make([]byte, apiserver.EncryptionKeySize)
+		}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cfg := base
+			tc.modify(&cfg)
+			crdHash := "crdhash"
+			if tc.crdHash != "" {
+				crdHash = tc.crdHash
+			}
+			m, err := newManifest(cfg, crdHash, files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Key == baseManifest.Key {
+				t.Errorf("changing the %s kept key %q", name, m.Key)
+			}
+		})
+	}
+}
+
+func TestBinaryIdentity(t *testing.T) {
+	t.Parallel()
+
+	got, err := binaryIdentity("v1.34.1", "/does/not/exist")
+	if err != nil || got != "v1.34.1" {
+		t.Errorf("binaryIdentity() with version = %q, %v; want the version", got, err)
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "kine", "binary contents")
+	sum := sha256.Sum256([]byte("binary contents"))
+	want := "sha256:" + hex.EncodeToString(sum[:])
+	got, err = binaryIdentity("", filepath.Join(dir, "kine"))
+	if err != nil || got != want {
+		t.Errorf("binaryIdentity() without version = %q, %v; want %q", got, err, want)
+	}
+
+	if _, err := binaryIdentity("", filepath.Join(dir, "missing")); err == nil {
+		t.Error("binaryIdentity() of a missing binary succeeded, want error")
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	t.Parallel()
+
+	cachePath := filepath.Join(t.TempDir(), "cached-0123456789abcdef.db")
+	path := manifestPath(cachePath)
+	if filepath.Base(path) != "cached-0123456789abcdef.json" {
+		t.Fatalf("manifestPath() = %q", path)
+	}
+
+	m := &Manifest{Key: "0123456789abcdef", CRDHash: "crdhash", KubeAPIServer: "v1.34.1"}
+	if err := writeManifest(path, m); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Manifest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Key != m.Key || got.KubeAPIServer != m.KubeAPIServer || got.CreatedAt.IsZero() {
+		t.Errorf("manifest = %+v, want %+v with CreatedAt set", got, m)
+	}
+}
+
+// TestEnsureCache_ReusesMatchingKey checks that EnsureCache finds a database
+// stored under the manifest key. The existing-cache path returns before any
+// process is started.
+func TestEnsureCache_ReusesMatchingKey(t *testing.T) {
+	t.Parallel()
+
+	crdDir := t.TempDir()
+	writeTestFile(t, crdDir, "crd.yaml", "kind: CustomResourceDefinition")
+	cfg := Config{
+		CRDDir:               crdDir,
+		CacheDir:             t.TempDir(),
+		KineBinary:           "kine",
+		KubeAPIServerBinary:  "kube-apiserver",
+		APIServerOptions:     apiserver.Options{Version: version.MajorMinor(1, 34)},
+		KubeAPIServerVersion: "v1.34.1",
+		KineVersion:          "v0.13.5",
+		Timeout:              time.Minute,
+		PortRegistry:         netutil.NewPortRegistry(),
+	}
+
+	crdHash, files, err := computeDirHash(crdDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := newManifest(cfg, crdHash, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, cfg.CacheDir, "cached-"+m.Key+".db", "db")
+
+	result, err := EnsureCache(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("EnsureCache() error: %v", err)
+	}
+	if result.Created || result.Hash != m.Key || result.Manifest.KubeAPIServer != "v1.34.1" {
+		t.Errorf("EnsureCache() = %+v, want existing cache %q", result, m.Key)
+	}
+}
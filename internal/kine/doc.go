@@ -1,6 +1,8 @@
 // Package kine provides process management for the kine etcd-compatible SQLite shim.
 //
 // It handles the full kine lifecycle: construction, startup with optional SQLite
-// database prepopulation from a cached template, TCP-based readiness polling on
-// the listen port, and graceful shutdown via SIGTERM with a configurable timeout.
+// database prepopulation from a cached template, readiness polling on the listen
+// address, and graceful shutdown via SIGTERM with a configurable timeout. kine
+// listens on a unix socket in the data directory where SocketPath allows it,
+// and on a loopback TCP port otherwise.
 package kine
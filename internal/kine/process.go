@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"os"
 	"os/exec"
 	"time"
 
 	"github.com/giantswarm/k8senv/internal/fileutil"
+	"github.com/giantswarm/k8senv/internal/netns"
 	"github.com/giantswarm/k8senv/internal/process"
 )
 
@@ -23,12 +25,12 @@ import (
 // operate on the same SQLite file and must agree on the timeout.
 const sqliteBusyTimeoutMs = 5000
 
-// readinessPollInterval is the interval between consecutive TCP connection
+// readinessPollInterval is the interval between consecutive connection
 // attempts when waiting for the kine process to become ready.
 const readinessPollInterval = 10 * time.Millisecond
 
-// readinessDialTimeout is the per-attempt timeout for the TCP dial used in
-// kine readiness checks. 1 second is generous for a localhost connection;
+// readinessDialTimeout is the per-attempt timeout for the dial used in kine
+// readiness checks. 1 second is generous for a localhost connection;
 // early attempts that fail because kine is not yet listening return
 // immediately with a connection-refused error, so this timeout only guards
 // against pathological cases (e.g., SYN sent but no SYN-ACK).
@@ -42,13 +44,25 @@ type Config struct {
 	Binary       string // Path to kine binary (default: "kine")
 	DataDir      string // Working directory for logs
 	SQLitePath   string // Path to SQLite database
-	Port         int    // Listen port
+	Port         int    // Loopback listen port, when SocketPath is empty
+	SocketPath   string // Unix socket to listen on instead of Port (see SocketPath)
 	CachedDBPath string // Optional: source DB to prepopulate from
 
+	// NetworkNamespace runs kine in its own network namespace (see
+	// netns.Command). kine is then reachable only through SocketPath,
+	// which must be set.
+	NetworkNamespace bool
+
 	// StopTimeout is the timeout used by Close when auto-stopping a process
 	// that was not explicitly stopped. Zero uses process.DefaultStopTimeout.
 	StopTimeout time.Duration
 
+	// LogStream optionally re-emits the process output through Logger.
+	LogStream process.LogStream
+
+	// LogRotation optionally caps the size of the log files.
+	LogRotation process.LogRotation
+
 	// Logger (optional, defaults to slog.Default())
 	Logger *slog.Logger
 }
@@ -75,9 +89,15 @@ func (c Config) validate() error {
 	if c.SQLitePath == "" {
 		errs = append(errs, errors.New("sqlite path must not be empty"))
 	}
-	if c.Port <= 0 || c.Port > 65535 {
+	switch {
+	case c.SocketPath != "" && c.Port != 0:
+		errs = append(errs, errors.New("port and socket path are mutually exclusive"))
+	case c.SocketPath == "" && (c.Port <= 0 || c.Port > 65535):
 		errs = append(errs, errors.New("port must be between 1 and 65535"))
 	}
+	if c.NetworkNamespace && c.SocketPath == "" {
+		errs = append(errs, errors.New("network namespace requires a socket path"))
+	}
 
 	return errors.Join(errs...)
 }
@@ -90,10 +110,10 @@ func New(cfg Config) (*Process, error) {
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("invalid kine config: %w", err)
 	}
-	return &Process{
-		config: cfg,
-		base:   process.NewBaseProcess("kine", cfg.Logger, cfg.StopTimeout),
-	}, nil
+	base := process.NewBaseProcess("kine", cfg.Logger, cfg.StopTimeout)
+	base.SetLogStream(cfg.LogStream)
+	base.SetLogRotation(cfg.LogRotation)
+	return &Process{config: cfg, base: base}, nil
 }
 
 // Start launches the kine process. If CachedDBPath is set in the
@@ -111,25 +131,59 @@ func (p *Process) Start(ctx context.Context) error {
 		}
 	}
 
+	// A socket left behind by a killed kine in a reused data directory
+	// would make the listen fail with "address already in use".
+	if p.config.SocketPath != "" {
+		if err := os.Remove(p.config.SocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove stale kine socket: %w", err)
+		}
+	}
+
 	args := []string{
 		fmt.Sprintf("--endpoint=sqlite://%s?_busy_timeout=%d", p.config.SQLitePath, sqliteBusyTimeoutMs),
-		fmt.Sprintf("--listen-address=127.0.0.1:%d", p.config.Port),
+		"--listen-address=" + p.listenAddress(),
 		"--metrics-bind-address=0", // Disable metrics server to avoid port conflicts
 	}
 
-	cmd := exec.CommandContext( //nolint:gosec // G204: binary path is from config, not user input
-		ctx,
-		p.config.Binary,
-		args...)
+	cmd, err := p.command(ctx, args)
+	if err != nil {
+		return fmt.Errorf("start kine: %w", err)
+	}
 	if err := p.base.SetupAndStart(cmd, p.config.DataDir); err != nil {
 		return fmt.Errorf("setup and start kine process: %w", err)
 	}
 	return nil
 }
 
-// WaitReady polls the kine TCP port until it's accepting connections.
+// command returns the command running the kine binary with args, directly
+// or in a network namespace of its own.
+func (p *Process) command(ctx context.Context, args []string) (*exec.Cmd, error) {
+	if p.config.NetworkNamespace {
+		cmd, err := netns.Command(ctx, "", 0, p.config.Binary, args...)
+		if err != nil {
+			return nil, fmt.Errorf("create network namespace command: %w", err)
+		}
+		return cmd, nil
+	}
+	return exec.CommandContext(ctx, p.config.Binary, args...), nil //nolint:gosec // G204: binary path is from config, not user input
+}
+
+// listenAddress returns kine's --listen-address: unix://<SocketPath>, or the
+// loopback TCP address of Port.
+func (p *Process) listenAddress() string {
+	if p.config.SocketPath != "" {
+		return "unix://" + p.config.SocketPath
+	}
+	return fmt.Sprintf("127.0.0.1:%d", p.config.Port)
+}
+
+// WaitReady polls the kine socket or TCP port until it's accepting
+// connections.
 func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
-	addr := fmt.Sprintf("127.0.0.1:%d", p.config.Port)
+	network, addr := "tcp", fmt.Sprintf("127.0.0.1:%d", p.config.Port)
+	if p.config.SocketPath != "" {
+		network, addr = "unix", p.config.SocketPath
+	}
 
 	log := p.base.Logger()
 	dialer := &net.Dialer{Timeout: readinessDialTimeout}
@@ -141,10 +195,10 @@ func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
 		Logger:        log,
 		ProcessExited: p.base.Exited(),
 	}, func(checkCtx context.Context, attempt int) (bool, error) {
-		conn, err := dialer.DialContext(checkCtx, "tcp", addr)
+		conn, err := dialer.DialContext(checkCtx, network, addr)
 		if err != nil {
 			if log.Enabled(checkCtx, slog.LevelDebug) {
-				log.Debug("waitForKine attempt", "port", p.config.Port, "attempt", attempt, "error", err)
+				log.Debug("waitForKine attempt", "address", addr, "attempt", attempt, "error", err)
 			}
 			return false, nil // Not ready yet
 		}
@@ -156,8 +210,12 @@ func (p *Process) WaitReady(ctx context.Context, timeout time.Duration) error {
 	return nil
 }
 
-// Endpoint returns the kine endpoint URL for kube-apiserver to connect to.
+// Endpoint returns the kine endpoint URL for kube-apiserver to connect to:
+// unix://<SocketPath>, or http://127.0.0.1:<Port>.
 func (p *Process) Endpoint() string {
+	if p.config.SocketPath != "" {
+		return "unix://" + p.config.SocketPath
+	}
 	return fmt.Sprintf("http://127.0.0.1:%d", p.config.Port)
 }
 
@@ -171,6 +229,17 @@ func (p *Process) Close() {
 	p.base.Close()
 }
 
+// Name returns the process name, "kine", which also prefixes its log files.
+func (p *Process) Name() string {
+	return p.base.Name()
+}
+
+// ExitCode returns the exit code of a kine process that exited on its own,
+// or -1 while it runs. See [process.BaseProcess.ExitCode].
+func (p *Process) ExitCode() int {
+	return p.base.ExitCode()
+}
+
 // prepopulateDB copies a SQLite database file to the destination path.
 // This is used to prepopulate kine's SQLite database with existing state.
 func prepopulateDB(srcPath, dstPath string) error {
@@ -0,0 +1,30 @@
+package kine
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// SocketFile is the name of the unix socket kine listens on, relative to the
+// instance data directory.
+const SocketFile = "kine.sock"
+
+// maxSocketPathLen is the longest unix socket path accepted on every
+// platform k8senv runs on: sun_path holds 108 bytes on Linux and 104 on
+// macOS, including the terminating NUL.
+const maxSocketPathLen = 103
+
+// SocketPath returns the absolute path of the kine socket in dataDir, or ""
+// if kine cannot listen on a unix socket there: on Windows, or when the
+// path exceeds the sun_path limit, as under a deep $TMPDIR on macOS. Callers
+// fall back to a TCP port when it returns "".
+func SocketPath(dataDir string) string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	path, err := filepath.Abs(filepath.Join(dataDir, SocketFile))
+	if err != nil || len(path) > maxSocketPathLen {
+		return ""
+	}
+	return path
+}
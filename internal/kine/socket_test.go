@@ -0,0 +1,97 @@
+package kine
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSocketPath(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		if got := SocketPath(t.TempDir()); got != "" {
+			t.Errorf("SocketPath on windows = %q, want empty", got)
+		}
+		return
+	}
+
+	dir := t.TempDir()
+	if got, want := SocketPath(dir), filepath.Join(dir, SocketFile); got != want {
+		t.Errorf("SocketPath(%q) = %q, want %q", dir, got, want)
+	}
+
+	long := filepath.Join(dir, strings.Repeat("d", maxSocketPathLen))
+	if got := SocketPath(long); got != "" {
+		t.Errorf("SocketPath of a %d-byte directory = %q, want empty", len(long), got)
+	}
+
+	rel := SocketPath("data")
+	if !filepath.IsAbs(rel) && rel != "" {
+		t.Errorf("SocketPath(\"data\") = %q, want an absolute path", rel)
+	}
+}
+
+func TestListenAddressAndEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg          Config
+		wantListen   string
+		wantEndpoint string
+	}{
+		"tcp": {
+			cfg:          Config{Port: 2379},
+			wantListen:   "127.0.0.1:2379",
+			wantEndpoint: "http://127.0.0.1:2379",
+		},
+		"unix socket": {
+			cfg:          Config{SocketPath: "/tmp/inst/kine.sock"},
+			wantListen:   "unix:///tmp/inst/kine.sock",
+			wantEndpoint: "unix:///tmp/inst/kine.sock",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			p := &Process{config: tt.cfg}
+			if got := p.listenAddress(); got != tt.wantListen {
+				t.Errorf("listenAddress() = %q, want %q", got, tt.wantListen)
+			}
+			if got := p.Endpoint(); got != tt.wantEndpoint {
+				t.Errorf("Endpoint() = %q, want %q", got, tt.wantEndpoint)
+			}
+		})
+	}
+}
+
+func TestConfigValidateListener(t *testing.T) {
+	t.Parallel()
+
+	base := Config{Binary: "kine", DataDir: "/d", SQLitePath: "/d/db"}
+	tests := map[string]struct {
+		port    int
+		socket  string
+		netns   bool
+		wantErr bool
+	}{
+		"port":                     {port: 2379},
+		"socket":                   {socket: "/d/kine.sock"},
+		"neither":                  {wantErr: true},
+		"both":                     {port: 2379, socket: "/d/kine.sock", wantErr: true},
+		"port out of range":        {port: 70000, wantErr: true},
+		"namespace with socket":    {socket: "/d/kine.sock", netns: true},
+		"namespace without socket": {port: 2379, netns: true, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cfg := base
+			cfg.Port, cfg.SocketPath, cfg.NetworkNamespace = tt.port, tt.socket, tt.netns
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,5 +2,10 @@
 // Stack manages the coordinated lifecycle of both processes, starting them in
 // parallel via errgroup and shutting them down in reverse order (apiserver first,
 // then kine). It delegates port allocation to netutil.PortRegistry to guarantee
-// distinct ports across concurrent stacks.
+// distinct ports across concurrent stacks. kine listens on a unix socket in the
+// stack's data directory where possible, so only kube-apiserver needs a port.
+// With NetworkNamespace, both run in Linux network namespaces of their own and
+// kube-apiserver is reached through a forwarder instead. When configured,
+// kube-controller-manager is started once kube-apiserver is ready and stopped
+// before it.
 package kubestack
@@ -2,22 +2,27 @@ package kubestack
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"time"
 
 	"github.com/giantswarm/k8senv/internal/apiserver"
+	"github.com/giantswarm/k8senv/internal/controllermanager"
 	"github.com/giantswarm/k8senv/internal/fileutil"
 	"github.com/giantswarm/k8senv/internal/kine"
+	"github.com/giantswarm/k8senv/internal/netns"
 	"github.com/giantswarm/k8senv/internal/netutil"
 	"github.com/giantswarm/k8senv/internal/process"
 	"golang.org/x/sync/errgroup"
 )
 
-// Config holds configuration for a kine + kube-apiserver pair.
+// Config holds configuration for a kine + kube-apiserver pair, optionally
+// joined by kube-controller-manager.
 type Config struct {
 	// Required
 	DataDir        string // Working directory for logs/config
@@ -31,6 +36,17 @@ type Config struct {
 	// Optional (for prepopulating kine DB)
 	CachedDBPath string
 
+	// Optional kube-apiserver features (admission plugins, ...). The zero
+	// value is the default configuration.
+	APIServerOptions apiserver.Options
+
+	// Optional kube-controller-manager, started once kube-apiserver is ready.
+	// An empty ControllerManagerBinary runs only kine and kube-apiserver.
+	// Controllers selects the controllers to run; empty uses
+	// controllermanager.DefaultControllers.
+	ControllerManagerBinary string
+	Controllers             []string
+
 	// Timeouts (required, must be positive)
 	KineReadyTimeout      time.Duration
 	APIServerReadyTimeout time.Duration
@@ -40,6 +56,27 @@ type Config struct {
 	// defaults to process.DefaultStopTimeout.
 	StopTimeout time.Duration
 
+	// LogStream optionally re-emits the output of every process through
+	// Logger. The zero value only writes the log files.
+	LogStream process.LogStream
+
+	// LogRotation optionally caps the size of every process's log files.
+	// The zero value lets them grow without bound.
+	LogRotation process.LogRotation
+
+	// KineTCP makes kine listen on a loopback TCP port instead of a unix
+	// socket in DataDir. Without it, the socket is used wherever
+	// kine.SocketPath allows, saving one port per stack, and TCP otherwise.
+	KineTCP bool
+
+	// NetworkNamespace runs kine and kube-apiserver each in a Linux user and
+	// network namespace of its own (see netns.Command). kine listens on its
+	// unix socket, and kube-apiserver binds apiserver.NamespacePort inside
+	// its namespace, reached through a netns.Forwarder on a loopback port
+	// of the test process. Requires Linux, no KineTCP, and a DataDir short
+	// enough for both sockets.
+	NetworkNamespace bool
+
 	// PortRegistry coordinates port allocation across concurrent stacks.
 	// Required: callers must provide a shared PortRegistry to prevent
 	// duplicate port allocation. Typically created once per Manager and
@@ -61,12 +98,21 @@ type Stack struct {
 	log    *slog.Logger
 
 	// Set by Start, cleared by Stop: process handles, allocated ports, and
-	// lifecycle flag.
-	kine      *kine.Process
-	apiserver *apiserver.Process
-	kinePort  int // allocated port for kine, released on Stop
-	apiPort   int // allocated port for kube-apiserver, released on Stop
-	started   bool
+	// lifecycle flag. controllerManager and kcmPort stay zero unless
+	// Config.ControllerManagerBinary is set.
+	kine              *kine.Process
+	apiserver         *apiserver.Process
+	controllerManager *controllermanager.Process
+	kinePort          int              // allocated port for kine, released on Stop; 0 with kineSocket
+	kineSocket        string           // unix socket kine listens on instead of kinePort
+	apiPort           int              // allocated port for kube-apiserver, released on Stop; the forwarder's with NetworkNamespace
+	forwarder         *netns.Forwarder // relays apiPort into kube-apiserver's namespace; nil without NetworkNamespace
+	kcmPort           int              // allocated port for kube-controller-manager, released on Stop
+	started           bool
+
+	// attempts is the number of launches StartWithRetry made to start this
+	// stack, including the failed ones before it. Set once before return.
+	attempts int
 }
 
 // stopTimeout returns the configured StopTimeout, falling back to
@@ -109,10 +155,33 @@ func (c Config) validate() error {
 	if c.APIServerReadyTimeout <= 0 {
 		errs = append(errs, errors.New("api server ready timeout must be positive"))
 	}
+	if len(c.Controllers) > 0 && c.ControllerManagerBinary == "" {
+		errs = append(errs, errors.New("controllers require a controller manager binary"))
+	}
+	if c.NetworkNamespace {
+		errs = append(errs, c.validateNetworkNamespace()...)
+	}
 
 	return errors.Join(errs...)
 }
 
+// validateNetworkNamespace checks the requirements of NetworkNamespace.
+// The socket paths are checked here rather than on Start because a data
+// directory that is too long would fail every retry the same way.
+func (c Config) validateNetworkNamespace() []error {
+	var errs []error
+	if !netns.Supported {
+		errs = append(errs, netns.ErrUnsupported)
+	}
+	if c.KineTCP {
+		errs = append(errs, errors.New("network namespace requires kine on a unix socket, not TCP"))
+	}
+	if c.DataDir != "" && (kine.SocketPath(c.DataDir) == "" || netns.SocketPath(c.DataDir, APIServerSocketFile) == "") {
+		errs = append(errs, fmt.Errorf("network namespace requires unix sockets in data dir %s, which is too long", c.DataDir))
+	}
+	return errs
+}
+
 // resolveBinaries verifies that configured binary paths exist on $PATH via
 // exec.LookPath. Call after validate to confirm that the binaries are
 // actually available. It uses errors.Join to report all missing binaries at
@@ -126,6 +195,11 @@ func (c Config) resolveBinaries() error {
 	if _, err := exec.LookPath(c.APIServerBinary); err != nil {
 		errs = append(errs, fmt.Errorf("api server binary not found: %w", err))
 	}
+	if c.ControllerManagerBinary != "" {
+		if _, err := exec.LookPath(c.ControllerManagerBinary); err != nil {
+			errs = append(errs, fmt.Errorf("controller manager binary not found: %w", err))
+		}
+	}
 
 	return errors.Join(errs...)
 }
@@ -187,7 +261,7 @@ func StartWithRetry(
 			lastErr = err
 			stack.logFailedAttempt(err, attempt, maxRetries)
 			if isPermanentStartError(err) {
-				return nil, fmt.Errorf("permanent start failure (not retried): %w", err)
+				return nil, &AttemptError{Attempts: attempt, Permanent: true, Err: err}
 			}
 			continue
 		}
@@ -195,10 +269,41 @@ func StartWithRetry(
 		if attempt > 1 {
 			stack.log.Info("kubestack start succeeded after retry", "attempt", attempt)
 		}
+		stack.attempts = attempt
 		return stack, nil
 	}
 
-	return nil, fmt.Errorf("start kubestack after %d attempts: %w", maxRetries, lastErr)
+	return nil, &AttemptError{Attempts: maxRetries, Err: lastErr}
+}
+
+// Attempts returns the number of times StartWithRetry launched the processes
+// to start s, including failed launches before the one that succeeded.
+func (s *Stack) Attempts() int {
+	return s.attempts
+}
+
+// AttemptError is returned by StartWithRetry when it gives up, either after
+// a permanent failure or once every attempt has failed.
+type AttemptError struct {
+	// Attempts is the number of start attempts made.
+	Attempts int
+	// Permanent reports that the last failure was not worth retrying.
+	Permanent bool
+	// Err is the failure of the last attempt.
+	Err error
+}
+
+// Error describes the last failure and why StartWithRetry gave up.
+func (e *AttemptError) Error() string {
+	if e.Permanent {
+		return fmt.Sprintf("permanent start failure (not retried): %v", e.Err)
+	}
+	return fmt.Sprintf("start kubestack after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the failure of the last attempt.
+func (e *AttemptError) Unwrap() error {
+	return e.Err
 }
 
 // validateContextPair checks that processCtx and readyCtx are both non-nil,
@@ -286,8 +391,9 @@ func isPermanentStartError(err error) bool {
 }
 
 // Start launches both kine and kube-apiserver concurrently and waits for
-// both to become ready. Since ports are allocated upfront, apiserver's
-// --etcd-servers flag is configured before either process starts. The
+// both to become ready. Since ports and the kine socket path are allocated
+// upfront, apiserver's --etcd-servers flag is configured before either
+// process starts. The
 // apiserver tolerates kine not being ready yet via its built-in etcd
 // connection retry logic.
 //
@@ -358,87 +464,227 @@ func (s *Stack) Start(processCtx, readyCtx context.Context) (retErr error) {
 		return err
 	}
 
+	// The kubeconfig embeds the CA and client credentials that the apiserver
+	// generates on Start, so it can only be written once Start has run.
+	s.log.Debug("generating kubeconfig")
+	if err := s.apiserver.WriteKubeconfig(); err != nil {
+		return fmt.Errorf("write kubeconfig: %w", err)
+	}
+
+	if s.config.ControllerManagerBinary != "" {
+		if err := s.startControllerManager(processCtx, readyCtx); err != nil {
+			return err
+		}
+	}
+
 	s.started = true
 	s.log.Debug("kubestack started", "elapsed", time.Since(startTime))
 	return nil
 }
 
-// allocatePorts reserves a pair of ports from the shared port registry
-// and stores them on the stack for use by kine and kube-apiserver.
+// allocatePorts reserves the ports of the stack from the shared port
+// registry: one for kube-apiserver, one for kine unless it listens on a
+// unix socket, and one for kube-controller-manager when it is configured.
+// With NetworkNamespace, kube-apiserver's port is the forwarder's instead,
+// which its listener holds for the lifetime of the stack.
 func (s *Stack) allocatePorts() error {
-	kinePort, apiPort, err := s.config.PortRegistry.AllocatePortPair()
+	if !s.config.KineTCP {
+		s.kineSocket = kine.SocketPath(s.config.DataDir)
+	}
+	switch {
+	case s.config.NetworkNamespace:
+		fwd, err := netns.Listen(netns.SocketPath(s.config.DataDir, APIServerSocketFile))
+		if err != nil {
+			return fmt.Errorf("allocate ports: %w", err)
+		}
+		s.forwarder = fwd
+		s.apiPort = fwd.Port()
+	case s.kineSocket != "":
+		apiPort, err := s.config.PortRegistry.AllocatePort()
+		if err != nil {
+			return fmt.Errorf("allocate ports: %w", err)
+		}
+		s.apiPort = apiPort
+	default:
+		kinePort, apiPort, err := s.config.PortRegistry.AllocatePortPair()
+		if err != nil {
+			return fmt.Errorf("allocate ports: %w", err)
+		}
+		s.kinePort = kinePort
+		s.apiPort = apiPort
+	}
+	if s.config.ControllerManagerBinary != "" {
+		kcmPort, err := s.config.PortRegistry.AllocatePort()
+		if err != nil {
+			s.releasePorts()
+			return fmt.Errorf("allocate controller manager port: %w", err)
+		}
+		s.kcmPort = kcmPort
+	}
+	s.log.Debug("ports allocated", "kine_port", s.kinePort, "kine_socket", s.kineSocket, "api_port", s.apiPort, "kcm_port", s.kcmPort)
+	if err := s.writePorts(); err != nil {
+		s.releasePorts()
+		return err
+	}
+	return nil
+}
+
+// APIServerSocketFile is the name of the unix socket, relative to
+// Config.DataDir, through which the forwarder reaches kube-apiserver in its
+// network namespace.
+const APIServerSocketFile = "apiserver.sock"
+
+// PortsFile is the name of the file, relative to Config.DataDir, in which
+// Start records the ports allocated to each process.
+const PortsFile = "ports.json"
+
+// Ports lists the ports allocated to one stack. Kine is zero when kine
+// listens on KineSocket instead, and ControllerManager is zero when no
+// kube-controller-manager is configured. With a network namespace,
+// APIServer is the forwarder's port and APIServerSocket the socket it
+// relays to.
+type Ports struct {
+	Kine              int    `json:"kine,omitempty"`
+	KineSocket        string `json:"kineSocket,omitempty"`
+	APIServer         int    `json:"apiserver"`
+	APIServerSocket   string `json:"apiserverSocket,omitempty"`
+	ControllerManager int    `json:"controllerManager,omitempty"`
+}
+
+// writePorts records the allocated ports in PortsFile so that they survive
+// a failed start for diagnostics.
+func (s *Stack) writePorts() error {
+	data, err := json.Marshal(Ports{
+		Kine:              s.kinePort,
+		KineSocket:        s.kineSocket,
+		APIServer:         s.apiPort,
+		APIServerSocket:   s.forwarderSocket(),
+		ControllerManager: s.kcmPort,
+	})
 	if err != nil {
-		return fmt.Errorf("allocate ports: %w", err)
+		return fmt.Errorf("marshal ports: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.config.DataDir, PortsFile), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write ports: %w", err)
 	}
-	s.kinePort = kinePort
-	s.apiPort = apiPort
-	s.log.Debug("ports allocated", "kine_port", kinePort, "api_port", apiPort)
 	return nil
 }
 
-// createProcesses builds the kine and apiserver process objects and
-// generates the kubeconfig file. No OS processes are started; this only
-// prepares the configuration so that startAndWaitForReady can launch both
-// concurrently.
+// forwarderSocket returns the unix socket the forwarder relays to, or ""
+// without a network namespace.
+func (s *Stack) forwarderSocket() string {
+	if s.forwarder == nil {
+		return ""
+	}
+	return s.forwarder.Socket()
+}
+
+// createProcesses builds the kine and apiserver process objects. No OS
+// processes are started; this only prepares the configuration so that
+// startAndWaitForReady can launch both concurrently.
 func (s *Stack) createProcesses() error {
 	kineProc, err := kine.New(kine.Config{
-		Binary:       s.config.KineBinary,
-		DataDir:      s.config.DataDir,
-		SQLitePath:   s.config.SQLitePath,
-		Port:         s.kinePort,
-		CachedDBPath: s.config.CachedDBPath,
-		StopTimeout:  s.config.stopTimeout(),
-		Logger:       s.log,
+		Binary:           s.config.KineBinary,
+		DataDir:          s.config.DataDir,
+		SQLitePath:       s.config.SQLitePath,
+		Port:             s.kinePort,
+		SocketPath:       s.kineSocket,
+		CachedDBPath:     s.config.CachedDBPath,
+		NetworkNamespace: s.config.NetworkNamespace,
+		StopTimeout:      s.config.stopTimeout(),
+		LogStream:        s.config.LogStream,
+		LogRotation:      s.config.LogRotation,
+		Logger:           s.log,
 	})
 	if err != nil {
 		return fmt.Errorf("create kine process: %w", err)
 	}
 	s.kine = kineProc
 
-	// Endpoint() only needs the port number, not a running kine process,
-	// so this is safe to call before kine starts.
-	apiserverProc, err := apiserver.New(apiserver.Config{
+	// Endpoint() only needs the port number or socket path, not a running
+	// kine process, so this is safe to call before kine starts.
+	apiCfg := apiserver.Config{
 		Binary:         s.config.APIServerBinary,
 		DataDir:        s.config.DataDir,
 		Port:           s.apiPort,
 		EtcdEndpoint:   s.kine.Endpoint(),
 		KubeconfigPath: s.config.KubeconfigPath,
+		Options:        s.config.APIServerOptions,
 		StopTimeout:    s.config.stopTimeout(),
+		LogStream:      s.config.LogStream,
+		LogRotation:    s.config.LogRotation,
 		Logger:         s.log,
-	})
+	}
+	if s.forwarder != nil {
+		apiCfg.Port = apiserver.NamespacePort
+		apiCfg.NamespaceSocket = s.forwarder.Socket()
+		apiCfg.ClientPort = s.apiPort
+	}
+	apiserverProc, err := apiserver.New(apiCfg)
 	if err != nil {
 		return fmt.Errorf("create apiserver process: %w", err)
 	}
 	s.apiserver = apiserverProc
-
-	s.log.Debug("generating kubeconfig")
-	if err := s.apiserver.WriteKubeconfig(); err != nil {
-		return fmt.Errorf("write kubeconfig: %w", err)
-	}
 	return nil
 }
 
-// startable is satisfied by both kine.Process and apiserver.Process,
-// allowing startAndWait to handle both uniformly.
+// startable is satisfied by kine.Process, apiserver.Process and
+// controllermanager.Process, allowing startAndWait to handle them uniformly.
 type startable interface {
 	Start(ctx context.Context) error
 	WaitReady(ctx context.Context, timeout time.Duration) error
+	Name() string
+	ExitCode() int
 }
 
-// startAndWait starts a process and waits for it to become ready.
+// ProcessError reports the process of a stack that failed to start or to
+// become ready. It is captured before the failed stack is cleaned up, so
+// ExitCode reflects how the process ended on its own.
+type ProcessError struct {
+	// Process is the process name, which also prefixes its log files (see
+	// process.StderrFile).
+	Process string
+	// Port is the port allocated to the process, or 0 for a kine listening
+	// on a unix socket.
+	Port int
+	// ExitCode is the exit code if the process exited before becoming
+	// ready, and -1 otherwise. See process.BaseProcess.ExitCode.
+	ExitCode int
+	// Err is the start or readiness failure.
+	Err error
+}
+
+// Error returns the underlying failure.
+func (e *ProcessError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying failure.
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+// startAndWait starts a process and waits for it to become ready. Failures
+// are returned as a *ProcessError.
 func (s *Stack) startAndWait(
 	processCtx, readyCtx context.Context,
 	proc startable,
+	port int,
 	timeout time.Duration,
 	name string,
 ) error {
 	s.log.Debug("starting process", "name", name)
 	if err := proc.Start(processCtx); err != nil {
-		return fmt.Errorf("start %s: %w", name, err)
+		return &ProcessError{Process: proc.Name(), Port: port, ExitCode: -1, Err: fmt.Errorf("start %s: %w", name, err)}
 	}
 	s.log.Debug("waiting for process readiness", "name", name, "timeout", timeout)
 	if err := proc.WaitReady(readyCtx, timeout); err != nil {
-		return fmt.Errorf("%s readiness: %w", name, err)
+		return &ProcessError{
+			Process:  proc.Name(),
+			Port:     port,
+			ExitCode: proc.ExitCode(),
+			Err:      fmt.Errorf("%s readiness: %w", name, err),
+		}
 	}
 	s.log.Debug("process ready", "name", name)
 	return nil
@@ -463,10 +709,10 @@ func (s *Stack) startAndWaitForReady(processCtx, readyCtx context.Context) error
 	// s.apiserver), so concurrent calls to Start are safe despite
 	// BaseProcess not being goroutine-safe.
 	g.Go(func() error {
-		return s.startAndWait(processCtx, gCtx, s.kine, s.config.KineReadyTimeout, "kine")
+		return s.startAndWait(processCtx, gCtx, s.kine, s.kinePort, s.config.KineReadyTimeout, "kine")
 	})
 	g.Go(func() error {
-		return s.startAndWait(processCtx, gCtx, s.apiserver, s.config.APIServerReadyTimeout, "apiserver")
+		return s.startAndWait(processCtx, gCtx, s.apiserver, s.apiPort, s.config.APIServerReadyTimeout, "apiserver")
 	})
 
 	if err := g.Wait(); err != nil {
@@ -475,6 +721,32 @@ func (s *Stack) startAndWaitForReady(processCtx, readyCtx context.Context) error
 	return nil
 }
 
+// startControllerManager launches kube-controller-manager against the ready
+// kube-apiserver and waits for its /healthz endpoint. It runs after
+// startAndWaitForReady because it needs the kubeconfig, CA and service
+// account key that kube-apiserver's Start writes. The readiness wait uses
+// APIServerReadyTimeout.
+func (s *Stack) startControllerManager(processCtx, readyCtx context.Context) error {
+	kcm, err := controllermanager.New(controllermanager.Config{
+		Binary:                s.config.ControllerManagerBinary,
+		DataDir:               s.config.DataDir,
+		Port:                  s.kcmPort,
+		KubeconfigPath:        s.config.KubeconfigPath,
+		CAFile:                s.apiserver.CAFile(),
+		ServiceAccountKeyFile: s.apiserver.ServiceAccountKeyFile(),
+		Controllers:           s.config.Controllers,
+		StopTimeout:           s.config.stopTimeout(),
+		LogStream:             s.config.LogStream,
+		LogRotation:           s.config.LogRotation,
+		Logger:                s.log,
+	})
+	if err != nil {
+		return fmt.Errorf("create controller manager process: %w", err)
+	}
+	s.controllerManager = kcm
+	return s.startAndWait(processCtx, readyCtx, kcm, s.kcmPort, s.config.APIServerReadyTimeout, "kube-controller-manager")
+}
+
 // cleanupAfterStartFailure releases all resources acquired during a failed
 // Start call. Resources are cleaned in reverse creation order (apiserver,
 // kine, ports). StopCloseAndNil handles nil pointers gracefully, so this
@@ -502,6 +774,9 @@ func (s *Stack) cleanupAfterStartFailure() {
 // Stop is not safe for concurrent use. Callers must ensure that Stop (and
 // Start) are not called concurrently on the same Stack. In practice, each Stack
 // is owned by a single Instance whose startMu serializes lifecycle calls.
+//
+// kube-controller-manager, when configured, is stopped before both so that
+// it does not log errors against a stopping apiserver.
 func (s *Stack) Stop(timeout time.Duration) error {
 	if timeout <= 0 {
 		return fmt.Errorf("stop timeout must be positive, got %s", timeout)
@@ -513,11 +788,14 @@ func (s *Stack) Stop(timeout time.Duration) error {
 	return s.stopProcesses(timeout)
 }
 
-// stopProcesses stops apiserver then kine (reverse start order) and releases
-// ports. The timeout is applied per-process. StopCloseAndNil handles nil
-// pointers gracefully.
+// stopProcesses stops kube-controller-manager, apiserver, then kine (reverse
+// start order) and releases ports. The timeout is applied per-process.
+// StopCloseAndNil handles nil pointers gracefully.
 func (s *Stack) stopProcesses(timeout time.Duration) error {
 	var errs []error
+	if err := process.StopCloseAndNil(&s.controllerManager, timeout); err != nil {
+		errs = append(errs, fmt.Errorf("stop controller manager: %w", err))
+	}
 	if err := process.StopCloseAndNil(&s.apiserver, timeout); err != nil {
 		errs = append(errs, fmt.Errorf("stop apiserver: %w", err))
 	}
@@ -528,6 +806,12 @@ func (s *Stack) stopProcesses(timeout time.Duration) error {
 	return errors.Join(errs...)
 }
 
+// APIServerPort returns the port allocated to kube-apiserver, or 0 once the
+// stack has been stopped.
+func (s *Stack) APIServerPort() int {
+	return s.apiPort
+}
+
 // IsStarted reports whether the stack has been started and not yet stopped.
 func (s *Stack) IsStarted() bool {
 	return s.started
@@ -540,8 +824,21 @@ func (s *Stack) releasePorts() {
 		s.config.PortRegistry.Release(s.kinePort)
 		s.kinePort = 0
 	}
+	s.kineSocket = ""
+	if s.forwarder != nil {
+		// The forwarder's port never came from the registry.
+		if err := s.forwarder.Close(); err != nil {
+			s.log.Debug("close forwarder", "error", err)
+		}
+		s.forwarder = nil
+		s.apiPort = 0
+	}
 	if s.apiPort != 0 {
 		s.config.PortRegistry.Release(s.apiPort)
 		s.apiPort = 0
 	}
+	if s.kcmPort != 0 {
+		s.config.PortRegistry.Release(s.kcmPort)
+		s.kcmPort = 0
+	}
 }
@@ -0,0 +1,343 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// gcWorkers is the number of goroutines processing garbage collection items.
+// Test workloads are small; two workers keep a slow API call on one object
+// from delaying all others.
+const gcWorkers = 2
+
+// requeueDelay is how long an item waits before being re-checked when it is
+// blocked on other objects going away (remaining namespace content, blocking
+// dependents). Deletions also requeue affected items directly, so this is
+// only a fallback and can stay short.
+const requeueDelay = 250 * time.Millisecond
+
+// ownerIndex is the informer index mapping an owner UID to its dependents.
+const ownerIndex = "owner"
+
+// namespacesResource is the core namespaces resource, watched to drive the
+// namespace lifecycle controller.
+var namespacesResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// objectRef identifies an object to process. It is comparable so it can be
+// used as a workqueue item.
+type objectRef struct {
+	resource  schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// watchedResource is a resource with a running metadata informer.
+type watchedResource struct {
+	resource   schema.GroupVersionResource
+	namespaced bool
+	informer   cache.SharedIndexInformer
+}
+
+// Controller runs the namespace lifecycle and garbage collection loops
+// against one kube-apiserver. Create it with Start and stop it with Stop.
+type Controller struct {
+	log       *slog.Logger
+	meta      metadata.Interface
+	client    kubernetes.Interface
+	mapper    meta.RESTMapper
+	resources []watchedResource
+
+	gcQueue workqueue.TypedRateLimitingInterface[objectRef]
+	nsQueue workqueue.TypedRateLimitingInterface[string]
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Resources is the result of API discovery against one kube-apiserver.
+// Discovery is the slowest part of starting a Controller, so callers that
+// start one Controller per lease against the same server should call
+// Discover once and reuse the result. It is immutable and safe for
+// concurrent use.
+type Resources struct {
+	groups []*restmapper.APIGroupResources
+}
+
+// Discover returns the resources served by the kube-apiserver at cfg.
+func Discover(cfg *rest.Config) (*Resources, error) {
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create clientset: %w", err)
+	}
+	groups, err := restmapper.GetAPIGroupResources(client.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("discover API resources: %w", err)
+	}
+	return &Resources{groups: groups}, nil
+}
+
+// Start starts a metadata informer for each resource in res that can be
+// listed, watched and deleted, and launches the workers. It returns once
+// everything is running, without waiting for the informer caches to sync;
+// objects already Terminating are picked up from the initial list. If res is
+// nil, the served resources are discovered first. If logger is nil,
+// slog.Default() is used.
+func Start(cfg *rest.Config, res *Resources, logger *slog.Logger) (*Controller, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create clientset: %w", err)
+	}
+	metaClient, err := metadata.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create metadata client: %w", err)
+	}
+	if res == nil {
+		if res, err = Discover(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Controller{
+		log:     logger,
+		meta:    metaClient,
+		client:  client,
+		mapper:  restmapper.NewDiscoveryRESTMapper(res.groups),
+		gcQueue: workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[objectRef]()),
+		nsQueue: workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]()),
+		cancel:  cancel,
+	}
+
+	for _, r := range watchableResources(res.groups) {
+		c.watch(r)
+	}
+	for _, res := range c.resources {
+		c.wg.Go(func() { res.informer.RunWithContext(ctx) })
+	}
+	for range gcWorkers {
+		c.wg.Go(func() { runWorker(ctx, c.log, c.gcQueue, c.syncObject) })
+	}
+	c.wg.Go(func() { runWorker(ctx, c.log, c.nsQueue, c.syncNamespace) })
+
+	c.log.Debug("lifecycle controller started", "resources", len(c.resources))
+	return c, nil
+}
+
+// Stop stops the informers and workers and waits for them to exit. An API
+// call in flight is canceled, so objects may be left half-processed; the
+// next Controller resumes from their current state.
+func (c *Controller) Stop() {
+	c.cancel()
+	c.gcQueue.ShutDown()
+	c.nsQueue.ShutDown()
+	c.wg.Wait()
+}
+
+// watchableResources returns the preferred version of every top-level
+// resource that supports list, watch and delete.
+func watchableResources(groups []*restmapper.APIGroupResources) []metav1.APIResource {
+	var out []metav1.APIResource
+	for _, g := range groups {
+		version := g.Group.PreferredVersion.Version
+		for _, r := range g.VersionedResources[version] {
+			if isSubresource(r.Name) || !hasVerbs(r.Verbs, "list", "watch", "delete") {
+				continue
+			}
+			r.Group = g.Group.Name
+			r.Version = version
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// isSubresource reports whether a discovery resource name denotes a
+// subresource, such as "pods/status".
+func isSubresource(name string) bool {
+	return strings.Contains(name, "/")
+}
+
+// hasVerbs reports whether verbs contains all of want.
+func hasVerbs(verbs metav1.Verbs, want ...string) bool {
+	for _, w := range want {
+		if !slices.Contains(verbs, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// watch creates the metadata informer for res and registers event handlers.
+func (c *Controller) watch(res metav1.APIResource) {
+	gvr := schema.GroupVersionResource{Group: res.Group, Version: res.Version, Resource: res.Name}
+	informer := metadatainformer.NewFilteredMetadataInformer(
+		c.meta, gvr, metav1.NamespaceAll, 0,
+		cache.Indexers{ownerIndex: indexByOwner}, nil,
+	).Informer()
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { c.onChange(gvr, obj) },
+		UpdateFunc: func(_, obj any) { c.onChange(gvr, obj) },
+		DeleteFunc: func(obj any) { c.onDelete(obj) },
+	})
+	if err != nil {
+		// Only fails once the informer has been stopped, which cannot
+		// happen before Start runs it.
+		c.log.Warn("lifecycle: add event handler", "resource", gvr.String(), "error", err)
+		return
+	}
+	c.resources = append(c.resources, watchedResource{resource: gvr, namespaced: res.Namespaced, informer: informer})
+}
+
+// indexByOwner indexes objects by the UIDs of their owners.
+func indexByOwner(obj any) ([]string, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, fmt.Errorf("index by owner: %w", err)
+	}
+	refs := m.GetOwnerReferences()
+	uids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		uids = append(uids, string(ref.UID))
+	}
+	return uids, nil
+}
+
+// onChange queues objects that need attention: Terminating namespaces,
+// objects with owners (whose owners may be gone) and deleting objects
+// waiting on the garbage collector's finalizers.
+func (c *Controller) onChange(gvr schema.GroupVersionResource, obj any) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	deleting := m.GetDeletionTimestamp() != nil
+	if gvr == namespacesResource && deleting {
+		c.nsQueue.Add(m.GetName())
+	}
+	if len(m.GetOwnerReferences()) > 0 || (deleting && hasGCFinalizer(m.GetFinalizers())) {
+		c.gcQueue.Add(objectRef{resource: gvr, namespace: m.GetNamespace(), name: m.GetName()})
+	}
+}
+
+// onDelete queues the dependents of a deleted object, whose owner may now be
+// gone, and its owners, which may be waiting for it in foreground deletion.
+func (c *Controller) onDelete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	for _, dep := range c.dependents(string(m.GetUID())) {
+		c.gcQueue.Add(dep.ref)
+	}
+	for _, ref := range m.GetOwnerReferences() {
+		if owner, ok := c.ownerRef(ref, m.GetNamespace()); ok {
+			c.gcQueue.Add(owner)
+		}
+	}
+}
+
+// dependent is an object whose ownerReferences include a given owner.
+type dependent struct {
+	ref      objectRef
+	uid      string
+	deleting bool
+	// blocking reports whether the reference to the owner sets
+	// blockOwnerDeletion.
+	blocking bool
+}
+
+// dependents returns the cached objects owned by ownerUID.
+func (c *Controller) dependents(ownerUID string) []dependent {
+	var out []dependent
+	for _, res := range c.resources {
+		objs, err := res.informer.GetIndexer().ByIndex(ownerIndex, ownerUID)
+		if err != nil {
+			continue
+		}
+		for _, obj := range objs {
+			m, err := meta.Accessor(obj)
+			if err != nil {
+				continue
+			}
+			out = append(out, dependent{
+				ref:      objectRef{resource: res.resource, namespace: m.GetNamespace(), name: m.GetName()},
+				uid:      string(m.GetUID()),
+				deleting: m.GetDeletionTimestamp() != nil,
+				blocking: blocksOwner(m.GetOwnerReferences(), ownerUID),
+			})
+		}
+	}
+	return out
+}
+
+// ownerRef resolves an owner reference of an object in namespace ns to an
+// objectRef. It returns false if the owner's kind is not served.
+func (c *Controller) ownerRef(ref metav1.OwnerReference, ns string) (objectRef, bool) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return objectRef{}, false
+	}
+	mapping, err := c.mapper.RESTMapping(gv.WithKind(ref.Kind).GroupKind(), gv.Version)
+	if err != nil {
+		return objectRef{}, false
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		ns = ""
+	}
+	return objectRef{resource: mapping.Resource, namespace: ns, name: ref.Name}, true
+}
+
+// runWorker processes items from queue until it is shut down. sync returns
+// (requeue, err): an error retries the item with rate-limited backoff, and
+// requeue re-checks it after requeueDelay.
+func runWorker[T comparable](
+	ctx context.Context,
+	log *slog.Logger,
+	queue workqueue.TypedRateLimitingInterface[T],
+	sync func(context.Context, T) (bool, error),
+) {
+	for {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		requeue, err := sync(ctx, item)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				log.Debug("lifecycle: sync failed, retrying", "item", item, "error", err)
+			}
+			queue.AddRateLimited(item)
+		case requeue:
+			queue.Forget(item)
+			queue.AddAfter(item, requeueDelay)
+		default:
+			queue.Forget(item)
+		}
+		queue.Done(item)
+	}
+}
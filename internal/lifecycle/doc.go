@@ -0,0 +1,13 @@
+// Package lifecycle provides a lightweight in-process replacement for the two
+// kube-controller-manager controllers most tests depend on: the namespace
+// lifecycle controller, which empties and finalizes Terminating namespaces,
+// and the ownerReference garbage collector with background, foreground and
+// orphan propagation.
+//
+// A Controller watches object metadata of every listable resource found by
+// Discover, using client-go metadata informers, and acts through the regular
+// API so that admission, finalizers and audit behave as they would in a
+// cluster. It is far cheaper than a kube-controller-manager process but makes
+// no attempt at completeness: resources added after discovery (e.g. CRDs
+// created by a test) are not watched.
+package lifecycle
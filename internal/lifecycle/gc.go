@@ -0,0 +1,244 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// hasGCFinalizer reports whether finalizers include one of the finalizers
+// the garbage collector is responsible for.
+func hasGCFinalizer(finalizers []string) bool {
+	return slices.Contains(finalizers, metav1.FinalizerDeleteDependents) ||
+		slices.Contains(finalizers, metav1.FinalizerOrphanDependents)
+}
+
+// blocksOwner reports whether the reference to ownerUID among refs sets
+// blockOwnerDeletion.
+func blocksOwner(refs []metav1.OwnerReference, ownerUID string) bool {
+	for _, ref := range refs {
+		if string(ref.UID) == ownerUID {
+			return ref.BlockOwnerDeletion != nil && *ref.BlockOwnerDeletion
+		}
+	}
+	return false
+}
+
+// ignoreGone treats NotFound and Conflict as success: the object the call
+// was meant for is gone or has changed, and any change re-queues it.
+func ignoreGone(err error) error {
+	if err == nil || isGone(err) {
+		return nil
+	}
+	return err
+}
+
+// isGone reports whether err means the target object no longer exists in the
+// expected form: NotFound, or Conflict from a UID or resourceVersion
+// precondition.
+func isGone(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsConflict(err)
+}
+
+// syncObject reconciles one object on behalf of the garbage collector:
+//
+//   - an object being deleted with the orphan finalizer has its owner
+//     reference removed from all dependents, then the finalizer removed;
+//   - an object being deleted with the foregroundDeletion finalizer has its
+//     dependents deleted, and the finalizer is removed once no dependent
+//     with blockOwnerDeletion remains;
+//   - any other object with owners is deleted in the background once all of
+//     its owners are gone, or has the references to missing owners removed
+//     while others remain.
+func (c *Controller) syncObject(ctx context.Context, ref objectRef) (bool, error) {
+	client := c.meta.Resource(ref.resource).Namespace(ref.namespace)
+	obj, err := client.Get(ctx, ref.name, metav1.GetOptions{})
+	if err != nil {
+		return false, ignoreGone(err)
+	}
+
+	if obj.DeletionTimestamp != nil {
+		switch {
+		case slices.Contains(obj.Finalizers, metav1.FinalizerOrphanDependents):
+			return false, c.orphanDependents(ctx, ref, obj)
+		case slices.Contains(obj.Finalizers, metav1.FinalizerDeleteDependents):
+			return c.deleteDependents(ctx, ref, obj)
+		default:
+			return false, nil
+		}
+	}
+
+	if len(obj.OwnerReferences) == 0 {
+		return false, nil
+	}
+	var missing []types.UID
+	for _, owner := range obj.OwnerReferences {
+		exists, err := c.ownerExists(ctx, owner, ref.namespace)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			missing = append(missing, owner.UID)
+		}
+	}
+
+	switch {
+	case len(missing) == 0:
+		return false, nil
+	case len(missing) == len(obj.OwnerReferences):
+		c.log.Debug("lifecycle: deleting object whose owners are gone",
+			"resource", ref.resource.String(), "namespace", ref.namespace, "name", ref.name)
+		return false, c.deleteObject(ctx, ref, obj.UID, metav1.DeletePropagationBackground)
+	default:
+		return false, c.patchOwnerReferences(ctx, ref, obj, withoutOwners(obj.OwnerReferences, missing))
+	}
+}
+
+// ownerExists reports whether the object referenced by owner exists with the
+// referenced UID. Owners of an unserved kind are treated as existing, so an
+// API discovery gap never causes a deletion.
+func (c *Controller) ownerExists(ctx context.Context, owner metav1.OwnerReference, ns string) (bool, error) {
+	ref, ok := c.ownerRef(owner, ns)
+	if !ok {
+		return true, nil
+	}
+	obj, err := c.meta.Resource(ref.resource).Namespace(ref.namespace).Get(ctx, ref.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get owner %s %s: %w", owner.Kind, owner.Name, err)
+	}
+	return obj.UID == owner.UID, nil
+}
+
+// deleteDependents implements foreground deletion for owner: every dependent
+// not yet being deleted is deleted (in the foreground itself when it blocks
+// the owner, so its own blocking dependents go first), and the
+// foregroundDeletion finalizer is removed once no blocking dependent is left.
+func (c *Controller) deleteDependents(ctx context.Context, ref objectRef, owner *metav1.PartialObjectMetadata) (bool, error) {
+	blocked := false
+	for _, dep := range c.dependents(string(owner.UID)) {
+		if dep.blocking {
+			blocked = true
+		}
+		if dep.deleting {
+			continue
+		}
+		policy := metav1.DeletePropagationBackground
+		if dep.blocking {
+			policy = metav1.DeletePropagationForeground
+		}
+		if err := c.deleteObject(ctx, dep.ref, types.UID(dep.uid), policy); err != nil {
+			return false, err
+		}
+	}
+	if blocked {
+		// Dependent deletions re-queue the owner; requeue as a fallback in
+		// case an event is missed.
+		return true, nil
+	}
+	return false, c.removeFinalizer(ctx, ref, owner, metav1.FinalizerDeleteDependents)
+}
+
+// orphanDependents implements orphan deletion for owner: the owner reference
+// is removed from every dependent, then the orphan finalizer from the owner.
+func (c *Controller) orphanDependents(ctx context.Context, ref objectRef, owner *metav1.PartialObjectMetadata) error {
+	for _, dep := range c.dependents(string(owner.UID)) {
+		client := c.meta.Resource(dep.ref.resource).Namespace(dep.ref.namespace)
+		obj, err := client.Get(ctx, dep.ref.name, metav1.GetOptions{})
+		if err != nil {
+			if ignoreGone(err) == nil {
+				continue
+			}
+			return fmt.Errorf("get dependent %s: %w", dep.ref.name, err)
+		}
+		refs := withoutOwners(obj.OwnerReferences, []types.UID{owner.UID})
+		if len(refs) == len(obj.OwnerReferences) {
+			continue
+		}
+		if err := c.patchOwnerReferences(ctx, dep.ref, obj, refs); err != nil {
+			return err
+		}
+	}
+	return c.removeFinalizer(ctx, ref, owner, metav1.FinalizerOrphanDependents)
+}
+
+// deleteObject deletes the object with the given UID. A UID precondition
+// guards against deleting a newer object that reuses the name. The grace
+// period is zero because no kubelet runs to confirm that a Pod bound to a
+// node has terminated; it would otherwise stay Terminating forever.
+func (c *Controller) deleteObject(ctx context.Context, ref objectRef, uid types.UID, policy metav1.DeletionPropagation) error {
+	var gracePeriod int64
+	err := c.meta.Resource(ref.resource).Namespace(ref.namespace).Delete(ctx, ref.name, metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+		Preconditions:      &metav1.Preconditions{UID: &uid},
+		PropagationPolicy:  &policy,
+	})
+	if err := ignoreGone(err); err != nil {
+		return fmt.Errorf("delete %s %s/%s: %w", ref.resource.Resource, ref.namespace, ref.name, err)
+	}
+	return nil
+}
+
+// removeFinalizer removes finalizer from obj.
+func (c *Controller) removeFinalizer(
+	ctx context.Context, ref objectRef, obj *metav1.PartialObjectMetadata, finalizer string,
+) error {
+	remaining := slices.DeleteFunc(slices.Clone(obj.Finalizers), func(f string) bool { return f == finalizer })
+	return c.patchMetadata(ctx, ref, obj, "finalizers", remaining)
+}
+
+// patchOwnerReferences replaces the ownerReferences of obj with refs.
+func (c *Controller) patchOwnerReferences(
+	ctx context.Context, ref objectRef, obj *metav1.PartialObjectMetadata, refs []metav1.OwnerReference,
+) error {
+	return c.patchMetadata(ctx, ref, obj, "ownerReferences", refs)
+}
+
+// patchMetadata sets one metadata field of obj with a merge patch.
+func (c *Controller) patchMetadata(ctx context.Context, ref objectRef, obj *metav1.PartialObjectMetadata, field string, value any) error {
+	patch, err := metadataPatch(obj.ResourceVersion, field, value)
+	if err != nil {
+		return err
+	}
+	_, err = c.meta.Resource(ref.resource).Namespace(ref.namespace).
+		Patch(ctx, ref.name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err := ignoreGone(err); err != nil {
+		return fmt.Errorf("patch %s of %s %s/%s: %w", field, ref.resource.Resource, ref.namespace, ref.name, err)
+	}
+	return nil
+}
+
+// metadataPatch builds a merge patch setting metadata.<field> to value. The
+// resourceVersion makes the patch fail with Conflict if the object changed
+// since it was read, since a merge patch replaces lists wholesale.
+func metadataPatch(resourceVersion, field string, value any) ([]byte, error) {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"resourceVersion": resourceVersion,
+			field:             value,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s patch: %w", field, err)
+	}
+	return patch, nil
+}
+
+// withoutOwners returns refs without the references to the given owner UIDs.
+// The result is never nil, so it marshals as an empty list.
+func withoutOwners(refs []metav1.OwnerReference, uids []types.UID) []metav1.OwnerReference {
+	out := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if !slices.Contains(uids, ref.UID) {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
@@ -0,0 +1,202 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/restmapper"
+)
+
+var configMapsResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// newConfigMap returns ConfigMap metadata as stored by the fake client.
+func newConfigMap(name, uid string, owners ...metav1.OwnerReference) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "test",
+			UID:             types.UID(uid),
+			OwnerReferences: owners,
+		},
+	}
+}
+
+// newTestController returns a Controller over a fake metadata client that
+// maps the ConfigMap kind, without informers.
+func newTestController(objs ...*metav1.PartialObjectMetadata) (*Controller, *metadatafake.FakeMetadataClient) {
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		panic(err)
+	}
+	runtimeObjs := make([]runtime.Object, 0, len(objs))
+	for _, o := range objs {
+		runtimeObjs = append(runtimeObjs, o)
+	}
+	client := metadatafake.NewSimpleMetadataClient(scheme, runtimeObjs...)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	return &Controller{log: slog.Default(), meta: client, mapper: mapper}, client
+}
+
+func TestSyncObjectDeletesOrphanedDependent(t *testing.T) {
+	t.Parallel()
+
+	owner := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "owner-uid"}
+	c, client := newTestController(newConfigMap("child", "child-uid", owner))
+
+	requeue, err := c.syncObject(t.Context(), objectRef{resource: configMapsResource, namespace: "test", name: "child"})
+	if err != nil || requeue {
+		t.Fatalf("syncObject() = (%v, %v), want (false, nil)", requeue, err)
+	}
+	if _, err := client.Resource(configMapsResource).Namespace("test").Get(t.Context(), "child", metav1.GetOptions{}); err == nil {
+		t.Error("child still exists, want it garbage collected")
+	}
+}
+
+func TestSyncObjectKeepsOwnedDependent(t *testing.T) {
+	t.Parallel()
+
+	owner := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "owner-uid"}
+	c, client := newTestController(newConfigMap("owner", "owner-uid"), newConfigMap("child", "child-uid", owner))
+
+	if _, err := c.syncObject(t.Context(), objectRef{resource: configMapsResource, namespace: "test", name: "child"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Resource(configMapsResource).Namespace("test").Get(t.Context(), "child", metav1.GetOptions{}); err != nil {
+		t.Errorf("child was deleted although its owner exists: %v", err)
+	}
+}
+
+func TestSyncObjectRemovesForegroundFinalizerWithoutDependents(t *testing.T) {
+	t.Parallel()
+
+	now := metav1.Now()
+	owner := newConfigMap("owner", "owner-uid")
+	owner.DeletionTimestamp = &now
+	owner.Finalizers = []string{metav1.FinalizerDeleteDependents, "example.com/keep"}
+	c, client := newTestController(owner)
+
+	requeue, err := c.syncObject(t.Context(), objectRef{resource: configMapsResource, namespace: "test", name: "owner"})
+	if err != nil || requeue {
+		t.Fatalf("syncObject() = (%v, %v), want (false, nil)", requeue, err)
+	}
+	got, err := client.Resource(configMapsResource).Namespace("test").Get(t.Context(), "owner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Finalizers, []string{"example.com/keep"}) {
+		t.Errorf("finalizers = %v, want [example.com/keep]", got.Finalizers)
+	}
+}
+
+func TestWatchableResources(t *testing.T) {
+	t.Parallel()
+
+	groups := []*restmapper.APIGroupResources{{
+		Group: metav1.APIGroup{
+			Name:             "apps",
+			PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+		},
+		VersionedResources: map[string][]metav1.APIResource{
+			"v1": {
+				{Name: "deployments", Namespaced: true, Verbs: metav1.Verbs{"list", "watch", "delete", "get"}},
+				{Name: "deployments/status", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch", "delete"}},
+				{Name: "controllerrevisions", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+			},
+			"v1beta1": {
+				{Name: "deployments", Namespaced: true, Verbs: metav1.Verbs{"list", "watch", "delete"}},
+			},
+		},
+	}}
+
+	got := watchableResources(groups)
+	if len(got) != 1 || got[0].Name != "deployments" || got[0].Group != "apps" || got[0].Version != "v1" {
+		t.Errorf("watchableResources() = %+v, want only apps/v1 deployments", got)
+	}
+}
+
+func TestIndexByOwner(t *testing.T) {
+	t.Parallel()
+
+	obj := newConfigMap("child", "child-uid",
+		metav1.OwnerReference{UID: "a"},
+		metav1.OwnerReference{UID: "b"},
+	)
+	got, err := indexByOwner(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("indexByOwner() = %v, want [a b]", got)
+	}
+}
+
+func TestBlocksOwner(t *testing.T) {
+	t.Parallel()
+
+	yes := true
+	refs := []metav1.OwnerReference{{UID: "a", BlockOwnerDeletion: &yes}, {UID: "b"}}
+	if !blocksOwner(refs, "a") {
+		t.Error("blocksOwner(a) = false, want true")
+	}
+	if blocksOwner(refs, "b") || blocksOwner(refs, "c") {
+		t.Error("blocksOwner(b or c) = true, want false")
+	}
+}
+
+func TestWithoutOwners(t *testing.T) {
+	t.Parallel()
+
+	refs := []metav1.OwnerReference{{UID: "a"}, {UID: "b"}}
+	if got := withoutOwners(refs, []types.UID{"a"}); !reflect.DeepEqual(got, refs[1:]) {
+		t.Errorf("withoutOwners(a) = %v, want %v", got, refs[1:])
+	}
+	got := withoutOwners(refs, []types.UID{"a", "b"})
+	if got == nil || len(got) != 0 {
+		t.Errorf("withoutOwners(all) = %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestMetadataPatch(t *testing.T) {
+	t.Parallel()
+
+	patch, err := metadataPatch("42", "finalizers", []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]map[string]any
+	if err := json.Unmarshal(patch, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["metadata"]["resourceVersion"] != "42" {
+		t.Errorf("resourceVersion = %v, want 42", got["metadata"]["resourceVersion"])
+	}
+	if list, ok := got["metadata"]["finalizers"].([]any); !ok || len(list) != 0 {
+		t.Errorf("finalizers = %#v, want empty list", got["metadata"]["finalizers"])
+	}
+}
+
+func TestHasGCFinalizer(t *testing.T) {
+	t.Parallel()
+
+	for finalizers, want := range map[string]bool{
+		metav1.FinalizerDeleteDependents: true,
+		metav1.FinalizerOrphanDependents: true,
+		"example.com/other":              false,
+	} {
+		if got := hasGCFinalizer([]string{finalizers}); got != want {
+			t.Errorf("hasGCFinalizer(%q) = %v, want %v", finalizers, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncNamespace emulates the namespace lifecycle controller for a
+// Terminating namespace: it deletes every object in the namespace and, once
+// none are left, removes the "kubernetes" finalizer so kube-apiserver can
+// delete the namespace itself. Objects held by finalizers keep the namespace
+// Terminating until they are gone, as in a cluster.
+func (c *Controller) syncNamespace(ctx context.Context, name string) (bool, error) {
+	ns, err := c.client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, ignoreGone(err)
+	}
+	if ns.DeletionTimestamp == nil || !slices.Contains(ns.Spec.Finalizers, corev1.FinalizerKubernetes) {
+		return false, nil
+	}
+
+	remaining := 0
+	for _, res := range c.resources {
+		if !res.namespaced {
+			continue
+		}
+		n, err := c.deleteNamespaceContent(ctx, res, name)
+		if err != nil {
+			return false, err
+		}
+		remaining += n
+	}
+	if remaining > 0 {
+		return true, nil
+	}
+
+	ns.Spec.Finalizers = slices.DeleteFunc(ns.Spec.Finalizers, func(f corev1.FinalizerName) bool {
+		return f == corev1.FinalizerKubernetes
+	})
+	_, err = c.client.CoreV1().Namespaces().Finalize(ctx, ns, metav1.UpdateOptions{})
+	if err := ignoreGone(err); err != nil {
+		return false, fmt.Errorf("finalize namespace %s: %w", name, err)
+	}
+	c.log.Debug("lifecycle: namespace finalized", "namespace", name)
+	return false, nil
+}
+
+// deleteNamespaceContent deletes the objects of res in namespace ns and
+// returns how many still exist. The list is read from the API rather than
+// the informer cache, since finalizing a namespace that still has content
+// would orphan it.
+func (c *Controller) deleteNamespaceContent(ctx context.Context, res watchedResource, ns string) (int, error) {
+	client := c.meta.Resource(res.resource).Namespace(ns)
+	list, err := client.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("list %s in namespace %s: %w", res.resource.Resource, ns, err)
+	}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if obj.DeletionTimestamp != nil {
+			continue
+		}
+		ref := objectRef{resource: res.resource, namespace: ns, name: obj.Name}
+		if err := c.deleteObject(ctx, ref, obj.UID, metav1.DeletePropagationBackground); err != nil {
+			return 0, err
+		}
+	}
+	return len(list.Items), nil
+}
@@ -0,0 +1,9 @@
+// Package logstream re-emits the output of kine and kube-apiserver through a
+// slog.Logger as it arrives.
+//
+// A Writer splits its input into lines and parses each one as a klog header
+// (kube-apiserver, kube-controller-manager) or as logrus text output (kine).
+// The level and message are mapped onto a slog record, with the process
+// name, the klog source location and logrus fields as attributes. Lines in
+// neither format are emitted at Info level as they are.
+package logstream
@@ -0,0 +1,179 @@
+package logstream
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// record is a parsed log line.
+type record struct {
+	level slog.Level
+	msg   string
+	attrs []slog.Attr
+}
+
+// parse parses line as klog or logrus output, falling back to an Info
+// record holding the line unchanged.
+func parse(line string) record {
+	if r, ok := parseKlog(line); ok {
+		return r
+	}
+	if r, ok := parseLogrus(line); ok {
+		return r
+	}
+	return record{level: slog.LevelInfo, msg: line}
+}
+
+// parseKlog parses a line with a klog header:
+//
+//	Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg...
+//
+// where L is one of I, W, E or F. The source location is returned as the
+// "caller" attribute.
+func parseKlog(line string) (record, bool) {
+	var level slog.Level
+	switch {
+	case line == "":
+		return record{}, false
+	case line[0] == 'I':
+		level = slog.LevelInfo
+	case line[0] == 'W':
+		level = slog.LevelWarn
+	case line[0] == 'E', line[0] == 'F':
+		level = slog.LevelError
+	default:
+		return record{}, false
+	}
+
+	header, msg, ok := strings.Cut(line, "] ")
+	if !ok {
+		header, ok = strings.CutSuffix(line, "]")
+		if !ok {
+			return record{}, false
+		}
+	}
+	fields := strings.Fields(header)
+	// Lmmdd, hh:mm:ss.uuuuuu, threadid, file:line
+	if len(fields) != 4 || len(fields[0]) != 5 || !isDigits(fields[0][1:]) ||
+		len(fields[1]) < 8 || fields[1][2] != ':' || !isDigits(fields[2]) ||
+		!strings.Contains(fields[3], ":") {
+		return record{}, false
+	}
+
+	r := record{level: level, msg: msg, attrs: []slog.Attr{slog.String("caller", fields[3])}}
+	if line[0] == 'F' {
+		r.attrs = append(r.attrs, slog.Bool("fatal", true))
+	}
+	return r, true
+}
+
+// parseLogrus parses a line of logrus text output:
+//
+//	time="..." level=info msg="..." key=value...
+//
+// Fields other than time, level and msg are returned as string attributes.
+func parseLogrus(line string) (record, bool) {
+	pairs, ok := parseLogfmt(line)
+	if !ok {
+		return record{}, false
+	}
+	var (
+		r        record
+		hasLevel bool
+	)
+	for _, kv := range pairs {
+		switch kv[0] {
+		case "time":
+		case "level":
+			r.level, ok = logrusLevel(kv[1])
+			if !ok {
+				return record{}, false
+			}
+			hasLevel = true
+		case "msg":
+			r.msg = kv[1]
+		default:
+			r.attrs = append(r.attrs, slog.String(kv[0], kv[1]))
+		}
+	}
+	return r, hasLevel
+}
+
+// logrusLevel maps a logrus level name to a slog level.
+func logrusLevel(name string) (slog.Level, bool) {
+	switch name {
+	case "trace", "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warning", "warn":
+		return slog.LevelWarn, true
+	case "error", "fatal", "panic":
+		return slog.LevelError, true
+	default:
+		return 0, false
+	}
+}
+
+// parseLogfmt splits line into key=value pairs. Values may be double-quoted
+// with backslash escapes. It reports false if line is not logfmt.
+func parseLogfmt(line string) ([][2]string, bool) {
+	var pairs [][2]string
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			return nil, false
+		}
+		var value string
+		if strings.HasPrefix(after, `"`) {
+			value, rest, ok = cutQuoted(after[1:])
+			if !ok {
+				return nil, false
+			}
+		} else {
+			value, rest, _ = strings.Cut(after, " ")
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+	return pairs, len(pairs) > 0
+}
+
+// cutQuoted returns the unescaped content of a double-quoted string whose
+// opening quote has been consumed, and the input after the closing quote.
+func cutQuoted(s string) (value, rest string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], true
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[i])
+				}
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package logstream
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		line string
+		want record
+	}{
+		"klog info": {
+			line: `I0102 15:04:05.123456   12345 server.go:123] "Serving securely" address="127.0.0.1:6443"`,
+			want: record{
+				level: slog.LevelInfo,
+				msg:   `"Serving securely" address="127.0.0.1:6443"`,
+				attrs: []slog.Attr{slog.String("caller", "server.go:123")},
+			},
+		},
+		"klog warning": {
+			line: "W1231 23:59:59.000001       1 authentication.go:40] anonymous requests",
+			want: record{
+				level: slog.LevelWarn,
+				msg:   "anonymous requests",
+				attrs: []slog.Attr{slog.String("caller", "authentication.go:40")},
+			},
+		},
+		"klog fatal": {
+			line: "F0102 15:04:05.123456 7 run.go:74] error: bind: address already in use",
+			want: record{
+				level: slog.LevelError,
+				msg:   "error: bind: address already in use",
+				attrs: []slog.Attr{slog.String("caller", "run.go:74"), slog.Bool("fatal", true)},
+			},
+		},
+		"logrus": {
+			line: `time="2026-01-02T15:04:05Z" level=warning msg="slow \"query\"" duration=1.5s`,
+			want: record{
+				level: slog.LevelWarn,
+				msg:   `slow "query"`,
+				attrs: []slog.Attr{slog.String("duration", "1.5s")},
+			},
+		},
+		"logrus debug": {
+			line: `time="2026-01-02T15:04:05Z" level=debug msg=ready`,
+			want: record{level: slog.LevelDebug, msg: "ready"},
+		},
+		"plain": {
+			line: "Flag --insecure-port has been deprecated",
+			want: record{level: slog.LevelInfo, msg: "Flag --insecure-port has been deprecated"},
+		},
+		"I-prefixed plain": {
+			line: "Internal error] something",
+			want: record{level: slog.LevelInfo, msg: "Internal error] something"},
+		},
+		"logfmt without level": {
+			line: "a=b c=d",
+			want: record{level: slog.LevelInfo, msg: "a=b c=d"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := parse(tc.line); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parse(%q) =\n  %+v\nwant\n  %+v", tc.line, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package logstream
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// maxLineLength caps the bytes buffered for one line. Longer lines are
+// emitted in pieces.
+const maxLineLength = 64 << 10
+
+// Writer is an io.WriteCloser that parses the output of one process line by
+// line and emits each line at or above a minimum level through a logger.
+// It is safe for concurrent use. Give each output stream its own Writer:
+// a partial line buffered for one stream would otherwise be joined with the
+// next line of another.
+type Writer struct {
+	log      *slog.Logger
+	minLevel slog.Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewWriter returns a Writer emitting through logger, with a "process"
+// attribute set to process, the lines at or above minLevel. A nil logger
+// uses slog.Default().
+func NewWriter(logger *slog.Logger, process string, minLevel slog.Level) *Writer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Writer{log: logger.With("process", process), minLevel: minLevel}
+}
+
+// Write emits every complete line in p and buffers the remainder. It never
+// fails, so that logging cannot block or break the process writing to it.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxLineLength {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil // release the backing array between writes
+	}
+	return len(p), nil
+}
+
+// Close emits a final line that lacks a trailing newline.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// emit parses line and logs it if its level passes the filter.
+func (w *Writer) emit(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	r := parse(string(line))
+	if r.level < w.minLevel {
+		return
+	}
+	w.log.LogAttrs(context.Background(), r.level, r.msg, r.attrs...)
+}
@@ -0,0 +1,81 @@
+package logstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// decodeLines decodes the JSON records in buf.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, m)
+	}
+	return records
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	w := NewWriter(logger.With("id", "inst-0"), "kube-apiserver", slog.LevelWarn)
+
+	input := "I0102 15:04:05.123456 1 a.go:1] dropped\n" +
+		"W0102 15:04:05.123456 1 b.go:2] split "
+	if _, err := w.Write([]byte(input)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("across writes\nE0102 15:04:05.123456 1 c.go:3] no newline")); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(decodeLines(t, &buf)); got != 1 {
+		t.Fatalf("emitted %d records before Close, want 1", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := decodeLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("emitted %d records, want 2 (Info filtered): %v", len(records), records)
+	}
+	want := []map[string]any{
+		{"level": "WARN", "msg": "split across writes", "caller": "b.go:2"},
+		{"level": "ERROR", "msg": "no newline", "caller": "c.go:3"},
+	}
+	for i, r := range records {
+		for k, v := range want[i] {
+			if r[k] != v {
+				t.Errorf("record %d %s = %v, want %v", i, k, r[k], v)
+			}
+		}
+		if r["process"] != "kube-apiserver" || r["id"] != "inst-0" {
+			t.Errorf("record %d attributes = %v, want process and id", i, r)
+		}
+	}
+}
+
+func TestWriterLongLine(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(slog.New(slog.NewJSONHandler(&buf, nil)), "kine", slog.LevelInfo)
+	if _, err := w.Write(bytes.Repeat([]byte("x"), maxLineLength)); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(decodeLines(t, &buf)); got != 1 {
+		t.Errorf("emitted %d records for an overlong line, want 1", got)
+	}
+}
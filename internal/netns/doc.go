@@ -0,0 +1,13 @@
+// Package netns runs processes in their own Linux user and network namespace.
+//
+// Command re-executes the running binary as a helper inside a fresh
+// namespace. The helper brings up the namespace's loopback interface, runs
+// the target process there and, when asked to, bridges a TCP port inside the
+// namespace to a unix socket on the shared filesystem. A Forwarder exposes
+// that socket on a loopback port of the calling process. Any binary that
+// imports this package becomes the helper when HelperEnv is set, from an init
+// function that runs before main or TestMain.
+//
+// On platforms other than Linux, Supported is false and Command and Probe
+// fail with ErrUnsupported. Forwarder works everywhere.
+package netns
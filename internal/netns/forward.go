@@ -0,0 +1,156 @@
+package netns
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// dialTimeout bounds each connection a forwarder or the helper opens to the
+// other side of the bridge. Both ends are local, so a dial either succeeds
+// or fails at once; the timeout only guards against pathological cases.
+const dialTimeout = time.Second
+
+// Forwarder accepts connections on a loopback TCP port and relays each one
+// to a unix socket, typically the one the helper bridges into a namespace.
+// Connections arriving while nothing listens on the socket are closed at
+// once, so clients polling for readiness simply retry.
+type Forwarder struct {
+	listener net.Listener
+	socket   string
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Listen starts a Forwarder on an ephemeral port of 127.0.0.1 relaying to
+// socket. The listener holds the port until Close, so no other process can
+// be handed it in the meantime.
+func Listen(socket string) (*Forwarder, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen for forwarder: %w", err)
+	}
+	f := &Forwarder{listener: listener, socket: socket, conns: make(map[net.Conn]struct{})}
+	f.wg.Add(1)
+	go f.serve()
+	return f, nil
+}
+
+// Port returns the loopback port the forwarder accepts connections on.
+func (f *Forwarder) Port() int {
+	addr, ok := f.listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return 0
+	}
+	return addr.Port
+}
+
+// Socket returns the unix socket connections are relayed to.
+func (f *Forwarder) Socket() string {
+	return f.socket
+}
+
+// Close stops accepting connections, closes the relayed ones and waits for
+// their goroutines to finish.
+func (f *Forwarder) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	for c := range f.conns {
+		_ = c.Close()
+	}
+	f.mu.Unlock()
+	err := f.listener.Close()
+	f.wg.Wait()
+	if err != nil {
+		return fmt.Errorf("close forwarder: %w", err)
+	}
+	return nil
+}
+
+// serve accepts connections until the listener is closed.
+func (f *Forwarder) serve() {
+	defer f.wg.Done()
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		if !f.track(conn) {
+			_ = conn.Close()
+			return
+		}
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			defer f.untrack(conn)
+			upstream, err := net.DialTimeout("unix", f.socket, dialTimeout)
+			if err != nil {
+				_ = conn.Close()
+				return
+			}
+			if !f.track(upstream) {
+				_ = upstream.Close()
+				_ = conn.Close()
+				return
+			}
+			defer f.untrack(upstream)
+			relay(conn, upstream)
+		}()
+	}
+}
+
+// track registers conn for Close. It reports false once the forwarder is
+// closed, in which case the caller must close conn itself.
+func (f *Forwarder) track(conn net.Conn) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return false
+	}
+	f.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack removes conn from the set Close closes.
+func (f *Forwarder) untrack(conn net.Conn) {
+	f.mu.Lock()
+	delete(f.conns, conn)
+	f.mu.Unlock()
+}
+
+// closeWriter is implemented by *net.TCPConn and *net.UnixConn.
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// relay copies data between a and b in both directions until both are
+// done, then closes them. The end of one direction is passed on as a
+// half-close, so request/response protocols see EOF where they expect it.
+func relay(a, b net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pipe := func(dst, src net.Conn) {
+		defer wg.Done()
+		_, err := io.Copy(dst, src)
+		if cw, ok := dst.(closeWriter); ok && err == nil {
+			_ = cw.CloseWrite()
+			return
+		}
+		// A failed copy leaves nothing to wait for in the other direction.
+		if err != nil && !errors.Is(err, net.ErrClosed) {
+			_ = a.Close()
+			_ = b.Close()
+		}
+	}
+	go pipe(a, b)
+	go pipe(b, a)
+	wg.Wait()
+	_ = a.Close()
+	_ = b.Close()
+}
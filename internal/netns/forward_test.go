@@ -0,0 +1,132 @@
+package netns
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echo serves l, writing every line it receives back in upper case, until l
+// is closed.
+func echo(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			s := bufio.NewScanner(conn)
+			for s.Scan() {
+				if _, err := io.WriteString(conn, strings.ToUpper(s.Text())+"\n"); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// roundTrip sends line through a connection to 127.0.0.1:port and returns
+// the reply.
+func roundTrip(t *testing.T, port int, line string) (string, error) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, line+"\n"); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return strings.TrimSuffix(reply, "\n"), err
+}
+
+func TestForwarder(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "fwd.sock")
+	f, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	if f.Port() == 0 || f.Socket() != socket {
+		t.Fatalf("Port() = %d, Socket() = %q", f.Port(), f.Socket())
+	}
+
+	// Nothing listens on the socket yet: the connection is closed.
+	if reply, err := roundTrip(t, f.Port(), "early"); err == nil {
+		t.Fatalf("round trip without upstream = %q, want error", reply)
+	}
+
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go echo(l)
+
+	for _, line := range []string{"ping", "pong"} {
+		reply, err := roundTrip(t, f.Port(), line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.ToUpper(line); reply != want {
+			t.Errorf("reply = %q, want %q", reply, want)
+		}
+	}
+}
+
+func TestForwarderClose(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "fwd.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go echo(l)
+
+	f, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(f.Port())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Close must not wait for the open connection's client to hang up.
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("read from a connection of a closed forwarder succeeded")
+	}
+	if _, err := roundTrip(t, f.Port(), "late"); err == nil {
+		t.Error("round trip through a closed forwarder succeeded")
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if got, want := SocketPath(dir, "apiserver.sock"), filepath.Join(dir, "apiserver.sock"); got != want {
+		t.Errorf("SocketPath = %q, want %q", got, want)
+	}
+	long := filepath.Join(dir, strings.Repeat("d", maxSocketPathLen))
+	if got := SocketPath(long, "apiserver.sock"); got != "" {
+		t.Errorf("SocketPath of a %d-byte directory = %q, want empty", len(long), got)
+	}
+}
@@ -0,0 +1,30 @@
+package netns
+
+import (
+	"path/filepath"
+
+	"github.com/giantswarm/k8senv/internal/sentinel"
+)
+
+// HelperEnv is the environment variable that makes a binary importing this
+// package run as the namespace helper instead of its main function. Command
+// and Probe set it; it is not passed on to the target process.
+const HelperEnv = "K8SENV_NETNS_HELPER"
+
+// ErrUnsupported is returned by Command and Probe on platforms without
+// network namespaces.
+const ErrUnsupported = sentinel.Error("network namespaces are only supported on Linux")
+
+// maxSocketPathLen is the longest unix socket path Linux accepts: sun_path
+// holds 108 bytes, including the terminating NUL.
+const maxSocketPathLen = 107
+
+// SocketPath returns the absolute path of the unix socket name in dir, or ""
+// if the path exceeds the sun_path limit.
+func SocketPath(dir, name string) string {
+	path, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil || len(path) > maxSocketPathLen {
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,243 @@
+//go:build linux
+
+package netns
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Supported reports whether Command and Probe can create namespaces on this
+// platform.
+const Supported = true
+
+// init turns the binary into the namespace helper when Command or Probe
+// re-executed it, and exits with the helper's status.
+func init() {
+	if os.Getenv(HelperEnv) == "" {
+		return
+	}
+	os.Exit(runHelper(os.Args[1:], os.Stderr))
+}
+
+// Command returns a command that runs name with args in a new user and
+// network namespace, through the helper. The target process runs as root
+// of the user namespace, which maps to the caller's user and group, and
+// sees only a loopback interface. With a non-empty socket, the helper also
+// relays connections on that unix socket to port on the namespace's
+// loopback, for a Forwarder to expose outside.
+//
+// The returned command's SysProcAttr carries the namespace flags; callers
+// may set further attributes but must not replace it.
+func Command(ctx context.Context, socket string, port int, name string, args ...string) (*exec.Cmd, error) {
+	helperArgs := make([]string, 0, len(args)+4)
+	if socket != "" {
+		helperArgs = append(helperArgs, "-socket="+socket, "-port="+strconv.Itoa(port))
+	}
+	helperArgs = append(helperArgs, "--", name)
+	return helperCommand(ctx, append(helperArgs, args...)...)
+}
+
+// Probe creates a namespace through the helper without running a target
+// process, to check that the host allows unprivileged user and network
+// namespaces.
+func Probe(ctx context.Context) error {
+	cmd, err := helperCommand(ctx, "-probe")
+	if err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return fmt.Errorf("create network namespace: %w", err)
+	}
+	return nil
+}
+
+// helperCommand returns a command re-executing the running binary as the
+// helper in a new user and network namespace.
+func helperCommand(ctx context.Context, args ...string) (*exec.Cmd, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate namespace helper: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, self, args...) //nolint:gosec // G204: re-executes the running binary
+	cmd.Env = append(os.Environ(), HelperEnv+"=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+		},
+	}
+	return cmd, nil
+}
+
+// runHelper is the helper's main function. It brings up the loopback
+// interface, starts the bridge if requested, runs the target process and
+// returns its exit code.
+func runHelper(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("k8senv-netns", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	socket := fs.String("socket", "", "unix socket to relay to -port on the namespace loopback")
+	port := fs.Int("port", 0, "loopback port inside the namespace")
+	probe := fs.Bool("probe", false, "only check that the namespace can be set up")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	fail := func(err error) int {
+		_, _ = fmt.Fprintf(stderr, "k8senv netns: %v\n", err)
+		return 1
+	}
+	if err := loopbackUp(); err != nil {
+		return fail(err)
+	}
+	if *probe {
+		return 0
+	}
+	if fs.NArg() == 0 {
+		return fail(errors.New("no command given"))
+	}
+
+	if *socket != "" {
+		bridge, err := listenBridge(*socket, *port)
+		if err != nil {
+			return fail(err)
+		}
+		defer func() { _ = bridge.Close() }()
+	}
+
+	code, err := runTarget(fs.Args())
+	if err != nil {
+		return fail(err)
+	}
+	return code
+}
+
+// runTarget runs the command line args to completion, forwarding SIGTERM
+// and SIGINT to it, and returns its exit code. A target killed by a signal
+// makes the helper die of the same signal, so the caller sees the same wait
+// status as it would for the target itself.
+func runTarget(args []string) (int, error) {
+	// Signals are forwarded to the target from before it starts, so that
+	// a stop request never kills the helper alone.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	target := exec.Command(args[0], args[1:]...) //nolint:gosec // G204: command line from the parent k8senv process
+	target.Stdin = os.Stdin
+	target.Stdout = os.Stdout
+	target.Stderr = os.Stderr
+	target.Env = targetEnv()
+	// The target must not outlive the helper, whose own parent-death
+	// signal is the only way the caller can reach it once the helper is
+	// killed.
+	target.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+	if err := target.Start(); err != nil {
+		return 0, fmt.Errorf("start %s: %w", args[0], err)
+	}
+	go func() {
+		for sig := range signals {
+			_ = target.Process.Signal(sig)
+		}
+	}()
+
+	err := target.Wait()
+	if err == nil {
+		return 0, nil
+	}
+	exitErr, ok := errors.AsType[*exec.ExitError](err)
+	if !ok {
+		return 0, fmt.Errorf("wait for %s: %w", args[0], err)
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		signal.Reset(ws.Signal())
+		_ = syscall.Kill(os.Getpid(), ws.Signal())
+		return 128 + int(ws.Signal()), nil
+	}
+	return exitErr.ExitCode(), nil
+}
+
+// targetEnv returns the helper's environment without HelperEnv, so that a
+// target importing this package runs normally.
+func targetEnv() []string {
+	env := os.Environ()
+	out := env[:0]
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, HelperEnv+"=") {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// loopbackUp sets the IFF_UP flag of the namespace's loopback interface,
+// which starts out down, so that 127.0.0.1 can be bound.
+func loopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("bring up loopback: %w", err)
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	ifr, err := unix.NewIfreq("lo")
+	if err != nil {
+		return fmt.Errorf("bring up loopback: %w", err)
+	}
+	if err := unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifr); err != nil {
+		return fmt.Errorf("bring up loopback: get flags: %w", err)
+	}
+	ifr.SetUint16(ifr.Uint16() | unix.IFF_UP)
+	if err := unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifr); err != nil {
+		return fmt.Errorf("bring up loopback: set flags: %w", err)
+	}
+	return nil
+}
+
+// listenBridge listens on socket, replacing a stale socket file, and relays
+// every connection to port on the namespace loopback. Closing the returned
+// listener removes the socket file.
+func listenBridge(socket string, port int) (net.Listener, error) {
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("listen on bridge socket: %w", err)
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				upstream, err := net.DialTimeout("tcp", addr, dialTimeout)
+				if err != nil {
+					_ = conn.Close()
+					return
+				}
+				relay(conn, upstream)
+			}()
+		}
+	}()
+	return listener, nil
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package netns
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// echoPortEnv makes the test binary serve echo on 127.0.0.1 at the given
+// port instead of running tests, as the target of TestCommandBridge.
+const echoPortEnv = "K8SENV_NETNS_TEST_ECHO_PORT"
+
+func TestMain(m *testing.M) {
+	if port := os.Getenv(echoPortEnv); port != "" {
+		l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", port))
+		if err != nil {
+			os.Exit(1)
+		}
+		echo(l)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// requireNamespaces skips the test if the host does not allow unprivileged
+// user and network namespaces.
+func requireNamespaces(t *testing.T) {
+	t.Helper()
+	if err := Probe(t.Context()); err != nil {
+		t.Skipf("network namespaces unavailable: %v", err)
+	}
+}
+
+func TestCommandBridge(t *testing.T) {
+	t.Parallel()
+	requireNamespaces(t)
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	// The same fixed port for both: each runs in a namespace of its own.
+	const port = 8080
+	var fwds []*Forwarder
+	for i := range 2 {
+		socket := filepath.Join(dir, "echo"+strconv.Itoa(i)+".sock")
+		cmd, err := Command(t.Context(), socket, port, self)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmd.Env = append(cmd.Env, echoPortEnv+"="+strconv.Itoa(port))
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = cmd.Process.Signal(syscall.SIGTERM)
+			_ = cmd.Wait()
+		})
+		f, err := Listen(socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = f.Close() })
+		fwds = append(fwds, f)
+	}
+
+	for _, f := range fwds {
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			reply, err := roundTrip(t, f.Port(), "ping")
+			if err == nil {
+				if reply != "PING" {
+					t.Errorf("reply = %q, want PING", reply)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("no reply through forwarder on port %d: %v", f.Port(), err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestCommandIsolation(t *testing.T) {
+	t.Parallel()
+	requireNamespaces(t)
+
+	cmd, err := Command(t.Context(), "", 0, "cat", "/proc/net/dev", "/proc/self/uid_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	var ifaces []string
+	for line := range strings.Lines(string(out)) {
+		if name, _, ok := strings.Cut(line, ":"); ok && !strings.Contains(line, "|") {
+			ifaces = append(ifaces, strings.TrimSpace(name))
+		}
+	}
+	if len(ifaces) != 1 || ifaces[0] != "lo" {
+		t.Errorf("interfaces in namespace = %v, want [lo]", ifaces)
+	}
+	if want := "0 " + strconv.Itoa(os.Getuid()); !strings.Contains(strings.Join(strings.Fields(string(out)), " "), want+" 1") {
+		t.Errorf("uid_map does not map 0 to %d:\n%s", os.Getuid(), out)
+	}
+}
+
+func TestCommandExitStatus(t *testing.T) {
+	t.Parallel()
+	requireNamespaces(t)
+
+	run := func(script string) error {
+		cmd, err := Command(t.Context(), "", 0, "sh", "-c", script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cmd.Run()
+	}
+
+	err := run("exit 3")
+	if exitErr, ok := errors.AsType[*exec.ExitError](err); !ok || exitErr.ExitCode() != 3 {
+		t.Errorf("exit 3: err = %v, want exit status 3", err)
+	}
+
+	err = run("kill -TERM $$")
+	exitErr, ok := errors.AsType[*exec.ExitError](err)
+	if !ok {
+		t.Fatalf("kill -TERM: err = %v, want an exit error", err)
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !ws.Signaled() || ws.Signal() != syscall.SIGTERM {
+		t.Errorf("kill -TERM: wait status %v, want killed by SIGTERM", exitErr.Sys())
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package netns
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Supported reports whether Command and Probe can create namespaces on this
+// platform.
+const Supported = false
+
+// Command returns ErrUnsupported: network namespaces are a Linux feature.
+func Command(_ context.Context, _ string, _ int, _ string, _ ...string) (*exec.Cmd, error) {
+	return nil, ErrUnsupported
+}
+
+// Probe returns ErrUnsupported: network namespaces are a Linux feature.
+func Probe(_ context.Context) error {
+	return ErrUnsupported
+}
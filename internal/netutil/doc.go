@@ -1,5 +1,7 @@
 // Package netutil provides network utility functions for k8senv.
 // Its central type, PortRegistry, allocates pairs of ephemeral ports and tracks
 // reserved ports across the process to prevent duplicate allocation from the
-// TOCTOU race between concurrent callers.
+// TOCTOU race between concurrent callers. A registry created with
+// NewSharedPortRegistry extends this across processes through per-port file
+// locks.
 package netutil
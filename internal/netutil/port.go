@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
+
+	"github.com/gofrs/flock"
 )
 
 // maxPortRetries is the maximum number of attempts to find a port not already
@@ -18,25 +23,51 @@ var loopbackAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
 // PortRegistry tracks ports currently reserved by this process to prevent
 // the TOCTOU race where two concurrent AllocatePortPair calls receive the
 // same port from the kernel (because the first caller closed its listener
-// before the second caller opened theirs). A small external-process TOCTOU
-// window remains between the listener close and the consumer bind; this is
-// inherent to the listen-then-close pattern and acceptable for ephemeral
-// test ports.
+// before the second caller opened theirs).
+//
+// A registry created with NewSharedPortRegistry also takes an exclusive file
+// lock per port in a lock directory, so separate processes sharing that
+// directory (e.g. the package test binaries of one go test ./... run) never
+// hand out the same port either. The lock is held until Release and dropped
+// by the kernel if the process dies. A small TOCTOU window remains against
+// processes that do not take part, between the listener close and the
+// consumer bind; this is inherent to the listen-then-close pattern and
+// acceptable for ephemeral test ports.
 //
 // The singleton Manager creates one PortRegistry and shares it via dependency
 // injection with all instances and temporary stacks (e.g., CRD cache creation).
 type PortRegistry struct {
 	mu    sync.Mutex
 	ports map[int]struct{}
+
+	// lockDir holds the per-port lock files, or is empty for a registry
+	// that only coordinates within the process.
+	lockDir string
+	// locks holds the file lock of each reserved port when lockDir is set.
+	locks map[int]*flock.Flock
 }
 
-// NewPortRegistry creates a new PortRegistry ready for use.
+// NewPortRegistry creates a new PortRegistry that coordinates allocations
+// within this process only.
 func NewPortRegistry() *PortRegistry {
 	return &PortRegistry{
 		ports: make(map[int]struct{}),
 	}
 }
 
+// NewSharedPortRegistry creates a new PortRegistry that also coordinates
+// with every other process using a shared registry in lockDir. The
+// directory is created on first allocation; lock files are left in it after
+// release, like the CRD cache lock, because removing them could invalidate a
+// lock concurrently taken by another process.
+func NewSharedPortRegistry(lockDir string) *PortRegistry {
+	return &PortRegistry{
+		ports:   make(map[int]struct{}),
+		lockDir: lockDir,
+		locks:   make(map[int]*flock.Flock),
+	}
+}
+
 // reserve attempts to register a port in the registry.
 // Returns true if the port was successfully reserved, false if already taken.
 func (r *PortRegistry) reserve(port int) bool {
@@ -49,8 +80,33 @@ func (r *PortRegistry) reserve(port int) bool {
 	return true
 }
 
-// Release removes a port from the registry, allowing it to be reused.
-// It logs a warning if the port was not previously reserved.
+// lockPort takes the cross-process file lock of a port already reserved in
+// the registry. It returns false, without error, if another process holds
+// it, and true without locking anything for a registry without lockDir.
+func (r *PortRegistry) lockPort(port int) (bool, error) {
+	if r.lockDir == "" {
+		return true, nil
+	}
+	if err := os.MkdirAll(r.lockDir, 0o755); err != nil {
+		return false, fmt.Errorf("create port lock directory: %w", err)
+	}
+	fl := flock.New(filepath.Join(r.lockDir, strconv.Itoa(port)+".lock"))
+	locked, err := fl.TryLock()
+	if err != nil {
+		return false, fmt.Errorf("lock port %d: %w", port, err)
+	}
+	if !locked {
+		return false, nil
+	}
+	r.mu.Lock()
+	r.locks[port] = fl
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Release removes a port from the registry, allowing it to be reused, and
+// drops its cross-process lock. It logs a warning if the port was not
+// previously reserved.
 func (r *PortRegistry) Release(port int) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -59,12 +115,19 @@ func (r *PortRegistry) Release(port int) {
 		return
 	}
 	delete(r.ports, port)
+	if fl, ok := r.locks[port]; ok {
+		delete(r.locks, port)
+		if err := fl.Close(); err != nil {
+			slog.Default().Debug("failed to release port lock", "port", port, "path", fl.Path(), "err", err)
+		}
+	}
 }
 
 // tryAllocate opens a listener to obtain an ephemeral port, reserves it in
-// the registry, and closes the listener. It returns ok=true with the port on
-// success, ok=false when the port is already registered (caller should retry),
-// or an error.
+// the registry, takes its cross-process lock, and closes the listener. It
+// returns ok=true with the port on success, ok=false when the port is already
+// registered here or locked by another process (caller should retry), or an
+// error.
 func (r *PortRegistry) tryAllocate() (port int, ok bool, err error) {
 	l, err := net.ListenTCP("tcp", loopbackAddr)
 	if err != nil {
@@ -84,6 +147,17 @@ func (r *PortRegistry) tryAllocate() (port int, ok bool, err error) {
 		return 0, false, nil
 	}
 
+	locked, err := r.lockPort(p)
+	if err != nil || !locked {
+		_ = l.Close()
+		r.Release(p)
+		if err != nil {
+			return 0, false, err
+		}
+		slog.Default().Debug("port locked by another process, retrying", "port", p)
+		return 0, false, nil
+	}
+
 	if err := l.Close(); err != nil {
 		r.Release(p)
 		return 0, false, fmt.Errorf("close listener for port %d: %w", p, err)
@@ -117,6 +191,16 @@ func (r *PortRegistry) allocatePort() (int, error) {
 	)
 }
 
+// AllocatePort allocates a single free port, for processes beyond the pair
+// returned by AllocatePortPair.
+//
+// The port is registered in the registry to prevent duplicate allocation
+// across concurrent callers. Callers must call Release when it is no longer
+// needed.
+func (r *PortRegistry) AllocatePort() (int, error) {
+	return r.allocatePort()
+}
+
 // AllocatePortPair allocates two distinct free ports.
 //
 // Ports are registered in the registry to prevent duplicate allocation across
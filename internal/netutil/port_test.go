@@ -1,6 +1,9 @@
 package netutil
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"testing"
 )
@@ -269,6 +272,30 @@ func TestPortRegistry_AllocatePortPair(t *testing.T) {
 	r.Release(p2)
 }
 
+func TestPortRegistry_AllocatePort(t *testing.T) {
+	t.Parallel()
+
+	r := NewPortRegistry()
+	p1, p2, err := r.AllocatePortPair()
+	if err != nil {
+		t.Fatalf("AllocatePortPair() error: %v", err)
+	}
+	p3, err := r.AllocatePort()
+	if err != nil {
+		t.Fatalf("AllocatePort() error: %v", err)
+	}
+	if p3 == 0 || p3 == p1 || p3 == p2 {
+		t.Errorf("AllocatePort() = %d, want a non-zero port distinct from %d and %d", p3, p1, p2)
+	}
+	if r.reserve(p3) {
+		t.Errorf("port %d should already be registered, but reserve succeeded", p3)
+	}
+
+	r.Release(p1)
+	r.Release(p2)
+	r.Release(p3)
+}
+
 func TestPortRegistry_AllocateMultiplePairs(t *testing.T) {
 	t.Parallel()
 
@@ -299,3 +326,88 @@ func TestPortRegistry_AllocateMultiplePairs(t *testing.T) {
 		r.Release(port)
 	}
 }
+
+func TestSharedPortRegistry_CrossProcess(t *testing.T) {
+	t.Parallel()
+
+	// Two registries sharing a lock directory stand in for two processes:
+	// each holds its locks through its own open file descriptions, which
+	// conflict like those of separate processes.
+	dir := t.TempDir()
+	a := NewSharedPortRegistry(dir)
+	b := NewSharedPortRegistry(dir)
+
+	port, err := a.AllocatePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, strconv.Itoa(port)+".lock")); err != nil {
+		t.Fatalf("lock file for port %d: %v", port, err)
+	}
+
+	if !b.reserve(port) {
+		t.Fatalf("in-process reserve(%d) in second registry failed", port)
+	}
+	locked, err := b.lockPort(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked {
+		t.Fatalf("second registry locked port %d held by the first", port)
+	}
+	b.Release(port)
+
+	a.Release(port)
+	if !b.reserve(port) {
+		t.Fatalf("reserve(%d) after release failed", port)
+	}
+	locked, err = b.lockPort(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked {
+		t.Errorf("second registry could not lock port %d after the first released it", port)
+	}
+	b.Release(port)
+}
+
+func TestSharedPortRegistry_DistinctPorts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	registries := []*PortRegistry{NewSharedPortRegistry(dir), NewSharedPortRegistry(dir)}
+
+	seen := make(map[int]bool)
+	for i := range 20 {
+		r := registries[i%2]
+		p1, p2, err := r.AllocatePortPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range []int{p1, p2} {
+			if seen[p] {
+				t.Fatalf("port %d allocated twice across registries", p)
+			}
+			seen[p] = true
+		}
+	}
+}
+
+func TestSharedPortRegistry_LockDirError(t *testing.T) {
+	t.Parallel()
+
+	// A regular file where the lock directory should be.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewSharedPortRegistry(filepath.Join(file, "ports"))
+	if _, err := r.AllocatePort(); err == nil {
+		t.Fatal("AllocatePort succeeded with an unusable lock directory")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.ports) != 0 {
+		t.Errorf("registry holds %d ports after failed allocation", len(r.ports))
+	}
+}
@@ -0,0 +1,11 @@
+// Package nodesim provides an in-process stand-in for the scheduler and
+// kubelets of a cluster. It registers fake Node objects with capacity and
+// conditions, binpacks pending Pods onto them, drives the status of bound
+// Pods through Pending, Running and Succeeded or Failed according to
+// scripted rules, and renews the nodes' Leases in kube-node-lease.
+//
+// No containers run: container states, readiness and exit codes are written
+// to the Pod status as if they had. A Simulator watches Pods with a client-go
+// informer and acts through the regular API, so operators under test observe
+// the same watch events and status updates they would in a cluster.
+package nodesim
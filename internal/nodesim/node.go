@@ -0,0 +1,184 @@
+package nodesim
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// nodeLeaseNamespace holds the node heartbeat Leases.
+const nodeLeaseNamespace = corev1.NamespaceNodeLease
+
+// nodeLeaseDuration and nodeLeaseRenewInterval match the kubelet defaults.
+const (
+	nodeLeaseDuration      = 40 * time.Second
+	nodeLeaseRenewInterval = 10 * time.Second
+)
+
+// containerRuntime is reported in NodeInfo and is the scheme of simulated
+// container IDs.
+const containerRuntime = "k8senv"
+
+// nodeState is a registered node together with the addresses assigned to it.
+type nodeState struct {
+	Node
+	capacity corev1.ResourceList
+	hostIP   string
+	// podSubnet is the /24 prefix pod IPs are allocated from, e.g. "10.244.0".
+	podSubnet string
+}
+
+// newNodeState fills in defaults for node number index.
+func newNodeState(n Node, index int) nodeState {
+	capacity := DefaultCapacity.DeepCopy()
+	maps.Copy(capacity, n.Capacity)
+	return nodeState{
+		Node:      n,
+		capacity:  capacity,
+		hostIP:    fmt.Sprintf("10.0.%d.%d", index/250, index%250+1),
+		podSubnet: fmt.Sprintf("10.244.%d", index),
+	}
+}
+
+// labels returns the node's labels: the well-known hostname, os and arch
+// labels overlaid with the configured ones.
+func (n nodeState) labels() map[string]string {
+	nodeLabels := map[string]string{
+		corev1.LabelHostname:   n.Name,
+		corev1.LabelOSStable:   "linux",
+		corev1.LabelArchStable: runtime.GOARCH,
+	}
+	maps.Copy(nodeLabels, n.Labels)
+	return nodeLabels
+}
+
+// object returns the Node object to register, including its status.
+func (n nodeState) object(kubeletVersion string) *corev1.Node {
+	now := metav1.Now()
+	condition := func(t corev1.NodeConditionType, s corev1.ConditionStatus, reason string) corev1.NodeCondition {
+		return corev1.NodeCondition{
+			Type: t, Status: s, Reason: reason,
+			LastHeartbeatTime: now, LastTransitionTime: now,
+		}
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Labels: n.labels()},
+		Spec:       corev1.NodeSpec{PodCIDR: n.podSubnet + ".0/24", PodCIDRs: []string{n.podSubnet + ".0/24"}},
+		Status: corev1.NodeStatus{
+			Capacity:    n.capacity.DeepCopy(),
+			Allocatable: n.capacity.DeepCopy(),
+			Phase:       corev1.NodeRunning,
+			Conditions: []corev1.NodeCondition{
+				condition(corev1.NodeMemoryPressure, corev1.ConditionFalse, "KubeletHasSufficientMemory"),
+				condition(corev1.NodeDiskPressure, corev1.ConditionFalse, "KubeletHasNoDiskPressure"),
+				condition(corev1.NodePIDPressure, corev1.ConditionFalse, "KubeletHasSufficientPID"),
+				condition(corev1.NodeReady, corev1.ConditionTrue, "KubeletReady"),
+			},
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: n.hostIP},
+				{Type: corev1.NodeHostName, Address: n.Name},
+			},
+			NodeInfo: corev1.NodeSystemInfo{
+				OperatingSystem:         "linux",
+				Architecture:            runtime.GOARCH,
+				KubeletVersion:          kubeletVersion,
+				ContainerRuntimeVersion: containerRuntime + "://simulated",
+			},
+		},
+	}
+}
+
+// registerNode creates the Node, or updates it if it already exists, and
+// then writes its status, which create ignores.
+func (s *Simulator) registerNode(ctx context.Context, n nodeState) error {
+	want := n.object(s.kubeletVersion)
+	nodes := s.client.CoreV1().Nodes()
+
+	got, err := nodes.Create(ctx, want, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		got, err = nodes.Get(ctx, n.Name, metav1.GetOptions{})
+		if err == nil {
+			got.Labels = want.Labels
+			got.Spec = want.Spec
+			got, err = nodes.Update(ctx, got, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("register node %s: %w", n.Name, err)
+	}
+
+	got.Status = want.Status
+	if _, err := nodes.UpdateStatus(ctx, got, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update status of node %s: %w", n.Name, err)
+	}
+	return nil
+}
+
+// renewLease creates or renews the heartbeat Lease of node name.
+func (s *Simulator) renewLease(ctx context.Context, name string) error {
+	leases := s.client.CoordinationV1().Leases(nodeLeaseNamespace)
+	now := metav1.NewMicroTime(time.Now())
+
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nodeLeaseNamespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(name),
+				LeaseDurationSeconds: ptr.To(int32(nodeLeaseDuration / time.Second)),
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+	} else if err == nil {
+		lease.Spec.HolderIdentity = ptr.To(name)
+		lease.Spec.LeaseDurationSeconds = ptr.To(int32(nodeLeaseDuration / time.Second))
+		lease.Spec.RenewTime = &now
+		_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("renew lease of node %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteLeases deletes the heartbeat Lease of every node. Failures are
+// logged: a Lease that survives is only stale, and the next Start renews it.
+func (s *Simulator) deleteLeases() {
+	ctx, cancel := context.WithTimeout(context.Background(), unregisterTimeout)
+	defer cancel()
+	leases := s.client.CoordinationV1().Leases(nodeLeaseNamespace)
+	for _, n := range s.nodes {
+		err := leases.Delete(ctx, n.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			s.log.Warn("nodesim: delete node lease", "node", n.Name, "error", err)
+		}
+	}
+}
+
+// renewLeases renews every node Lease each nodeLeaseRenewInterval until ctx
+// is canceled.
+func (s *Simulator) renewLeases(ctx context.Context) {
+	ticker := time.NewTicker(nodeLeaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, n := range s.nodes {
+			if err := s.renewLease(ctx, n.Name); err != nil && ctx.Err() == nil {
+				s.log.Warn("nodesim: renew node lease", "node", n.Name, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package nodesim
+
+import (
+	"log/slog"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeleteLeases(t *testing.T) {
+	t.Parallel()
+
+	s := &Simulator{
+		log:    slog.New(slog.DiscardHandler),
+		client: fake.NewClientset(),
+		nodes:  []nodeState{newNodeState(Node{Name: "a"}, 0), newNodeState(Node{Name: "b"}, 1)},
+	}
+	// Only node a has a Lease; the missing one must not be reported.
+	if err := s.renewLease(t.Context(), "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.deleteLeases()
+
+	leases := s.client.CoordinationV1().Leases(nodeLeaseNamespace)
+	for _, name := range []string{"a", "b"} {
+		if _, err := leases.Get(t.Context(), name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("Get(lease %q) error = %v, want NotFound", name, err)
+		}
+	}
+}
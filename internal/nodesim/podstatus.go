@@ -0,0 +1,244 @@
+package nodesim
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// podAddresses are the addresses a simulated Pod is reported at.
+type podAddresses struct {
+	hostIP string
+	podIP  string
+}
+
+// desiredStatus returns the status a kubelet would report for pod at time
+// now under rule, and how long until the status next changes (zero if it
+// never does on its own).
+//
+// The timeline is anchored at the Pod's startTime, or now for a Pod seen for
+// the first time. Init containers complete instantly; restartable init
+// containers (sidecars) run alongside the regular containers. Every run of
+// the containers lasts rule.RunFor; when the restart policy restarts them,
+// the next run begins immediately and the restart count goes up. Times are
+// truncated to seconds, the precision they are stored with, so a status
+// computed again from the stored Pod compares equal.
+//
+// Fields not owned by the kubelet, such as qosClass and conditions other
+// than the standard ones, are copied from pod.Status.
+func desiredStatus(pod *corev1.Pod, rule PodRule, addr podAddresses, now time.Time) (corev1.PodStatus, time.Duration) {
+	now = now.Truncate(time.Second)
+	start := now
+	if pod.Status.StartTime != nil {
+		start = pod.Status.StartTime.Time
+	}
+	elapsed := now.Sub(start)
+
+	exited := rule.RunFor > 0 && elapsed >= rule.RunFor
+	restarts := restartsAfter(pod.Spec.RestartPolicy, rule.ExitCode)
+
+	status := *pod.Status.DeepCopy()
+	status.HostIP = addr.hostIP
+	status.HostIPs = []corev1.HostIP{{IP: addr.hostIP}}
+	status.PodIP = addr.podIP
+	status.PodIPs = []corev1.PodIP{{IP: addr.podIP}}
+	status.StartTime = ptr.To(stamp(start))
+	status.Reason = ""
+	status.Message = ""
+
+	if exited && !restarts {
+		finished := start.Add(rule.RunFor)
+		status.Phase = corev1.PodSucceeded
+		if rule.ExitCode != 0 {
+			status.Phase = corev1.PodFailed
+		}
+		status.InitContainerStatuses = initContainerStatuses(pod, start, nil)
+		status.ContainerStatuses = make([]corev1.ContainerStatus, 0, len(pod.Spec.Containers))
+		for _, c := range pod.Spec.Containers {
+			cs := containerStatus(pod, c, 0)
+			cs.State = corev1.ContainerState{Terminated: terminated(rule.ExitCode, start, finished)}
+			cs.Started = ptr.To(false)
+			status.ContainerStatuses = append(status.ContainerStatuses, cs)
+		}
+		status.Conditions = standardConditions(pod, status.Conditions, false, "PodCompleted", now)
+		return status, 0
+	}
+
+	// The current run began after restartCount complete runs.
+	var restartCount int32
+	runStart := start
+	if rule.RunFor > 0 {
+		restartCount = int32(elapsed / rule.RunFor) //nolint:gosec // bounded by elapsed time
+		runStart = start.Add(time.Duration(restartCount) * rule.RunFor)
+	}
+	readyAt := runStart.Add(rule.ReadyAfter)
+	ready := !now.Before(readyAt)
+
+	var lastState *corev1.ContainerStateTerminated
+	if restartCount > 0 {
+		lastState = terminated(rule.ExitCode, runStart.Add(-rule.RunFor), runStart)
+	}
+	running := func(c corev1.Container) corev1.ContainerStatus {
+		cs := containerStatus(pod, c, restartCount)
+		cs.State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: stamp(runStart)}}
+		cs.Started = ptr.To(true)
+		cs.Ready = ready
+		if lastState != nil {
+			cs.LastTerminationState = corev1.ContainerState{Terminated: lastState}
+		}
+		return cs
+	}
+
+	status.Phase = corev1.PodRunning
+	status.InitContainerStatuses = initContainerStatuses(pod, start, running)
+	status.ContainerStatuses = make([]corev1.ContainerStatus, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		status.ContainerStatuses = append(status.ContainerStatuses, running(c))
+	}
+	reason := ""
+	if !ready {
+		reason = "ContainersNotReady"
+	}
+	status.Conditions = standardConditions(pod, status.Conditions, ready, reason, now)
+
+	var next time.Duration
+	if !ready {
+		next = readyAt.Sub(now)
+	}
+	if rule.RunFor > 0 {
+		untilExit := runStart.Add(rule.RunFor).Sub(now)
+		if next == 0 || untilExit < next {
+			next = untilExit
+		}
+	}
+	return status, next
+}
+
+// restartsAfter reports whether containers exiting with exitCode are
+// restarted under policy.
+func restartsAfter(policy corev1.RestartPolicy, exitCode int32) bool {
+	switch policy {
+	case corev1.RestartPolicyNever:
+		return false
+	case corev1.RestartPolicyOnFailure:
+		return exitCode != 0
+	default:
+		return true
+	}
+}
+
+// initContainerStatuses reports regular init containers as completed at
+// start and restartable ones as running, using running to build their
+// status. When running is nil the Pod has finished and every init
+// container is reported as completed.
+func initContainerStatuses(
+	pod *corev1.Pod, start time.Time, running func(corev1.Container) corev1.ContainerStatus,
+) []corev1.ContainerStatus {
+	if len(pod.Spec.InitContainers) == 0 {
+		return nil
+	}
+	out := make([]corev1.ContainerStatus, 0, len(pod.Spec.InitContainers))
+	for _, c := range pod.Spec.InitContainers {
+		sidecar := c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways
+		if sidecar && running != nil {
+			out = append(out, running(c))
+			continue
+		}
+		cs := containerStatus(pod, c, 0)
+		cs.State = corev1.ContainerState{Terminated: terminated(0, start, start)}
+		cs.Started = ptr.To(false)
+		out = append(out, cs)
+	}
+	return out
+}
+
+// containerStatus returns the identifying fields of the status of c.
+func containerStatus(pod *corev1.Pod, c corev1.Container, restartCount int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:         c.Name,
+		Image:        c.Image,
+		ImageID:      c.Image,
+		ContainerID:  fmt.Sprintf("%s://%s-%s-%d", containerRuntime, pod.UID, c.Name, restartCount),
+		RestartCount: restartCount,
+	}
+}
+
+// terminated returns a terminated container state with the reason the
+// kubelet reports for exitCode.
+func terminated(exitCode int32, started, finished time.Time) *corev1.ContainerStateTerminated {
+	reason := "Completed"
+	if exitCode != 0 {
+		reason = "Error"
+	}
+	return &corev1.ContainerStateTerminated{
+		ExitCode:   exitCode,
+		Reason:     reason,
+		StartedAt:  stamp(started),
+		FinishedAt: stamp(finished),
+	}
+}
+
+// standardConditions sets the conditions the kubelet owns in conds. Ready
+// additionally requires every readiness gate condition to be True.
+func standardConditions(
+	pod *corev1.Pod, conds []corev1.PodCondition, containersReady bool, reason string, now time.Time,
+) []corev1.PodCondition {
+	podReady := containersReady
+	for _, gate := range pod.Spec.ReadinessGates {
+		if !conditionTrue(conds, gate.ConditionType) {
+			podReady = false
+			if reason == "" {
+				reason = "ReadinessGatesNotReady"
+			}
+		}
+	}
+
+	conds = setCondition(conds, corev1.PodScheduled, true, "", now)
+	conds = setCondition(conds, corev1.PodReadyToStartContainers, true, "", now)
+	conds = setCondition(conds, corev1.PodInitialized, true, "", now)
+	conds = setCondition(conds, corev1.ContainersReady, containersReady, reason, now)
+	return setCondition(conds, corev1.PodReady, podReady, reason, now)
+}
+
+// conditionTrue reports whether conds has condition t with status True.
+func conditionTrue(conds []corev1.PodCondition, t corev1.PodConditionType) bool {
+	for _, c := range conds {
+		if c.Type == t {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setCondition sets condition t in conds, keeping its lastTransitionTime if
+// the status is unchanged. Reason is cleared when the condition is True.
+func setCondition(
+	conds []corev1.PodCondition, t corev1.PodConditionType, ok bool, reason string, now time.Time,
+) []corev1.PodCondition {
+	want := corev1.PodCondition{Type: t, Status: corev1.ConditionFalse, Reason: reason}
+	if ok {
+		want.Status = corev1.ConditionTrue
+		want.Reason = ""
+	}
+	for i, c := range conds {
+		if c.Type != t {
+			continue
+		}
+		want.LastTransitionTime = c.LastTransitionTime
+		if c.Status != want.Status || c.LastTransitionTime.IsZero() {
+			want.LastTransitionTime = stamp(now)
+		}
+		conds[i] = want
+		return conds
+	}
+	want.LastTransitionTime = stamp(now)
+	return append(conds, want)
+}
+
+// stamp converts t to an API time at the precision it is stored with.
+func stamp(t time.Time) metav1.Time {
+	return metav1.NewTime(t.Truncate(time.Second))
+}
@@ -0,0 +1,143 @@
+package nodesim
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testAddr = podAddresses{hostIP: "10.0.0.1", podIP: "10.244.0.2"}
+
+// newTestPod returns a Pod with one container, started at start if it is
+// non-zero.
+func newTestPod(policy corev1.RestartPolicy, start time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "test", UID: "uid"},
+		Spec: corev1.PodSpec{
+			RestartPolicy: policy,
+			Containers:    []corev1.Container{{Name: "app", Image: "app:1"}},
+		},
+	}
+	if !start.IsZero() {
+		pod.Status.StartTime = &metav1.Time{Time: start}
+	}
+	return pod
+}
+
+// condition returns the status of condition t, or "" if it is not set.
+func condition(status corev1.PodStatus, t corev1.PodConditionType) corev1.ConditionStatus {
+	for _, c := range status.Conditions {
+		if c.Type == t {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func TestDesiredStatusReadyAfter(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rule := PodRule{ReadyAfter: 5 * time.Second}
+	pod := newTestPod(corev1.RestartPolicyAlways, start)
+
+	status, next := desiredStatus(pod, rule, testAddr, start.Add(2*time.Second))
+	if status.Phase != corev1.PodRunning || condition(status, corev1.PodReady) != corev1.ConditionFalse {
+		t.Fatalf("at 2s: phase %s, Ready %s; want Running, False", status.Phase, condition(status, corev1.PodReady))
+	}
+	if next != 3*time.Second {
+		t.Errorf("at 2s: next = %v, want 3s", next)
+	}
+	if status.PodIP != testAddr.podIP || status.HostIP != testAddr.hostIP {
+		t.Errorf("addresses = %s/%s, want %s/%s", status.HostIP, status.PodIP, testAddr.hostIP, testAddr.podIP)
+	}
+
+	pod.Status = status
+	status, next = desiredStatus(pod, rule, testAddr, start.Add(5*time.Second))
+	if condition(status, corev1.PodReady) != corev1.ConditionTrue || !status.ContainerStatuses[0].Ready {
+		t.Errorf("at 5s: Pod or container not Ready: %+v", status)
+	}
+	if next != 0 {
+		t.Errorf("at 5s: next = %v, want 0", next)
+	}
+}
+
+func TestDesiredStatusExit(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		policy   corev1.RestartPolicy
+		exitCode int32
+		want     corev1.PodPhase
+		restarts int32
+	}{
+		{name: "never success", policy: corev1.RestartPolicyNever, want: corev1.PodSucceeded},
+		{name: "never failure", policy: corev1.RestartPolicyNever, exitCode: 1, want: corev1.PodFailed},
+		{name: "on failure success", policy: corev1.RestartPolicyOnFailure, want: corev1.PodSucceeded},
+		{name: "on failure restarts", policy: corev1.RestartPolicyOnFailure, exitCode: 2, want: corev1.PodRunning, restarts: 2},
+		{name: "always restarts", policy: corev1.RestartPolicyAlways, want: corev1.PodRunning, restarts: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule := PodRule{RunFor: 10 * time.Second, ExitCode: tt.exitCode}
+			status, _ := desiredStatus(newTestPod(tt.policy, start), rule, testAddr, start.Add(25*time.Second))
+			if status.Phase != tt.want {
+				t.Fatalf("phase = %s, want %s", status.Phase, tt.want)
+			}
+			cs := status.ContainerStatuses[0]
+			if cs.RestartCount != tt.restarts {
+				t.Errorf("restartCount = %d, want %d", cs.RestartCount, tt.restarts)
+			}
+			switch {
+			case tt.want != corev1.PodRunning:
+				if cs.State.Terminated == nil || cs.State.Terminated.ExitCode != tt.exitCode {
+					t.Errorf("state = %+v, want terminated with exit code %d", cs.State, tt.exitCode)
+				}
+			case cs.LastTerminationState.Terminated == nil:
+				t.Error("lastState.terminated not set after restart")
+			}
+		})
+	}
+}
+
+func TestDesiredStatusStable(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod := newTestPod(corev1.RestartPolicyAlways, start)
+	pod.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "init:1"}}
+	pod.Status, _ = desiredStatus(pod, PodRule{}, testAddr, start.Add(time.Second))
+
+	again, _ := desiredStatus(pod, PodRule{}, testAddr, start.Add(time.Minute))
+	for i, c := range again.Conditions {
+		if !c.LastTransitionTime.Equal(&pod.Status.Conditions[i].LastTransitionTime) {
+			t.Errorf("condition %s transition time moved from %v to %v",
+				c.Type, pod.Status.Conditions[i].LastTransitionTime, c.LastTransitionTime)
+		}
+	}
+	if got := again.InitContainerStatuses[0].State.Terminated; got == nil || got.Reason != "Completed" {
+		t.Errorf("init container state = %+v, want terminated Completed", again.InitContainerStatuses[0].State)
+	}
+}
+
+func TestDesiredStatusReadinessGate(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod := newTestPod(corev1.RestartPolicyAlways, start)
+	pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: "example.com/ready"}}
+
+	status, _ := desiredStatus(pod, PodRule{}, testAddr, start)
+	if condition(status, corev1.ContainersReady) != corev1.ConditionTrue {
+		t.Error("ContainersReady not True")
+	}
+	if condition(status, corev1.PodReady) != corev1.ConditionFalse {
+		t.Error("Ready is not False although the readiness gate is unset")
+	}
+}
@@ -0,0 +1,124 @@
+package nodesim
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// nodeUsage is the sum of the requests of the Pods placed on a node.
+type nodeUsage struct {
+	requests corev1.ResourceList
+	pods     int64
+}
+
+// add accounts for a Pod requesting req.
+func (u *nodeUsage) add(req corev1.ResourceList) {
+	if u.requests == nil {
+		u.requests = corev1.ResourceList{}
+	}
+	for name, q := range req {
+		sum := u.requests[name]
+		sum.Add(q)
+		u.requests[name] = sum
+	}
+	u.pods++
+}
+
+// podRequests returns the resources pod reserves on its node: the larger of
+// the sum of its containers' requests and any single init container's
+// requests, plus the Pod overhead. Restartable init containers (sidecars)
+// run alongside the containers and count towards the sum.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	add := func(req corev1.ResourceList) {
+		for name, q := range req {
+			sum := total[name]
+			sum.Add(q)
+			total[name] = sum
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		add(c.Resources.Requests)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			add(c.Resources.Requests)
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			continue
+		}
+		for name, q := range c.Resources.Requests {
+			if cur, ok := total[name]; !ok || q.Cmp(cur) > 0 {
+				total[name] = q.DeepCopy()
+			}
+		}
+	}
+	add(pod.Spec.Overhead)
+	return total
+}
+
+// fits reports whether a Pod with nodeSelector selector and requests req
+// can be placed on n given its current usage u.
+func fits(n nodeState, u nodeUsage, selector map[string]string, req corev1.ResourceList) bool {
+	if !labels.SelectorFromSet(selector).Matches(labels.Set(n.labels())) {
+		return false
+	}
+	if maxPods, ok := n.capacity[corev1.ResourcePods]; ok && u.pods+1 > maxPods.Value() {
+		return false
+	}
+	for name, q := range req {
+		if q.IsZero() {
+			continue
+		}
+		free, ok := n.capacity[name]
+		if !ok {
+			return false
+		}
+		free = free.DeepCopy()
+		free.Sub(u.requests[name])
+		if free.Cmp(q) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// allocation returns the mean fraction of n's CPU and memory that would be
+// requested after placing a Pod requesting req.
+func allocation(n nodeState, u nodeUsage, req corev1.ResourceList) float64 {
+	var sum float64
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		capacity := n.capacity[name]
+		if capacity.IsZero() {
+			continue
+		}
+		used := u.requests[name].DeepCopy()
+		used.Add(req[name])
+		sum += used.AsApproximateFloat64() / capacity.AsApproximateFloat64()
+	}
+	return sum / 2
+}
+
+// pickNode binpacks pod: among the nodes it fits on, it returns the one
+// that would be most allocated afterwards, preferring earlier nodes on a
+// tie. Only the nodeSelector, resource requests and the pods limit are
+// considered; affinity, taints and topology spread are not.
+func pickNode(nodes []nodeState, usage map[string]nodeUsage, pod *corev1.Pod) (nodeState, bool) {
+	req := podRequests(pod)
+	var (
+		best      nodeState
+		bestScore = -1.0
+	)
+	for _, n := range nodes {
+		u := usage[n.Name]
+		if !fits(n, u, pod.Spec.NodeSelector, req) {
+			continue
+		}
+		if score := allocation(n, u, req); score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best, bestScore >= 0
+}
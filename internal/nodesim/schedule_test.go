@@ -0,0 +1,105 @@
+package nodesim
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+// podRequesting returns a Pod whose single container requests cpu.
+func podRequesting(cpu string) *corev1.Pod {
+	return &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse(cpu),
+		}},
+	}}}}
+}
+
+func TestPodRequests(t *testing.T) {
+	t.Parallel()
+
+	pod := podRequesting("500m")
+	pod.Spec.Containers = append(pod.Spec.Containers, podRequesting("250m").Spec.Containers[0])
+	pod.Spec.InitContainers = []corev1.Container{
+		podRequesting("1").Spec.Containers[0],
+		{
+			Name:          "sidecar",
+			RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways),
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("100m"),
+			}},
+		},
+	}
+
+	got := podRequests(pod)[corev1.ResourceCPU]
+	if want := resource.MustParse("1"); got.Cmp(want) != 0 {
+		t.Errorf("cpu request = %s, want %s (largest init container)", got.String(), want.String())
+	}
+
+	pod.Spec.InitContainers = pod.Spec.InitContainers[1:]
+	got = podRequests(pod)[corev1.ResourceCPU]
+	if want := resource.MustParse("850m"); got.Cmp(want) != 0 {
+		t.Errorf("cpu request = %s, want %s (containers plus sidecar)", got.String(), want.String())
+	}
+}
+
+func TestPickNodeBinpacks(t *testing.T) {
+	t.Parallel()
+
+	nodes := []nodeState{
+		newNodeState(Node{Name: "a"}, 0),
+		newNodeState(Node{Name: "b"}, 1),
+	}
+	usage := map[string]nodeUsage{}
+	u := usage["b"]
+	u.add(podRequesting("2").Spec.Containers[0].Resources.Requests)
+	usage["b"] = u
+
+	got, ok := pickNode(nodes, usage, podRequesting("1"))
+	if !ok || got.Name != "b" {
+		t.Errorf("pickNode() = %q, %v; want the more allocated node b", got.Name, ok)
+	}
+
+	got, ok = pickNode(nodes, usage, podRequesting("7"))
+	if !ok || got.Name != "a" {
+		t.Errorf("pickNode() = %q, %v; want a, the only node with room", got.Name, ok)
+	}
+
+	if _, ok := pickNode(nodes, usage, podRequesting("9")); ok {
+		t.Error("pickNode() found a node for a Pod larger than any node")
+	}
+}
+
+func TestPickNodeSelectorAndPods(t *testing.T) {
+	t.Parallel()
+
+	nodes := []nodeState{
+		newNodeState(Node{Name: "a", Capacity: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}}, 0),
+		newNodeState(Node{Name: "b", Labels: map[string]string{"pool": "gpu"}}, 1),
+	}
+
+	pod := podRequesting("1")
+	pod.Spec.NodeSelector = map[string]string{"pool": "gpu"}
+	if got, ok := pickNode(nodes, nil, pod); !ok || got.Name != "b" {
+		t.Errorf("pickNode() = %q, %v; want b, the only node matching the selector", got.Name, ok)
+	}
+
+	pod.Spec.NodeSelector = map[string]string{corev1.LabelHostname: "a"}
+	full := map[string]nodeUsage{"a": {pods: 1}}
+	if _, ok := pickNode(nodes, full, pod); ok {
+		t.Error("pickNode() placed a Pod on a node at its pods limit")
+	}
+}
+
+func TestFitsUnknownResource(t *testing.T) {
+	t.Parallel()
+
+	n := newNodeState(Node{Name: "a"}, 0)
+	req := corev1.ResourceList{"example.com/gpu": resource.MustParse("1")}
+	if fits(n, nodeUsage{}, nil, req) {
+		t.Error("fits() = true for a resource the node does not have")
+	}
+}
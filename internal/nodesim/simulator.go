@@ -0,0 +1,412 @@
+package nodesim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+)
+
+// registerTimeout bounds node and Lease registration in Start.
+const registerTimeout = 30 * time.Second
+
+// unregisterTimeout bounds Lease deletion in Stop.
+const unregisterTimeout = 10 * time.Second
+
+// minRequeueDelay is the shortest wait before a Pod is re-checked for its
+// next scripted transition, so sub-second rules do not spin the worker.
+const minRequeueDelay = 100 * time.Millisecond
+
+// schedulerName is the scheduler the simulator stands in for. Pods naming a
+// different scheduler are left for it.
+const schedulerName = corev1.DefaultSchedulerName
+
+// Simulator registers fake nodes, schedules Pods onto them and drives the
+// status of the Pods bound to them. Create it with Start and stop it with
+// Stop.
+type Simulator struct {
+	log            *slog.Logger
+	client         kubernetes.Interface
+	nodes          []nodeState
+	rules          []PodRule
+	kubeletVersion string
+
+	pods  cache.SharedIndexInformer
+	queue workqueue.TypedRateLimitingInterface[types.NamespacedName]
+
+	mu sync.Mutex
+	// assumed maps the UID of a Pod bound by the simulator to its node
+	// until the informer observes the binding, so capacity is not handed
+	// out twice in between.
+	assumed map[types.UID]string
+	podIPs  map[types.UID]string
+	nextIP  map[string]int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start registers nodes and their Leases, then starts watching Pods. It
+// returns once the nodes exist; scheduling begins when the Pod cache has
+// synced. rules script the lifetime of bound Pods (see PodRule). If logger
+// is nil, slog.Default() is used.
+func Start(cfg *rest.Config, nodes []Node, rules []PodRule, logger *slog.Logger) (*Simulator, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no fake nodes configured")
+	}
+	if err := errors.Join(ValidateNodes(nodes), ValidateRules(rules)); err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create clientset: %w", err)
+	}
+	version, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("get server version: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Simulator{
+		log:            logger,
+		client:         client,
+		rules:          rules,
+		kubeletVersion: version.GitVersion,
+		queue:          workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[types.NamespacedName]()),
+		assumed:        make(map[types.UID]string),
+		podIPs:         make(map[types.UID]string),
+		nextIP:         make(map[string]int),
+		cancel:         cancel,
+	}
+	for i, n := range nodes {
+		s.nodes = append(s.nodes, newNodeState(n, i))
+	}
+
+	if err := s.register(ctx); err != nil {
+		cancel()
+		s.queue.ShutDown()
+		s.deleteLeases()
+		return nil, err
+	}
+
+	s.pods = informers.NewSharedInformerFactory(client, 0).Core().V1().Pods().Informer()
+	_, err = s.pods.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    s.enqueue,
+		UpdateFunc: s.onUpdate,
+		DeleteFunc: s.onDelete,
+	})
+	if err != nil {
+		cancel()
+		s.queue.ShutDown()
+		s.deleteLeases()
+		return nil, fmt.Errorf("add pod event handler: %w", err)
+	}
+
+	s.wg.Go(func() { s.pods.RunWithContext(ctx) })
+	s.wg.Go(func() { s.renewLeases(ctx) })
+	s.wg.Go(func() {
+		if cache.WaitForCacheSync(ctx.Done(), s.pods.HasSynced) {
+			s.runWorker(ctx)
+		}
+	})
+
+	s.log.Debug("node simulator started", "nodes", len(s.nodes))
+	return s, nil
+}
+
+// Stop stops watching Pods and renewing Leases, waits for the workers to
+// exit and deletes the node Leases. The Leases live in kube-node-lease, a
+// system namespace that purges preserve, so nothing else would remove them.
+// The Node objects are left in place; they are removed by the next purge
+// like any other object.
+func (s *Simulator) Stop() {
+	s.cancel()
+	s.queue.ShutDown()
+	s.wg.Wait()
+	s.deleteLeases()
+}
+
+// register creates every node and its Lease.
+func (s *Simulator) register(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, registerTimeout)
+	defer cancel()
+	for _, n := range s.nodes {
+		if err := s.registerNode(ctx, n); err != nil {
+			return err
+		}
+		if err := s.renewLease(ctx, n.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// node returns the simulated node called name.
+func (s *Simulator) node(name string) (nodeState, bool) {
+	for _, n := range s.nodes {
+		if n.Name == name {
+			return n, true
+		}
+	}
+	return nodeState{}, false
+}
+
+// enqueue queues a Pod for scheduling or a status update.
+func (s *Simulator) enqueue(obj any) {
+	if pod, ok := obj.(*corev1.Pod); ok {
+		s.queue.Add(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+	}
+}
+
+// onUpdate queues the Pod and, when it has just finished, the pending Pods
+// that may fit into the capacity it frees.
+func (s *Simulator) onUpdate(oldObj, newObj any) {
+	s.enqueue(newObj)
+	oldPod, ok1 := oldObj.(*corev1.Pod)
+	newPod, ok2 := newObj.(*corev1.Pod)
+	if ok1 && ok2 && !isTerminal(oldPod) && isTerminal(newPod) {
+		s.enqueuePending()
+	}
+}
+
+// onDelete forgets the Pod and queues the pending Pods that may fit into
+// the capacity it frees.
+func (s *Simulator) onDelete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pod, ok := obj.(*corev1.Pod); ok {
+		s.mu.Lock()
+		delete(s.assumed, pod.UID)
+		delete(s.podIPs, pod.UID)
+		s.mu.Unlock()
+	}
+	s.enqueuePending()
+}
+
+// enqueuePending queues every cached Pod that is waiting to be scheduled.
+func (s *Simulator) enqueuePending() {
+	for _, obj := range s.pods.GetStore().List() {
+		if pod, ok := obj.(*corev1.Pod); ok && pod.Spec.NodeName == "" {
+			s.enqueue(pod)
+		}
+	}
+}
+
+// runWorker processes queued Pods until the queue is shut down. A failed
+// sync is retried with rate-limited backoff; a Pod with a scripted
+// transition ahead is re-checked when it is due.
+func (s *Simulator) runWorker(ctx context.Context) {
+	for {
+		key, shutdown := s.queue.Get()
+		if shutdown {
+			return
+		}
+		after, err := s.sync(ctx, key)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				s.log.Debug("nodesim: sync failed, retrying", "pod", key.String(), "error", err)
+			}
+			s.queue.AddRateLimited(key)
+		case after > 0:
+			s.queue.Forget(key)
+			s.queue.AddAfter(key, max(after, minRequeueDelay))
+		default:
+			s.queue.Forget(key)
+		}
+		s.queue.Done(key)
+	}
+}
+
+// sync schedules the Pod if it is pending, or brings the status of a Pod
+// bound to a simulated node up to date. It returns how long until the Pod
+// needs to be checked again.
+func (s *Simulator) sync(ctx context.Context, key types.NamespacedName) (time.Duration, error) {
+	obj, exists, err := s.pods.GetStore().GetByKey(key.String())
+	if err != nil || !exists {
+		return 0, err
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return 0, nil
+	}
+
+	if pod.Spec.NodeName == "" {
+		if pod.DeletionTimestamp != nil || pod.Spec.SchedulerName != schedulerName {
+			return 0, nil
+		}
+		return 0, s.schedule(ctx, pod)
+	}
+
+	s.mu.Lock()
+	delete(s.assumed, pod.UID)
+	s.mu.Unlock()
+
+	node, ok := s.node(pod.Spec.NodeName)
+	if !ok {
+		return 0, nil
+	}
+	if pod.DeletionTimestamp != nil {
+		return 0, s.deletePod(ctx, pod)
+	}
+	if isTerminal(pod) {
+		return 0, nil
+	}
+
+	rule := ruleFor(s.rules, pod.Labels)
+	status, next := desiredStatus(pod, rule, s.addresses(pod, node), time.Now())
+	if equality.Semantic.DeepEqual(pod.Status, status) {
+		return next, nil
+	}
+	updated := pod.DeepCopy()
+	updated.Status = status
+	if _, err := s.client.CoreV1().Pods(pod.Namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("update status of pod %s: %w", key, err)
+	}
+	return next, nil
+}
+
+// schedule binds pod to the node pickNode selects, or marks it
+// unschedulable if it fits nowhere. It is requeued when capacity frees up.
+func (s *Simulator) schedule(ctx context.Context, pod *corev1.Pod) error {
+	node, ok := pickNode(s.nodes, s.usage(), pod)
+	if !ok {
+		return s.markUnschedulable(ctx, pod)
+	}
+
+	s.mu.Lock()
+	s.assumed[pod.UID] = node.Name
+	s.mu.Unlock()
+
+	err := s.client.CoreV1().Pods(pod.Namespace).Bind(ctx, &corev1.Binding{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID},
+		Target:     corev1.ObjectReference{Kind: "Node", Name: node.Name},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		s.mu.Lock()
+		delete(s.assumed, pod.UID)
+		s.mu.Unlock()
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("bind pod %s/%s to node %s: %w", pod.Namespace, pod.Name, node.Name, err)
+	}
+	s.log.Debug("nodesim: scheduled pod", "pod", pod.Namespace+"/"+pod.Name, "node", node.Name)
+	return nil
+}
+
+// usage sums the requests of the unfinished Pods on each simulated node,
+// including Pods bound but not yet observed bound.
+func (s *Simulator) usage() map[string]nodeUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := make(map[string]nodeUsage, len(s.nodes))
+	for _, obj := range s.pods.GetStore().List() {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || isTerminal(pod) {
+			continue
+		}
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			nodeName = s.assumed[pod.UID]
+		}
+		if nodeName == "" {
+			continue
+		}
+		u := usage[nodeName]
+		u.add(podRequests(pod))
+		usage[nodeName] = u
+	}
+	return usage
+}
+
+// markUnschedulable sets the PodScheduled condition to False, as the
+// scheduler does for a Pod that fits on no node.
+func (s *Simulator) markUnschedulable(ctx context.Context, pod *corev1.Pod) error {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			return nil
+		}
+	}
+	updated := pod.DeepCopy()
+	updated.Status.Conditions = setCondition(updated.Status.Conditions, corev1.PodScheduled, false,
+		corev1.PodReasonUnschedulable, time.Now())
+	for i, c := range updated.Status.Conditions {
+		if c.Type == corev1.PodScheduled {
+			updated.Status.Conditions[i].Message = fmt.Sprintf(
+				"0/%d nodes are available: insufficient resources or node selector mismatch.", len(s.nodes))
+		}
+	}
+	_, err := s.client.CoreV1().Pods(pod.Namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("mark pod %s/%s unschedulable: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// deletePod completes the graceful deletion of a Pod on a simulated node.
+// Nothing runs, so there is nothing to wait for.
+func (s *Simulator) deletePod(ctx context.Context, pod *corev1.Pod) error {
+	err := s.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+		GracePeriodSeconds: ptr.To[int64](0),
+		Preconditions:      &metav1.Preconditions{UID: &pod.UID},
+	})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// addresses returns the host and Pod IP of pod on node. Pod IPs are
+// allocated from the node's Pod CIDR and kept for the Pod's lifetime;
+// host-network Pods share the node's address.
+func (s *Simulator) addresses(pod *corev1.Pod, node nodeState) podAddresses {
+	addr := podAddresses{hostIP: node.hostIP, podIP: pod.Status.PodIP}
+	if pod.Spec.HostNetwork {
+		addr.podIP = node.hostIP
+	}
+	if addr.podIP != "" {
+		return addr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ip, ok := s.podIPs[pod.UID]; ok {
+		addr.podIP = ip
+		return addr
+	}
+	// Host addresses 2-254 of the /24, wrapping around for long runs.
+	n := s.nextIP[node.Name]
+	s.nextIP[node.Name] = (n + 1) % 253
+	addr.podIP = fmt.Sprintf("%s.%d", node.podSubnet, n+2)
+	s.podIPs[pod.UID] = addr.podIP
+	return addr
+}
+
+// isTerminal reports whether pod has finished.
+func isTerminal(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
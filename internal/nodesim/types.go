@@ -0,0 +1,103 @@
+package nodesim
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Node describes a fake node to register.
+type Node struct {
+	// Name is the Node name. Required and unique.
+	Name string
+
+	// Labels are added to the well-known kubernetes.io/hostname, os and arch
+	// labels. Pods select nodes with nodeSelector against these.
+	Labels map[string]string
+
+	// Capacity is reported as both capacity and allocatable. Resources not
+	// listed are taken from DefaultCapacity.
+	Capacity corev1.ResourceList
+}
+
+// PodRule scripts the simulated lifetime of the Pods it selects. The first
+// rule whose Selector matches a Pod applies; Pods matching no rule become
+// Ready immediately and run until deleted.
+type PodRule struct {
+	// Selector matches Pod labels. Empty matches every Pod.
+	Selector map[string]string
+
+	// ReadyAfter is how long containers run before the Pod becomes Ready.
+	ReadyAfter time.Duration
+
+	// RunFor is how long containers run before exiting with ExitCode. Zero
+	// runs them until the Pod is deleted. Whether an exited Pod restarts
+	// follows its restartPolicy: Never ends in Succeeded or Failed,
+	// OnFailure restarts on a non-zero exit code, Always always restarts.
+	RunFor time.Duration
+
+	// ExitCode is the exit code of every container when RunFor elapses.
+	ExitCode int32
+}
+
+// DefaultCapacity is the capacity of a node for resources not set in
+// Node.Capacity.
+var DefaultCapacity = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("8"),
+	corev1.ResourceMemory: resource.MustParse("32Gi"),
+	corev1.ResourcePods:   resource.MustParse("110"),
+}
+
+// DefaultNodeName is the name of the node registered when none is configured.
+const DefaultNodeName = "k8senv-node"
+
+// ValidateNodes checks that every node has a unique, non-empty name and
+// non-negative capacity.
+func ValidateNodes(nodes []Node) error {
+	var errs []error
+	seen := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		if n.Name == "" {
+			errs = append(errs, errors.New("fake node name must not be empty"))
+			continue
+		}
+		if seen[n.Name] {
+			errs = append(errs, fmt.Errorf("duplicate fake node %q", n.Name))
+		}
+		seen[n.Name] = true
+		for name, q := range n.Capacity {
+			if q.Sign() < 0 {
+				errs = append(errs, fmt.Errorf("fake node %q: capacity %s must not be negative", n.Name, name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateRules checks that durations are non-negative and selectors valid.
+func ValidateRules(rules []PodRule) error {
+	var errs []error
+	for i, r := range rules {
+		if r.ReadyAfter < 0 || r.RunFor < 0 {
+			errs = append(errs, fmt.Errorf("pod rule %d: durations must not be negative", i))
+		}
+		if _, err := labels.ValidatedSelectorFromSet(r.Selector); err != nil {
+			errs = append(errs, fmt.Errorf("pod rule %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ruleFor returns the first rule matching pod's labels, or the zero rule.
+func ruleFor(rules []PodRule, podLabels map[string]string) PodRule {
+	for _, r := range rules {
+		if labels.SelectorFromSet(r.Selector).Matches(labels.Set(podLabels)) {
+			return r
+		}
+	}
+	return PodRule{}
+}
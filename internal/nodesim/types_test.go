@@ -0,0 +1,87 @@
+package nodesim
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestValidateNodes(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateNodes([]Node{{Name: "a"}, {Name: "b"}}); err != nil {
+		t.Errorf("ValidateNodes(valid) = %v", err)
+	}
+	for name, nodes := range map[string][]Node{
+		"empty name": {{}},
+		"duplicate":  {{Name: "a"}, {Name: "a"}},
+		"negative":   {{Name: "a", Capacity: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("-1")}}},
+	} {
+		if err := ValidateNodes(nodes); err == nil {
+			t.Errorf("ValidateNodes(%s) = nil, want error", name)
+		}
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateRules([]PodRule{{Selector: map[string]string{"app": "web"}, RunFor: time.Second}}); err != nil {
+		t.Errorf("ValidateRules(valid) = %v", err)
+	}
+	for name, rule := range map[string]PodRule{
+		"negative duration": {ReadyAfter: -time.Second},
+		"invalid selector":  {Selector: map[string]string{"bad key!": "x"}},
+	} {
+		if err := ValidateRules([]PodRule{rule}); err == nil {
+			t.Errorf("ValidateRules(%s) = nil, want error", name)
+		}
+	}
+}
+
+func TestRuleFor(t *testing.T) {
+	t.Parallel()
+
+	rules := []PodRule{
+		{Selector: map[string]string{"app": "job"}, ExitCode: 1},
+		{ExitCode: 2},
+	}
+	if got := ruleFor(rules, map[string]string{"app": "job"}); got.ExitCode != 1 {
+		t.Errorf("ruleFor(app=job).ExitCode = %d, want 1", got.ExitCode)
+	}
+	if got := ruleFor(rules, map[string]string{"app": "web"}); got.ExitCode != 2 {
+		t.Errorf("ruleFor(app=web).ExitCode = %d, want 2 (catch-all)", got.ExitCode)
+	}
+	if got := ruleFor(nil, nil); got.Selector != nil || got.RunFor != 0 || got.ExitCode != 0 {
+		t.Errorf("ruleFor(no rules) = %+v, want zero rule", got)
+	}
+}
+
+func TestNodeObject(t *testing.T) {
+	t.Parallel()
+
+	n := newNodeState(Node{
+		Name:     "worker",
+		Labels:   map[string]string{"pool": "a"},
+		Capacity: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+	}, 3)
+	obj := n.object("v1.36.0")
+
+	if obj.Labels[corev1.LabelHostname] != "worker" || obj.Labels["pool"] != "a" {
+		t.Errorf("labels = %v, want hostname and pool", obj.Labels)
+	}
+	if cpu := obj.Status.Allocatable[corev1.ResourceCPU]; cpu.String() != "2" {
+		t.Errorf("allocatable cpu = %s, want 2", cpu.String())
+	}
+	if mem := obj.Status.Capacity[corev1.ResourceMemory]; mem.Cmp(DefaultCapacity[corev1.ResourceMemory]) != 0 {
+		t.Errorf("capacity memory = %s, want default", mem.String())
+	}
+	if obj.Spec.PodCIDR != "10.244.3.0/24" || obj.Status.Addresses[0].Address != "10.0.0.4" {
+		t.Errorf("podCIDR %s, address %s; want 10.244.3.0/24, 10.0.0.4", obj.Spec.PodCIDR, obj.Status.Addresses[0].Address)
+	}
+	if obj.Status.NodeInfo.KubeletVersion != "v1.36.0" {
+		t.Errorf("kubelet version = %s, want v1.36.0", obj.Status.NodeInfo.KubeletVersion)
+	}
+}
@@ -0,0 +1,9 @@
+// Package oidc provides a minimal OpenID Connect issuer for exercising the
+// kube-apiserver JWT authenticator.
+//
+// An Issuer serves the OIDC discovery document and a JWKS containing a single
+// ECDSA P-256 key over HTTPS on a loopback listener, using a self-signed
+// certificate that kube-apiserver is configured to trust. Tokens signed by
+// the Issuer are accepted by any apiserver whose AuthenticationConfiguration
+// lists the Issuer's URL and CA.
+package oidc
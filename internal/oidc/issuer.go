@@ -0,0 +1,278 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+)
+
+// keyID is the "kid" of the single signing key published in the JWKS.
+const keyID = "k8senv"
+
+// jwksPath is the path of the JWKS document advertised via jwks_uri.
+const jwksPath = "/openid/v1/jwks"
+
+// discoveryPath is the well-known OIDC discovery path relative to the issuer.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// DefaultTokenLifetime is the validity of tokens whose claims do not set
+// "exp" explicitly.
+const DefaultTokenLifetime = time.Hour
+
+// certLifetime is the validity of the self-signed serving certificate. It
+// only has to outlive a test run.
+const certLifetime = 24 * time.Hour
+
+// readHeaderTimeout bounds how long the server waits for request headers.
+const readHeaderTimeout = 10 * time.Second
+
+// shutdownTimeout bounds how long Close waits for in-flight requests.
+const shutdownTimeout = 5 * time.Second
+
+// Issuer is a loopback OIDC issuer. It is safe for concurrent use.
+type Issuer struct {
+	listener net.Listener
+	server   *http.Server
+	log      *slog.Logger
+
+	url    string
+	caPEM  []byte
+	key    *ecdsa.PrivateKey
+	jwks   []byte
+	config []byte
+
+	// serveDone is closed when the Serve goroutine returns.
+	serveDone chan struct{}
+}
+
+// NewIssuer generates a signing key and a self-signed serving certificate
+// for 127.0.0.1, and starts serving discovery and JWKS on an ephemeral
+// loopback port. If logger is nil, slog.Default() is used.
+func NewIssuer(logger *slog.Logger) (*Issuer, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate signing key: %w", err)
+	}
+	cert, caPEM, err := selfSignedCert()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen for oidc issuer: %w", err)
+	}
+
+	iss := &Issuer{
+		listener:  listener,
+		log:       logger,
+		url:       "https://" + listener.Addr().String(),
+		caPEM:     caPEM,
+		key:       signingKey,
+		serveDone: make(chan struct{}),
+	}
+	if iss.jwks, err = marshalJWKS(&signingKey.PublicKey); err != nil {
+		listener.Close() //nolint:errcheck,gosec // best-effort cleanup on setup failure
+		return nil, err
+	}
+	if iss.config, err = json.Marshal(discoveryDocument{
+		Issuer:           iss.url,
+		JWKSURI:          iss.url + jwksPath,
+		ResponseTypes:    []string{"id_token"},
+		SubjectTypes:     []string{"public"},
+		SigningAlgValues: []string{"ES256"},
+	}); err != nil {
+		listener.Close() //nolint:errcheck,gosec // best-effort cleanup on setup failure
+		return nil, fmt.Errorf("marshal discovery document: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+discoveryPath, iss.serveJSON(iss.config))
+	mux.HandleFunc("GET "+jwksPath, iss.serveJSON(iss.jwks))
+	iss.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+
+	go func() {
+		defer close(iss.serveDone)
+		if serveErr := iss.server.ServeTLS(listener, "", ""); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			iss.log.Warn("oidc issuer stopped unexpectedly", "error", serveErr)
+		}
+	}()
+
+	return iss, nil
+}
+
+// URL returns the issuer URL, which is also the "iss" claim of issued tokens.
+func (i *Issuer) URL() string {
+	return i.url
+}
+
+// CAPEM returns the PEM-encoded certificate that kube-apiserver must trust
+// to fetch discovery and JWKS from the issuer.
+func (i *Issuer) CAPEM() []byte {
+	return i.caPEM
+}
+
+// Token returns a signed ES256 JWT carrying claims. The "iss", "aud", "iat"
+// and "exp" claims are filled in when absent: iss with URL, aud with
+// audience, and exp DefaultTokenLifetime after iat. claims is not modified.
+func (i *Issuer) Token(audience string, claims map[string]any) (string, error) {
+	now := time.Now()
+	payload := make(map[string]any, len(claims)+4)
+	payload["iss"] = i.url
+	payload["aud"] = audience
+	payload["iat"] = now.Unix()
+	payload["exp"] = now.Add(DefaultTokenLifetime).Unix()
+	for k, v := range claims {
+		payload[k] = v
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "kid": keyID, "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("marshal token header: %w", err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal token claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, i.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+
+	// JWS ES256 signatures are the fixed-width concatenation R || S.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Close stops the listener and waits for in-flight requests to complete,
+// bounded by shutdownTimeout.
+func (i *Issuer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := i.server.Shutdown(ctx)
+	<-i.serveDone
+	if err != nil {
+		return fmt.Errorf("shutdown oidc issuer: %w", err)
+	}
+	return nil
+}
+
+// serveJSON returns a handler that writes a pre-rendered JSON document.
+func (i *Issuer) serveJSON(doc []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(doc); err != nil {
+			i.log.Debug("write oidc response", "error", err)
+		}
+	}
+}
+
+// discoveryDocument is the subset of the OIDC provider metadata consumed by
+// kube-apiserver.
+type discoveryDocument struct {
+	Issuer           string   `json:"issuer"`
+	JWKSURI          string   `json:"jwks_uri"`
+	ResponseTypes    []string `json:"response_types_supported"`
+	SubjectTypes     []string `json:"subject_types_supported"`
+	SigningAlgValues []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// jsonWebKey is an EC public key in JWK form (RFC 7517).
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// marshalJWKS renders a JWK Set containing pub.
+func marshalJWKS(pub *ecdsa.PublicKey) ([]byte, error) {
+	// Bytes returns the uncompressed point 0x04 || X || Y.
+	point, err := pub.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("encode signing key: %w", err)
+	}
+	coord := (len(point) - 1) / 2
+	data, err := json.Marshal(map[string][]jsonWebKey{"keys": {{
+		KeyType:   "EC",
+		Curve:     "P-256",
+		X:         base64.RawURLEncoding.EncodeToString(point[1 : 1+coord]),
+		Y:         base64.RawURLEncoding.EncodeToString(point[1+coord:]),
+		Use:       "sig",
+		Algorithm: "ES256",
+		KeyID:     keyID,
+	}}})
+	if err != nil {
+		return nil, fmt.Errorf("marshal jwks: %w", err)
+	}
+	return data, nil
+}
+
+// selfSignedCert creates a self-signed serving certificate for 127.0.0.1 and
+// returns it together with its PEM encoding. The certificate is its own CA,
+// so the PEM can be used directly as the trust anchor.
+func selfSignedCert() (tls.Certificate, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("generate serving key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("generate serial number: %w", err)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "k8senv-oidc-issuer"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(certLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("create serving certificate: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, certPEM, nil
+}
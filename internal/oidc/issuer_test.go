@@ -0,0 +1,99 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newTestIssuer(t *testing.T) *Issuer {
+	t.Helper()
+	iss, err := NewIssuer(nil)
+	if err != nil {
+		t.Fatalf("NewIssuer() error: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := iss.Close(); err != nil {
+			t.Errorf("Close() error: %v", err)
+		}
+	})
+	return iss
+}
+
+// TestIssuerDiscovery verifies that discovery is served over TLS trusted by
+// CAPEM and advertises the issuer URL.
+func TestIssuerDiscovery(t *testing.T) {
+	t.Parallel()
+	iss := newTestIssuer(t)
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(iss.CAPEM()) {
+		t.Fatal("CAPEM() is not a valid PEM certificate")
+	}
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+	}}
+
+	resp, err := client.Get(iss.URL() + discoveryPath)
+	if err != nil {
+		t.Fatalf("GET discovery: %v", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // test cleanup
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode discovery: %v", err)
+	}
+	if doc.Issuer != iss.URL() || doc.JWKSURI != iss.URL()+jwksPath {
+		t.Errorf("discovery = %+v, want issuer %q", doc, iss.URL())
+	}
+}
+
+// TestIssuerToken verifies the default claims, caller overrides and the
+// ES256 signature of issued tokens.
+func TestIssuerToken(t *testing.T) {
+	t.Parallel()
+	iss := newTestIssuer(t)
+
+	token, err := iss.Token("k8senv", map[string]any{"sub": "alice", "aud": "custom"})
+	if err != nil {
+		t.Fatalf("Token() error: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(body, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims["iss"] != iss.URL() || claims["sub"] != "alice" || claims["aud"] != "custom" {
+		t.Errorf("claims = %v, want iss=%q sub=alice aud=custom", claims, iss.URL())
+	}
+	if _, ok := claims["exp"]; !ok {
+		t.Error("claims missing default exp")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&iss.key.PublicKey, digest[:], r, s) {
+		t.Error("token signature does not verify")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"syscall"
 	"time"
 
+	"github.com/giantswarm/k8senv/internal/logstream"
 	"github.com/giantswarm/k8senv/internal/sentinel"
 )
 
@@ -22,6 +24,17 @@ const ErrEmptyCmdPath = sentinel.Error("cmd.Path must not be empty")
 // ErrEmptyDataDir is returned when SetupAndStart is called with an empty data directory.
 const ErrEmptyDataDir = sentinel.Error("data directory must not be empty")
 
+// LogStream configures re-emitting a process's output through its logger as
+// it arrives, in addition to writing it to the log files. The zero value
+// disables streaming.
+type LogStream struct {
+	// Enabled turns streaming on.
+	Enabled bool
+	// MinLevel drops lines below this level (after mapping klog and logrus
+	// levels onto slog levels).
+	MinLevel slog.Level
+}
+
 // BaseProcess provides common process lifecycle management.
 // Embed this in package-specific Process types to reuse Stop and Close methods.
 //
@@ -37,6 +50,8 @@ type BaseProcess struct {
 	name        string        // Process name for logging (e.g., "kine", "kube-apiserver")
 	log         *slog.Logger  // Logger for operational messages
 	stopTimeout time.Duration // Timeout for auto-stop in Close; guaranteed positive by NewBaseProcess
+	logStream   LogStream     // Streaming of output through log; set by SetLogStream
+	logRotation LogRotation   // Size cap of the log files; set by SetLogRotation
 }
 
 // NewBaseProcess creates a BaseProcess with the given name, logger, and stop
@@ -59,6 +74,18 @@ func NewBaseProcess(name string, logger *slog.Logger, stopTimeout time.Duration)
 	return BaseProcess{name: name, log: logger, stopTimeout: stopTimeout}
 }
 
+// SetLogStream configures streaming of the process output through the
+// logger for subsequent calls to SetupAndStart.
+func (b *BaseProcess) SetLogStream(ls LogStream) {
+	b.logStream = ls
+}
+
+// SetLogRotation configures the size cap of the log files for subsequent
+// calls to SetupAndStart.
+func (b *BaseProcess) SetLogRotation(r LogRotation) {
+	b.logRotation = r
+}
+
 // Stop terminates the process with the given timeout.
 // After Stop returns, IsStarted reports false regardless of whether the stop
 // succeeded, because the process is no longer in a known-running state.
@@ -128,6 +155,28 @@ func (b *BaseProcess) Exited() <-chan struct{} {
 	return b.exited
 }
 
+// Name returns the process name, which also prefixes its log files.
+func (b *BaseProcess) Name() string {
+	return b.name
+}
+
+// ExitCode returns the exit code of a process that has exited on its own, or
+// 128 plus the signal number if a signal terminated it, as shells report it
+// (137 for SIGKILL, e.g. by the OOM killer). It returns -1 if the process has
+// not been started, is still running, or has been stopped.
+func (b *BaseProcess) ExitCode() int {
+	select {
+	case <-b.exited:
+	default:
+		return -1 // includes b.exited == nil
+	}
+	state := b.cmd.ProcessState
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return state.ExitCode()
+}
+
 // IsStarted reports whether the process has been started and not yet stopped.
 func (b *BaseProcess) IsStarted() bool {
 	return b.cmd != nil
@@ -136,6 +185,8 @@ func (b *BaseProcess) IsStarted() bool {
 // SetupAndStart creates log files, sets up stdout/stderr, and starts the command.
 // The cmd must already have its Path and Args set. This sets Dir, Stdout, Stderr
 // and calls Start(). On success, cmd, waitDone, and logFiles are populated.
+// If a LogStream is enabled, the output is also teed into the logger. With a
+// LogRotation, the log files are rotated once they reach its size cap.
 //
 // A single goroutine calling cmd.Wait is started here so that exactly one Wait
 // call is made per process. The resulting channel is consumed by Stop.
@@ -159,7 +210,19 @@ func (b *BaseProcess) SetupAndStart(cmd *exec.Cmd, dataDir string) error {
 	cmd.Dir = dataDir
 	configureSysProcAttr(cmd)
 
-	logFiles, err := startCmd(cmd, dataDir, b.name)
+	// Each stream gets its own writer so that a partial line on one is not
+	// joined with a line on the other. The writers are closed once cmd.Wait
+	// has returned, which guarantees all output has been copied into them,
+	// to emit a final unterminated line.
+	var streams []*logstream.Writer
+	if b.logStream.Enabled {
+		streams = []*logstream.Writer{
+			logstream.NewWriter(b.log, b.name, b.logStream.MinLevel),
+			logstream.NewWriter(b.log, b.name, b.logStream.MinLevel),
+		}
+	}
+
+	logFiles, err := startCmd(cmd, dataDir, b.name, b.logRotation, streams)
 	if err != nil {
 		return fmt.Errorf("start command: %w", err)
 	}
@@ -178,7 +241,11 @@ func (b *BaseProcess) SetupAndStart(cmd *exec.Cmd, dataDir string) error {
 	done := make(chan error, 1)
 	exited := make(chan struct{})
 	go func() {
-		done <- cmd.Wait()
+		err := cmd.Wait()
+		for _, w := range streams {
+			_ = w.Close()
+		}
+		done <- err
 		close(exited)
 	}()
 	b.waitDone = done
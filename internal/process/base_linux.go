@@ -7,12 +7,18 @@ import (
 	"syscall"
 )
 
-// configureSysProcAttr sets Linux-specific process attributes on cmd.
+// PdeathsigSupported reports whether child processes are signalled when the
+// test process dies.
+const PdeathsigSupported = true
+
+// configureSysProcAttr sets Linux-specific process attributes on cmd,
+// keeping any set by the caller (such as namespace flags).
 // Pdeathsig ensures the child process receives SIGTERM when its parent dies,
 // preventing orphaned kine and kube-apiserver processes if the test binary
 // is killed abruptly.
 func configureSysProcAttr(cmd *exec.Cmd) {
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Pdeathsig: syscall.SIGTERM,
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
 	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGTERM
 }
@@ -4,6 +4,10 @@ package process
 
 import "os/exec"
 
+// PdeathsigSupported reports whether child processes are signalled when the
+// test process dies.
+const PdeathsigSupported = false
+
 // configureSysProcAttr is a no-op on non-Linux platforms.
 // Pdeathsig (parent-death signal) is a Linux-only kernel feature.
 func configureSysProcAttr(_ *exec.Cmd) {}
@@ -3,11 +3,15 @@ package process
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
+
+	"github.com/giantswarm/k8senv/internal/logstream"
 )
 
 // noCopy prevents logFiles from being copied after it holds open file handles.
@@ -30,9 +34,9 @@ func (*noCopy) Unlock() {}
 // logFiles must not be copied after creation; use a pointer when passing or
 // storing it to avoid aliasing the underlying file descriptors.
 type logFiles struct {
-	noCopy     noCopy // sentinel: go vet copylocks flags any copy of this struct
-	stdoutFile *os.File
-	stderrFile *os.File
+	noCopy     noCopy         // sentinel: go vet copylocks flags any copy of this struct
+	stdoutFile io.WriteCloser // *os.File, or *rotatingFile with LogRotation
+	stderrFile io.WriteCloser
 }
 
 // Close closes both log file handles and nils them to prevent double-close.
@@ -49,18 +53,24 @@ func (l *logFiles) Close() {
 
 // newLogFiles creates and initializes log files for a process.
 // The processName is used to generate log file names (e.g., "kine" -> "kine-stdout.log").
+// With rotation enabled, the files are rotating sinks the process writes to
+// through a pipe; otherwise the process writes to them directly.
 // Returns a pointer to prevent copying the file handles.
-func newLogFiles(dataDir, processName string) (*logFiles, error) {
-	stdoutPath := filepath.Join(dataDir, processName+"-stdout.log")
-	stderrPath := filepath.Join(dataDir, processName+"-stderr.log")
+func newLogFiles(dataDir, processName string, rotation LogRotation) (*logFiles, error) {
+	stdoutPath := filepath.Join(dataDir, StdoutFile(processName))
+	stderrPath := filepath.Join(dataDir, StderrFile(processName))
 
-	//nolint:gosec // paths from controlled config, not user input
-	stdoutFile, err := os.Create(stdoutPath)
+	create := func(path string) (io.WriteCloser, error) {
+		if rotation.Enabled() {
+			return newRotatingFile(path, rotation)
+		}
+		return os.Create(path) //nolint:gosec // paths from controlled config, not user input
+	}
+	stdoutFile, err := create(stdoutPath)
 	if err != nil {
 		return nil, fmt.Errorf("create stdout log: %w", err)
 	}
-	//nolint:gosec // paths from controlled config, not user input
-	stderrFile, err := os.Create(stderrPath)
+	stderrFile, err := create(stderrPath)
 	if err != nil {
 		_ = stdoutFile.Close()
 		return nil, fmt.Errorf("create stderr log: %w", err)
@@ -68,6 +78,50 @@ func newLogFiles(dataDir, processName string) (*logFiles, error) {
 	return &logFiles{stdoutFile: stdoutFile, stderrFile: stderrFile}, nil
 }
 
+// StdoutFile returns the name of the file, relative to the data directory,
+// receiving the standard output of processName.
+func StdoutFile(processName string) string {
+	return processName + "-stdout.log"
+}
+
+// StderrFile returns the name of the file, relative to the data directory,
+// receiving the standard error of processName.
+func StderrFile(processName string) string {
+	return processName + "-stderr.log"
+}
+
+// CmdlineFile returns the name of the file, relative to the data directory,
+// in which startCmd records the command line of processName.
+func CmdlineFile(processName string) string {
+	return processName + "-cmdline.txt"
+}
+
+// writeCmdline records args as a single shell-quoted line next to the
+// process logs, so that a failed startup can be reproduced by hand.
+func writeCmdline(dataDir, processName string, args []string) error {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	path := filepath.Join(dataDir, CmdlineFile(processName))
+	if err := os.WriteFile(path, []byte(strings.Join(quoted, " ")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write %s command line: %w", processName, err)
+	}
+	return nil
+}
+
+// shellQuote returns arg unchanged if it consists only of characters that
+// need no quoting in a POSIX shell, and single-quoted otherwise.
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, shellSafe) == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// shellSafe lists the characters shellQuote leaves unquoted.
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=+.,/:@%"
+
 // DefaultStopTimeout is the default timeout for stopping a process. It is used
 // as a fallback by packages that manage process lifecycle (kubestack, crdcache)
 // when no explicit stop timeout is configured.
@@ -243,17 +297,29 @@ func expectSignalExit(err error, name string) error {
 	return fmt.Errorf("%s: %w", name, err)
 }
 
-// startCmd creates log files, sets up stdout/stderr, and starts the command.
+// startCmd creates log files, rotated according to rotation, sets up
+// stdout/stderr, and starts the command.
+// If tees holds two writers, stdout and stderr are also written to the first
+// and second respectively.
 // On success, caller owns the logFiles. On failure, log files are closed automatically.
 // Returns a pointer to prevent copying the file handles.
-func startCmd(cmd *exec.Cmd, dataDir, processName string) (*logFiles, error) {
-	logFiles, err := newLogFiles(dataDir, processName)
+func startCmd(cmd *exec.Cmd, dataDir, processName string, rotation LogRotation, tees []*logstream.Writer) (*logFiles, error) {
+	logFiles, err := newLogFiles(dataDir, processName, rotation)
 	if err != nil {
 		return nil, fmt.Errorf("create %s logs: %w", processName, err)
 	}
 
 	cmd.Stdout = logFiles.stdoutFile
 	cmd.Stderr = logFiles.stderrFile
+	if len(tees) == 2 {
+		cmd.Stdout = io.MultiWriter(logFiles.stdoutFile, tees[0])
+		cmd.Stderr = io.MultiWriter(logFiles.stderrFile, tees[1])
+	}
+
+	if err := writeCmdline(dataDir, processName, cmd.Args); err != nil {
+		logFiles.Close()
+		return nil, err
+	}
 
 	if err := cmd.Start(); err != nil {
 		logFiles.Close()
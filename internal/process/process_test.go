@@ -1,10 +1,15 @@
 package process
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -182,6 +187,40 @@ func TestDrainReserve(t *testing.T) {
 	}
 }
 
+func TestShellQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"--secure-port=6443":      "--secure-port=6443",
+		"/usr/bin/kube-apiserver": "/usr/bin/kube-apiserver",
+		"":                        "''",
+		"sqlite:///tmp/db?_x=5":   "'sqlite:///tmp/db?_x=5'",
+		"a b":                     "'a b'",
+		"it's":                    `'it'\''s'`,
+	}
+	for arg, want := range tests {
+		if got := shellQuote(arg); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", arg, got, want)
+		}
+	}
+}
+
+func TestWriteCmdline(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := writeCmdline(dir, "kine", []string{"kine", "--listen-address=127.0.0.1:1", "a b"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, CmdlineFile("kine")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "kine --listen-address=127.0.0.1:1 'a b'\n"; string(got) != want {
+		t.Errorf("command line = %q, want %q", got, want)
+	}
+}
+
 func TestNewBaseProcess(t *testing.T) {
 	t.Parallel()
 
@@ -447,3 +486,89 @@ func makeSignalExitError(tb testing.TB, sig syscall.Signal) *exec.ExitError {
 
 	return exitErr
 }
+
+func TestBaseProcess_ExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		script string
+		want   int
+	}{
+		"exit status":   {script: "exit 3", want: 3},
+		"killed by sig": {script: "kill -9 $$", want: 137},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bp := NewBaseProcess("test-proc", nil, 0)
+			if got := bp.ExitCode(); got != -1 {
+				t.Errorf("ExitCode() before start = %d, want -1", got)
+			}
+			if err := bp.SetupAndStart(exec.Command("sh", "-c", tc.script), t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = bp.Stop(5 * time.Second)
+				bp.Close()
+			})
+
+			<-bp.Exited()
+			if got := bp.ExitCode(); got != tc.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBaseProcess_LogStream(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	bp := NewBaseProcess("test-proc", logger, 0)
+	bp.SetLogStream(LogStream{Enabled: true, MinLevel: slog.LevelWarn})
+
+	dataDir := t.TempDir()
+	script := `echo "I0102 15:04:05.000000 1 a.go:1] dropped"; ` +
+		`echo "W0102 15:04:05.000000 1 b.go:2] kept" >&2; printf "E0102 15:04:05.000000 1 c.go:3] unterminated"`
+	if err := bp.SetupAndStart(exec.Command("sh", "-c", script), dataDir); err != nil {
+		t.Fatal(err)
+	}
+	<-bp.Exited()
+	if err := bp.Stop(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	bp.Close()
+
+	out := buf.String()
+	for _, want := range []string{`msg=kept`, `caller=b.go:2`, `process=test-proc`, `msg=unterminated`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("streamed output %q lacks %q", out, want)
+		}
+	}
+	if strings.Contains(out, "dropped") {
+		t.Errorf("streamed output %q contains a line below MinLevel", out)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dataDir, StdoutFile("test-proc"))); !strings.Contains(string(got), "dropped") {
+		t.Errorf("stdout log = %q, want every line", got)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,182 @@
+package process
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// LogRotation caps the size of a process's log files. When a write would
+// grow a file past MaxSize, the file is renamed to "<file>.1", older
+// segments shift up by one ("<file>.1" to "<file>.2", ...) and the one past
+// Segments is deleted. The zero value disables rotation.
+type LogRotation struct {
+	// MaxSize is the size in bytes at which a log file is rotated. Zero
+	// disables rotation.
+	MaxSize int64
+	// Segments is the number of rotated files kept next to the current one.
+	// Zero discards the output of a file when it is rotated.
+	Segments int
+	// Counter, if set, counts the rotations of every file, so that readers
+	// holding an offset into a file can tell it has been rotated since.
+	Counter *RotationCounter
+}
+
+// RotationCounter counts how often each log file has been rotated, keyed by
+// path. Counts only grow, also across process restarts, so the difference
+// between two readings is the number of rotations in between. It is safe for
+// concurrent use; the zero value is ready to use.
+type RotationCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Get returns the number of rotations of the file at path so far, or 0 on a
+// nil counter.
+func (c *RotationCounter) Get(path string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[path]
+}
+
+// Add records a rotation of the file at path. It is a no-op on a nil
+// counter.
+func (c *RotationCounter) Add(path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[path]++
+}
+
+// Enabled reports whether rotation is configured.
+func (r LogRotation) Enabled() bool {
+	return r.MaxSize > 0
+}
+
+// SegmentFile returns the name of rotated segment n (1 is the newest) of the
+// log file name.
+func SegmentFile(name string, n int) string {
+	return name + "." + strconv.Itoa(n)
+}
+
+// rotatingFile is a log sink that rotates the file at path according to a
+// LogRotation. It is safe for concurrent use.
+//
+// Write never fails: the process writes into a pipe that rotatingFile
+// drains, and a sink that stopped draining it would block the process once
+// the pipe buffer fills. Output that cannot be written is dropped.
+type rotatingFile struct {
+	path     string
+	rotation LogRotation
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// newRotatingFile creates (or truncates) the file at path. Segments left
+// over from an earlier run are kept until rotation replaces them.
+func newRotatingFile(path string, rotation LogRotation) (*rotatingFile, error) {
+	f, err := os.Create(path) //nolint:gosec // G304: paths from controlled config, not user input
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingFile{path: path, rotation: rotation, f: f}, nil
+}
+
+// Write appends p to the current file, rotating it whenever the next part
+// of p would grow it past MaxSize. p is split after the last newline that
+// fits, so that lines stay whole unless a single line exceeds MaxSize.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return len(p), nil
+	}
+	written := len(p)
+	for int64(len(p)) > r.rotation.MaxSize-r.size {
+		room := max(r.rotation.MaxSize-r.size, 0)
+		cut := bytes.LastIndexByte(p[:room], '\n') + 1
+		if cut == 0 && r.size == 0 {
+			cut = int(room) // a line longer than MaxSize
+		}
+		r.write(p[:cut])
+		p = p[cut:]
+		if err := r.rotate(); err != nil {
+			// Keep appending to the current file rather than losing the
+			// output; the next write retries.
+			break
+		}
+	}
+	r.write(p)
+	return written, nil
+}
+
+// write appends p to the current file, dropping what cannot be written.
+func (r *rotatingFile) write(p []byte) {
+	n, _ := r.f.Write(p)
+	r.size += int64(n)
+}
+
+// rotate closes the current file, shifts the segments and opens a new,
+// empty file at path.
+func (r *rotatingFile) rotate() error {
+	var errs []error
+	if r.rotation.Segments == 0 {
+		if err := r.f.Truncate(0); err != nil {
+			return fmt.Errorf("truncate %s: %w", r.path, err)
+		}
+		if _, err := r.f.Seek(0, 0); err != nil {
+			return fmt.Errorf("seek %s: %w", r.path, err)
+		}
+		r.size = 0
+		r.rotation.Counter.Add(r.path)
+		return nil
+	}
+
+	for n := r.rotation.Segments; n > 1; n-- {
+		err := os.Rename(SegmentFile(r.path, n-1), SegmentFile(r.path, n))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := os.Rename(r.path, SegmentFile(r.path, 1)); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	f, err := os.Create(r.path) //nolint:gosec // G304: paths from controlled config, not user input
+	if err != nil {
+		// The renamed file is still open; keep writing to it.
+		return errors.Join(append(errs, err)...)
+	}
+	_ = r.f.Close()
+	r.f = f
+	r.size = 0
+	r.rotation.Counter.Add(r.path)
+	return errors.Join(errs...)
+}
+
+// Close closes the current file. Later writes are dropped.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,117 @@
+package process
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFile returns the content of path, or "<missing>" if it does not exist.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		segments int
+		want     map[string]string // file suffix -> content
+	}{
+		"keep two": {
+			segments: 2,
+			want:     map[string]string{"": "eeee", ".1": "dddd", ".2": "cccc", ".3": "<missing>"},
+		},
+		"keep none": {
+			segments: 0,
+			want:     map[string]string{"": "eeee", ".1": "<missing>"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "kine-stderr.log")
+			counter := &RotationCounter{}
+			r, err := newRotatingFile(path, LogRotation{MaxSize: 6, Segments: tc.segments, Counter: counter})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+				if n, err := r.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v", s, n, err)
+				}
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+			for suffix, want := range tc.want {
+				if got := readFile(t, path+suffix); got != want {
+					t.Errorf("%s = %q, want %q", filepath.Base(path+suffix), got, want)
+				}
+			}
+			// Every write after the first rotates.
+			if got := counter.Get(path); got != 4 {
+				t.Errorf("rotations = %d, want 4", got)
+			}
+		})
+	}
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	t.Parallel()
+
+	r, err := newRotatingFile(filepath.Join(t.TempDir(), "log"), LogRotation{MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.Write([]byte("dropped")); n != 7 || err != nil {
+		t.Errorf("Write after Close = %d, %v; want 7, nil", n, err)
+	}
+}
+
+func TestBaseProcess_LogRotation(t *testing.T) {
+	t.Parallel()
+
+	bp := NewBaseProcess("test-proc", nil, 0)
+	bp.SetLogRotation(LogRotation{MaxSize: 100, Segments: 1})
+
+	dataDir := t.TempDir()
+	script := `for i in $(seq 1 50); do echo "line $i"; done`
+	if err := bp.SetupAndStart(exec.Command("sh", "-c", script), dataDir); err != nil {
+		t.Fatal(err)
+	}
+	<-bp.Exited()
+	if err := bp.Stop(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	bp.Close()
+
+	path := filepath.Join(dataDir, StdoutFile("test-proc"))
+	current, segment := readFile(t, path), readFile(t, SegmentFile(path, 1))
+	if !strings.HasSuffix(current, "line 50\n") {
+		t.Errorf("current log = %q, want it to end with the last line", current)
+	}
+	if len(current) > 100 || len(segment) > 100 {
+		t.Errorf("log sizes = %d, %d; want at most 100 bytes each", len(current), len(segment))
+	}
+	if got := readFile(t, SegmentFile(path, 2)); got != "<missing>" {
+		t.Errorf("segment 2 = %q, want none", got)
+	}
+}
@@ -0,0 +1,35 @@
+package k8senv
+
+import "github.com/giantswarm/k8senv/internal/apiserver"
+
+// JWTAuthenticator configures the JWT authenticator enabled by
+// WithJWTAuthenticator: the accepted audiences, how token claims map to the
+// Kubernetes user, and CEL validation rules. It mirrors the upstream
+// apiserver.config.k8s.io/v1 JWTAuthenticator minus the issuer, which k8senv
+// runs itself.
+type JWTAuthenticator = apiserver.JWTAuthenticator
+
+// JWTClaimMappings maps token claims to the username, groups, UID and extra
+// attributes of the authenticated user.
+type JWTClaimMappings = apiserver.ClaimMappings
+
+// JWTPrefixedClaimOrExpression maps a claim (with an optional prefix) or a
+// CEL expression to the username or groups.
+type JWTPrefixedClaimOrExpression = apiserver.PrefixedClaimOrExpression
+
+// JWTClaimOrExpression maps a claim or a CEL expression to the UID.
+type JWTClaimOrExpression = apiserver.ClaimOrExpression
+
+// JWTExtraMapping maps a CEL expression to an extra user attribute.
+type JWTExtraMapping = apiserver.ExtraMapping
+
+// JWTClaimValidationRule validates token claims by required value or CEL
+// expression.
+type JWTClaimValidationRule = apiserver.ClaimValidationRule
+
+// JWTUserValidationRule validates the mapped user with a CEL expression.
+type JWTUserValidationRule = apiserver.UserValidationRule
+
+// DefaultJWTAudience is the audience accepted, and put into tokens issued by
+// Instance.ConfigForJWTClaims, when JWTAuthenticator.Audiences is empty.
+const DefaultJWTAudience = apiserver.DefaultJWTAudience
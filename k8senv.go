@@ -2,15 +2,20 @@ package k8senv
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"testing"
 
 	"github.com/giantswarm/k8senv/internal/core"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/rest"
 )
 
@@ -39,13 +44,45 @@ var (
 // The core.Manager is stored as a named (unexported) field rather than embedded
 // to prevent callers from using type assertions to access internal methods
 // (e.g., IsShuttingDown, ReleaseToPool) that are not part of the public Manager interface.
+//
+// With WithKubeAPIServerVersions, versions holds one core.Manager per
+// kube-apiserver version and versionOrder lists the versions in ascending
+// order; mgr is the manager of the newest version. Without it, versions is
+// nil and mgr is the only manager.
 type managerWrapper struct {
-	mgr *core.Manager
+	mgr          *core.Manager
+	versions     map[string]*core.Manager
+	versionOrder []string
 }
 
-// Initialize wraps core.Manager.Initialize.
+// managers returns every core.Manager of w, in ascending version order.
+func (w *managerWrapper) managers() []*core.Manager {
+	if w.versions == nil {
+		return []*core.Manager{w.mgr}
+	}
+	managers := make([]*core.Manager, 0, len(w.versionOrder))
+	for _, v := range w.versionOrder {
+		managers = append(managers, w.versions[v])
+	}
+	return managers
+}
+
+// Initialize wraps core.Manager.Initialize, initializing the managers of all
+// kube-apiserver versions concurrently.
 func (w *managerWrapper) Initialize(ctx context.Context) error {
-	return w.mgr.Initialize(ctx)
+	if w.versions == nil {
+		return w.mgr.Initialize(ctx)
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	for _, v := range w.versionOrder {
+		g.Go(func() error {
+			if err := w.versions[v].Initialize(gctx); err != nil {
+				return fmt.Errorf("kube-apiserver %s: %w", v, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
 }
 
 // Acquire implements Manager.Acquire, returning Instance interface.
@@ -57,9 +94,97 @@ func (w *managerWrapper) Acquire(ctx context.Context) (Instance, error) {
 	return &instanceWrapper{inst: inst, token: token}, nil
 }
 
-// Shutdown wraps core.Manager.Shutdown.
+// AcquireVersion implements Manager.AcquireVersion.
+func (w *managerWrapper) AcquireVersion(ctx context.Context, v string) (Instance, error) {
+	m, ok := w.versions[v]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownVersion, v)
+	}
+	inst, token, err := m.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instanceWrapper{inst: inst, token: token}, nil
+}
+
+// KubeAPIServerVersions implements Manager.KubeAPIServerVersions.
+func (w *managerWrapper) KubeAPIServerVersions() []string {
+	return slices.Clone(w.versionOrder)
+}
+
+// WriteMergedKubeconfig writes the instances of all kube-apiserver versions
+// to one kubeconfig through core.WriteMergedKubeconfig.
+func (w *managerWrapper) WriteMergedKubeconfig(path string) error {
+	return core.WriteMergedKubeconfig(path, w.managers()...)
+}
+
+// Versions wraps core.Manager.Versions.
+func (w *managerWrapper) Versions() (Versions, error) {
+	return w.mgr.Versions()
+}
+
+// Preflight checks all kube-apiserver versions through core.Preflight.
+func (w *managerWrapper) Preflight(ctx context.Context) *PreflightReport {
+	return core.Preflight(ctx, w.managers()...)
+}
+
+// AcquireForTest implements Manager.AcquireForTest.
+func (w *managerWrapper) AcquireForTest(t testing.TB) Instance {
+	t.Helper()
+	return acquireForTest(t, w.mgr)
+}
+
+// ForEachVersion implements Manager.ForEachVersion.
+func (w *managerWrapper) ForEachVersion(t *testing.T, fn func(t *testing.T, inst Instance)) {
+	t.Helper()
+	if w.versions == nil {
+		fn(t, w.AcquireForTest(t))
+		return
+	}
+	for _, v := range w.versionOrder {
+		t.Run(v, func(t *testing.T) {
+			fn(t, acquireForTest(t, w.versions[v]))
+		})
+	}
+}
+
+// acquireForTest acquires an instance of m for the duration of test t, as
+// described by Manager.AcquireForTest.
+func acquireForTest(t testing.TB, m *core.Manager) Instance {
+	t.Helper()
+	inst, token, err := m.Acquire(t.Context())
+	if err != nil {
+		t.Fatalf("k8senv: acquire instance: %v", err)
+	}
+	wrapped := &instanceWrapper{inst: inst, token: token, t: t}
+	t.Cleanup(func() {
+		if err := wrapped.Release(); err != nil && !errors.Is(err, ErrDoubleRelease) {
+			t.Errorf("k8senv: release instance %s: %v", inst.ID(), err)
+		}
+	})
+	LogOnFailure(t, wrapped)
+	return wrapped
+}
+
+// Shutdown wraps core.Manager.Shutdown, shutting down the managers of all
+// kube-apiserver versions. With -k8senv.hold, it first waits for an
+// interrupt while instances kept by WithKeepOnFailure are running.
 func (w *managerWrapper) Shutdown() error {
-	return w.mgr.Shutdown()
+	managers := w.managers()
+	if *holdKept {
+		kept := 0
+		for _, m := range managers {
+			kept += m.KeptInstances()
+		}
+		holdKeptInstances(kept)
+	}
+	var errs []error
+	for _, m := range managers {
+		if err := m.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // instanceWrapper wraps core.Instance to implement the Instance interface.
@@ -74,10 +199,17 @@ func (w *managerWrapper) Shutdown() error {
 // core.Instance also checks its generation counter, but that check is tied to
 // pool-level state (the instance may be re-acquired by another consumer). The
 // wrapper-level flag provides a definitive per-acquisition guard.
+//
+// t is the test the instance was acquired for by AcquireForTest, or nil.
 type instanceWrapper struct {
 	inst     *core.Instance
 	token    uint64
+	t        testing.TB
 	released atomic.Bool
+
+	// logs is the log window of the acquisition, recorded by Release so
+	// that LogOnFailure can report the output after the instance is gone.
+	logs atomic.Pointer[core.LogWindow]
 }
 
 // Config returns *rest.Config for connecting to this instance's kube-apiserver.
@@ -93,6 +225,26 @@ func (w *instanceWrapper) Config() (*rest.Config, error) {
 	return w.inst.Config()
 }
 
+// Kubeconfig returns the kubeconfig file contents of this instance.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) Kubeconfig() ([]byte, error) {
+	if w.released.Load() {
+		return nil, ErrInstanceReleased
+	}
+	return w.inst.Kubeconfig()
+}
+
+// KubeconfigPath returns the kubeconfig file path of this instance.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) KubeconfigPath() (string, error) {
+	if w.released.Load() {
+		return "", ErrInstanceReleased
+	}
+	return w.inst.KubeconfigPath()
+}
+
 // Release returns the instance to the pool. Non-system namespace data is
 // purged from kine's SQLite database, keeping the instance running for
 // immediate reuse.
@@ -119,9 +271,101 @@ func (w *instanceWrapper) Release() error {
 	if !w.released.CompareAndSwap(false, true) {
 		return ErrDoubleRelease
 	}
+	if window, err := w.inst.LogWindow(); err == nil {
+		w.logs.Store(&window)
+	}
+	if w.t != nil && w.t.Failed() && w.keep() {
+		return nil
+	}
 	return w.inst.Release(w.token)
 }
 
+// keep hands the instance of a failed test over for inspection when
+// WithKeepOnFailure is enabled, and tells the test where to find it.
+// Returns false if the manager declines to keep it.
+func (w *instanceWrapper) keep() bool {
+	// Read the path while the instance is still acquired; Keep releases it.
+	kubeconfig, err := w.inst.KubeconfigPath()
+	if err != nil || !w.inst.Keep(w.token) {
+		return false
+	}
+	w.t.Logf("k8senv: test failed; keeping instance %s running until Shutdown\n"+
+		"  kubeconfig: %s\n  data dir:   %s", w.inst.ID(), kubeconfig, w.inst.DataDir())
+	return true
+}
+
+// Logs returns the process output written during this acquisition.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) Logs() (LeaseLogs, error) {
+	if w.released.Load() {
+		return LeaseLogs{}, ErrInstanceReleased
+	}
+	return w.inst.Logs()
+}
+
+// leaseLogs returns the process output written during this acquisition,
+// also after Release, from the log window Release recorded.
+func (w *instanceWrapper) leaseLogs() (LeaseLogs, error) {
+	if window := w.logs.Load(); window != nil {
+		return window.Read()
+	}
+	return w.Logs()
+}
+
+// AuditEvents returns the audit events recorded during this acquisition.
+//
+// Returns ErrInstanceReleased if called after Release has completed, using
+// the same wrapper-level guard as Config.
+func (w *instanceWrapper) AuditEvents() ([]AuditEvent, error) {
+	if w.released.Load() {
+		return nil, ErrInstanceReleased
+	}
+	return w.inst.AuditEvents()
+}
+
+// RawStorage returns the raw kine storage records of this instance.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) RawStorage(ctx context.Context) ([]StorageRecord, error) {
+	if w.released.Load() {
+		return nil, ErrInstanceReleased
+	}
+	return w.inst.RawStorage(ctx)
+}
+
+// ConfigForJWTClaims returns a *rest.Config authenticated by a JWT carrying
+// claims.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) ConfigForJWTClaims(claims map[string]any) (*rest.Config, error) {
+	if w.released.Load() {
+		return nil, ErrInstanceReleased
+	}
+	return w.inst.ConfigForJWTClaims(claims)
+}
+
+// ServiceAccountConfig returns a *rest.Config authenticated as the
+// ServiceAccount ns/name.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) ServiceAccountConfig(ctx context.Context, ns, name string) (*rest.Config, error) {
+	if w.released.Load() {
+		return nil, ErrInstanceReleased
+	}
+	return w.inst.ServiceAccountConfig(ctx, ns, name)
+}
+
+// ServerVersion returns the version kube-apiserver serves at /version.
+//
+// Returns ErrInstanceReleased if called after Release has completed.
+func (w *instanceWrapper) ServerVersion(ctx context.Context) (*version.Info, error) {
+	if w.released.Load() {
+		return nil, ErrInstanceReleased
+	}
+	return w.inst.ServerVersion(ctx)
+}
+
 // ID returns a unique identifier for this instance.
 // Delegates to the underlying core.Instance.
 func (w *instanceWrapper) ID() string {
@@ -143,6 +387,9 @@ func defaultManagerConfig() managerConfig {
 		InstanceStopTimeout:  DefaultInstanceStopTimeout,
 		CleanupTimeout:       DefaultCleanupTimeout,
 		ShutdownDrainTimeout: DefaultShutdownDrainTimeout,
+		MaxKeptInstances:     DefaultMaxKeptInstances,
+		APIServerVerbosity:   DefaultAPIServerVerbosity,
+		PortLockDir:          filepath.Join(os.TempDir(), defaultBaseDataDirName, defaultPortLockDirName),
 	}}
 }
 
@@ -188,7 +435,11 @@ func resetForTesting() error {
 // than the stored singleton, the warning includes which fields differ so
 // the caller can identify the conflict.
 //
-// This performs no I/O operations; call Initialize before Acquire.
+// kine and kube-apiserver left at their default names are looked up on
+// $PATH; if one is missing there but its pinned version is installed in the
+// default binaries store (see package binaries), the store's copy is used.
+// Apart from these lookups, this performs no I/O operations; call Initialize
+// before Acquire.
 //
 // The singleton is never reset after Shutdown in production code; callers
 // that need a fresh manager must restart the process. Test packages can use
@@ -202,7 +453,7 @@ func NewManager(opts ...ManagerOption) Manager {
 
 	if singletonMgr == nil {
 		cfg := applyOptions(opts)
-		singletonMgr = &managerWrapper{mgr: core.NewManagerWithConfig(cfg.ManagerConfig)}
+		singletonMgr = newManagerWrapper(cfg)
 		singletonCfg = cfg
 	} else {
 		logDuplicateNewManager(opts)
@@ -210,6 +461,24 @@ func NewManager(opts ...ManagerOption) Manager {
 	return singletonMgr
 }
 
+// newManagerWrapper creates the core managers for cfg: one per
+// kube-apiserver version with WithKubeAPIServerVersions, otherwise one.
+// Binaries not on $PATH are resolved from the default store first; cfg
+// itself is left unchanged so later NewManager calls compare against the
+// options as given.
+func newManagerWrapper(cfg managerConfig) *managerWrapper {
+	cfg.ManagerConfig = withStoreBinaries(cfg.ManagerConfig)
+	if len(cfg.KubeAPIServerVersions) == 0 {
+		return &managerWrapper{mgr: core.NewManagerWithConfig(cfg.ManagerConfig)}
+	}
+	order, versions := core.NewVersionManagers(cfg.ManagerConfig)
+	return &managerWrapper{
+		mgr:          versions[order[len(order)-1]],
+		versions:     versions,
+		versionOrder: order,
+	}
+}
+
 // logDuplicateNewManager logs a warning when NewManager is called after the
 // singleton has already been created. If opts are provided, it applies them
 // to a fresh default config and compares against the stored config, listing
@@ -246,10 +515,25 @@ func configDiffs(stored, incoming managerConfig) []string {
 
 	for i := range t.NumField() {
 		a, b := sv.Field(i), iv.Field(i)
-		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		if !fieldEqual(a, b) {
 			diffs = append(diffs, fmt.Sprintf("%s: %v != %v", t.Field(i).Name, a.Interface(), b.Interface()))
 		}
 	}
 
 	return diffs
 }
+
+// fieldEqual reports whether two config field values are equal. Funcs, also
+// when held in an interface such as an http.HandlerFunc Authorizer, are
+// compared by their code pointer, since reflect.DeepEqual treats every
+// non-nil func as unequal. Everything else is compared with
+// reflect.DeepEqual.
+func fieldEqual(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Interface && !a.IsNil() && !b.IsNil() {
+		a, b = a.Elem(), b.Elem()
+	}
+	if a.Kind() == reflect.Func && a.Type() == b.Type() {
+		return a.Pointer() == b.Pointer()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
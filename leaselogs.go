@@ -0,0 +1,57 @@
+package k8senv
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv/internal/core"
+)
+
+// LeaseLogs holds the output each process wrote during one acquisition, as
+// returned by Instance.Logs: standard output followed by standard error.
+// ControllerManager is empty unless WithControllerManager is set.
+type LeaseLogs = core.LeaseLogs
+
+// LogOnFailure registers a t.Cleanup that attaches the kine and
+// kube-apiserver output written during the acquisition of inst to the test
+// log if t has failed, so a failure comes with the server side of the story:
+//
+//	inst, err := mgr.Acquire(ctx)
+//	...
+//	defer inst.Release()
+//	k8senv.LogOnFailure(t, inst)
+//
+// The output is reported even if inst has been released by then. Instances
+// from Manager.AcquireForTest are registered automatically.
+func LogOnFailure(t testing.TB, inst Instance) {
+	t.Helper()
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		var (
+			logs LeaseLogs
+			err  error
+		)
+		if w, ok := inst.(*instanceWrapper); ok {
+			logs, err = w.leaseLogs()
+		} else {
+			logs, err = inst.Logs()
+		}
+		if err != nil {
+			t.Logf("k8senv: logs of instance %s unavailable: %v", inst.ID(), err)
+			return
+		}
+		for _, l := range []struct {
+			name string
+			data []byte
+		}{
+			{"kine", logs.Kine},
+			{"kube-apiserver", logs.APIServer},
+			{"kube-controller-manager", logs.ControllerManager},
+		} {
+			if len(l.data) > 0 {
+				t.Logf("k8senv: %s output of instance %s during this test:\n%s", l.name, inst.ID(), l.data)
+			}
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package k8senv
+
+import "github.com/giantswarm/k8senv/internal/nodesim"
+
+// FakeNode describes a Node registered by WithFakeNodes: its name, labels
+// matched by Pod nodeSelectors, and capacity. Resources missing from
+// Capacity default to 8 CPUs, 32Gi of memory and 110 Pods.
+type FakeNode = nodesim.Node
+
+// PodRule scripts the simulated lifetime of the Pods whose labels match its
+// Selector: how long until they become Ready, how long their containers run
+// and the exit code they end with. See WithPodRules.
+type PodRule = nodesim.PodRule
+
+// DefaultFakeNodeName is the name of the node WithFakeNodes registers when
+// called without nodes.
+const DefaultFakeNodeName = nodesim.DefaultNodeName
@@ -1,8 +1,17 @@
 package k8senv
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"time"
+
+	"github.com/giantswarm/k8senv/internal/apiserver"
 )
 
 // requirePositive panics if d <= 0 with a descriptive message.
@@ -77,6 +86,35 @@ func WithKubeAPIServerBinary(binPath string) ManagerOption {
 	}
 }
 
+// WithKubeAPIServerVersions runs a pool per Kubernetes version, mapping each
+// version to its kube-apiserver binary; an empty path selects
+// $K8SENV_BIN_DIR/<version>/kube-apiserver. Use Manager.AcquireVersion and
+// Manager.ForEachVersion to pick a version.
+//
+// Default: unset (a single kube-apiserver from WithKubeAPIServerBinary).
+//
+// Panics if versions is empty, if a path is empty while $K8SENV_BIN_DIR is
+// unset, or (in NewManager) if a version does not parse.
+func WithKubeAPIServerVersions(versions map[string]string) ManagerOption {
+	if len(versions) == 0 {
+		panic("k8senv: kube-apiserver versions must not be empty")
+	}
+	binDir := os.Getenv(BinDirEnv)
+	resolved := make(map[string]string, len(versions))
+	for v, path := range versions {
+		if path == "" {
+			if binDir == "" {
+				panic(fmt.Sprintf("k8senv: no kube-apiserver path for version %q and %s is not set", v, BinDirEnv))
+			}
+			path = filepath.Join(binDir, v, DefaultKubeAPIServerBinary)
+		}
+		resolved[v] = path
+	}
+	return func(c *managerConfig) {
+		c.KubeAPIServerVersions = resolved
+	}
+}
+
 // WithAcquireTimeout sets the total timeout for Acquire(), covering instance
 // startup time. Instance startup typically takes 5-15 seconds.
 //
@@ -203,3 +241,387 @@ func WithBaseDataDir(dir string) ManagerOption {
 		c.BaseDataDir = dir
 	}
 }
+
+// WithAdmissionPlugins enables and disables kube-apiserver admission plugins
+// on top of its defaults. Listing ServiceAccount in enable turns it back on.
+//
+// Default: nil, nil (apiserver defaults minus ServiceAccount).
+//
+// Panics if a plugin name is empty or appears in both lists.
+func WithAdmissionPlugins(enable, disable []string) ManagerOption {
+	for _, name := range enable {
+		requireNonEmpty("admission plugin name", name)
+		if slices.Contains(disable, name) {
+			panic(fmt.Sprintf("k8senv: admission plugin %q is both enabled and disabled", name))
+		}
+	}
+	for _, name := range disable {
+		requireNonEmpty("admission plugin name", name)
+	}
+	enable, disable = slices.Clone(enable), slices.Clone(disable)
+	return func(c *managerConfig) {
+		c.EnableAdmissionPlugins = enable
+		c.DisableAdmissionPlugins = disable
+	}
+}
+
+// WithAdmissionConfig sets an AdmissionConfiguration file passed to
+// kube-apiserver via --admission-control-config-file.
+//
+// Default: unset (no admission configuration). Overrides an earlier
+// WithAdmissionConfigObject.
+//
+// Panics if path is empty.
+func WithAdmissionConfig(path string) ManagerOption {
+	requireNonEmpty("admission config path", path)
+	return func(c *managerConfig) {
+		c.AdmissionConfigPath = path
+		c.AdmissionConfig = nil
+	}
+}
+
+// WithAdmissionConfigObject is WithAdmissionConfig with the
+// AdmissionConfiguration given as an object, which is marshalled to JSON.
+//
+// Default: unset (no admission configuration). Overrides an earlier
+// WithAdmissionConfig.
+//
+// Panics if config is nil or cannot be marshalled to JSON.
+func WithAdmissionConfigObject(config any) ManagerOption {
+	if config == nil {
+		panic("k8senv: admission config must not be nil")
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		panic(fmt.Sprintf("k8senv: marshal admission config: %v", err))
+	}
+	return func(c *managerConfig) {
+		c.AdmissionConfig = data
+		c.AdmissionConfigPath = ""
+	}
+}
+
+// WithAuditPolicy enables kube-apiserver audit logging with the audit Policy
+// in the given file. Instance.AuditEvents returns the events of the current
+// acquisition.
+//
+// Default: unset (auditing disabled). Overrides an earlier
+// WithAuditPolicyObject.
+//
+// Panics if path is empty.
+func WithAuditPolicy(path string) ManagerOption {
+	requireNonEmpty("audit policy path", path)
+	return func(c *managerConfig) {
+		c.AuditPolicyPath = path
+		c.AuditPolicy = nil
+	}
+}
+
+// WithAuditPolicyObject is WithAuditPolicy with the audit Policy given as an
+// object, which is marshalled to JSON.
+//
+// Default: unset (auditing disabled). Overrides an earlier WithAuditPolicy.
+//
+// Panics if policy is nil or cannot be marshalled to JSON.
+func WithAuditPolicyObject(policy any) ManagerOption {
+	if policy == nil {
+		panic("k8senv: audit policy must not be nil")
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		panic(fmt.Sprintf("k8senv: marshal audit policy: %v", err))
+	}
+	return func(c *managerConfig) {
+		c.AuditPolicy = data
+		c.AuditPolicyPath = ""
+	}
+}
+
+// EncryptionProvider selects the encryption-at-rest provider configured by
+// WithEncryptionConfig.
+type EncryptionProvider string
+
+// Supported encryption providers. Both use a 32-byte key generated by
+// Initialize; encrypted values are stored with the prefix
+// "k8s:enc:<provider>:v1:key1:".
+const (
+	// EncryptionAESCBC selects the aescbc provider (AES-CBC with PKCS#7
+	// padding).
+	EncryptionAESCBC EncryptionProvider = apiserver.EncryptionAESCBC
+
+	// EncryptionSecretbox selects the secretbox provider (XSalsa20 and
+	// Poly1305).
+	EncryptionSecretbox EncryptionProvider = apiserver.EncryptionSecretbox
+)
+
+// WithEncryptionConfig enables encryption at rest of the given resources
+// with provider and a key generated by Initialize. Without resources, only
+// "secrets" is encrypted.
+//
+// Default: unset (no encryption).
+//
+// Panics if provider is not EncryptionAESCBC or EncryptionSecretbox, or if a
+// resource name is empty.
+func WithEncryptionConfig(provider EncryptionProvider, resources ...string) ManagerOption {
+	if provider != EncryptionAESCBC && provider != EncryptionSecretbox {
+		panic(fmt.Sprintf("k8senv: unknown encryption provider %q", provider))
+	}
+	if slices.Contains(resources, "") {
+		panic("k8senv: encryption resource name must not be empty")
+	}
+	resources = slices.Clone(resources)
+	return func(c *managerConfig) {
+		c.EncryptionProvider = string(provider)
+		c.EncryptionResources = resources
+	}
+}
+
+// WithJWTAuthenticator enables structured JWT authentication against a local
+// OIDC issuer started by Initialize. Use Instance.ConfigForJWTClaims to get a
+// client authenticated by a token with arbitrary claims.
+//
+// Default: unset (JWT authentication disabled).
+//
+// Panics if a mapping sets both a claim and an expression, or if a
+// validation rule is incomplete.
+func WithJWTAuthenticator(auth JWTAuthenticator) ManagerOption {
+	if err := auth.Validate(); err != nil {
+		panic(fmt.Sprintf("k8senv: invalid JWT authenticator: %v", err))
+	}
+	auth.Audiences = slices.Clone(auth.Audiences)
+	auth.ClaimValidationRules = slices.Clone(auth.ClaimValidationRules)
+	auth.ClaimMappings.Extra = slices.Clone(auth.ClaimMappings.Extra)
+	auth.UserValidationRules = slices.Clone(auth.UserValidationRules)
+	return func(c *managerConfig) {
+		c.JWTAuthenticator = &auth
+	}
+}
+
+// WithAuthorizer adds an authorization webhook served by handler in the test
+// process, consulted before AlwaysAllow for requests matching all
+// matchConditions (CEL over "request").
+//
+// Default: unset (AlwaysAllow only).
+//
+// Panics if handler is nil or a match condition is empty.
+func WithAuthorizer(handler http.Handler, matchConditions ...string) ManagerOption {
+	if handler == nil {
+		panic("k8senv: authorizer handler must not be nil")
+	}
+	if slices.Contains(matchConditions, "") {
+		panic("k8senv: authorizer match condition must not be empty")
+	}
+	matchConditions = slices.Clone(matchConditions)
+	return func(c *managerConfig) {
+		c.Authorizer = handler
+		c.AuthorizerMatchConditions = matchConditions
+	}
+}
+
+// WithClientCertAuth makes Instance.Config authenticate with an admin client
+// certificate instead of a bearer token.
+//
+// Default: disabled (bearer token).
+func WithClientCertAuth() ManagerOption {
+	return func(c *managerConfig) {
+		c.ClientCertAuth = true
+	}
+}
+
+// WithServiceAccounts keeps the ServiceAccount admission plugin enabled, so
+// Pods get their ServiceAccount token mounted as in a real cluster.
+//
+// Default: disabled (ServiceAccount admission plugin disabled).
+func WithServiceAccounts() ManagerOption {
+	return func(c *managerConfig) {
+		c.ServiceAccounts = true
+	}
+}
+
+// WithControllerManager runs kube-controller-manager next to every
+// instance's kube-apiserver with the given controllers, or "namespace",
+// "garbagecollector" and "serviceaccount-token" if none are given.
+//
+// Default: unset (kine and kube-apiserver only). Cannot be combined with
+// WithInProcessControllers.
+//
+// Panics if binary or a controller name is empty.
+func WithControllerManager(binary string, controllers ...string) ManagerOption {
+	requireNonEmpty("controller manager binary path", binary)
+	if slices.Contains(controllers, "") {
+		panic("k8senv: controller name must not be empty")
+	}
+	controllers = slices.Clone(controllers)
+	return func(c *managerConfig) {
+		c.ControllerManagerBinary = binary
+		c.Controllers = controllers
+	}
+}
+
+// WithInProcessControllers runs a namespace lifecycle controller and an
+// ownerReference garbage collector in the test process, a cheaper
+// alternative to WithControllerManager.
+//
+// Default: disabled. Cannot be combined with WithControllerManager.
+func WithInProcessControllers() ManagerOption {
+	return func(c *managerConfig) {
+		c.InProcessControllers = true
+	}
+}
+
+// WithKeepOnFailure keeps the instance of a test that failed, acquired with
+// Manager.AcquireForTest, running and unpurged until Shutdown for
+// inspection.
+//
+// Default: disabled.
+func WithKeepOnFailure() ManagerOption {
+	return func(c *managerConfig) {
+		c.KeepOnFailure = true
+	}
+}
+
+// WithMaxKeptInstances sets how many instances WithKeepOnFailure keeps.
+//
+// Default: 1.
+//
+// Panics if n < 1.
+func WithMaxKeptInstances(n int) ManagerOption {
+	if n < 1 {
+		panic(fmt.Sprintf("k8senv: max kept instances must be at least 1, got %d", n))
+	}
+	return func(c *managerConfig) {
+		c.MaxKeptInstances = n
+	}
+}
+
+// WithArtifactsDir sets the directory receiving the diagnostics bundle
+// written when an instance fails to start or cannot be purged on release.
+//
+// Default: a diagnostics directory in the instance data directory.
+//
+// Panics if dir is empty.
+func WithArtifactsDir(dir string) ManagerOption {
+	requireNonEmpty("artifacts directory", dir)
+	return func(c *managerConfig) {
+		c.ArtifactsDir = dir
+	}
+}
+
+// WithKineTCP makes kine listen on a loopback TCP port instead of a unix
+// socket in the instance data directory.
+//
+// Default: a unix socket where supported.
+func WithKineTCP() ManagerOption {
+	return func(c *managerConfig) {
+		c.KineTCP = true
+	}
+}
+
+// WithNetworkNamespace runs each instance's kine and kube-apiserver in a
+// Linux user and network namespace of their own. Initialize fails on other
+// platforms.
+//
+// Default: kine and kube-apiserver run in the host network namespace.
+// Cannot be combined with WithKineTCP, WithAuditPolicy,
+// WithAuditPolicyObject, WithAuthorizer or WithJWTAuthenticator.
+func WithNetworkNamespace() ManagerOption {
+	return func(c *managerConfig) {
+		c.NetworkNamespace = true
+	}
+}
+
+// WithPortLockDir sets the directory of the per-port lock files through
+// which k8senv processes on the host coordinate port allocation.
+//
+// Default: ports in the k8senv directory of the system temp directory.
+//
+// Panics if dir is empty.
+func WithPortLockDir(dir string) ManagerOption {
+	requireNonEmpty("port lock directory", dir)
+	return func(c *managerConfig) {
+		c.PortLockDir = dir
+	}
+}
+
+// WithProcessLogs re-emits the output of kine, kube-apiserver and
+// kube-controller-manager through the logger set with SetLogger, dropping
+// lines below minLevel.
+//
+// Default: disabled.
+func WithProcessLogs(minLevel slog.Level) ManagerOption {
+	return func(c *managerConfig) {
+		c.ProcessLogs = true
+		c.ProcessLogLevel = minLevel
+	}
+}
+
+// WithAPIServerVerbosity sets the klog verbosity (--v) of kube-apiserver.
+//
+// Default: DefaultAPIServerVerbosity (2).
+//
+// Panics if n < 0.
+func WithAPIServerVerbosity(n int) ManagerOption {
+	if n < 0 {
+		panic(fmt.Sprintf("k8senv: kube-apiserver verbosity must not be negative, got %d", n))
+	}
+	return func(c *managerConfig) {
+		c.APIServerVerbosity = n
+	}
+}
+
+// WithLogRotation caps each process log file at maxSize bytes, keeping up
+// to segments rotated files.
+//
+// Default: disabled (unbounded log files).
+//
+// Panics if maxSize < 1 or segments < 0.
+func WithLogRotation(maxSize int64, segments int) ManagerOption {
+	if maxSize < 1 {
+		panic(fmt.Sprintf("k8senv: max log size must be greater than 0, got %d", maxSize))
+	}
+	if segments < 0 {
+		panic(fmt.Sprintf("k8senv: log segments must not be negative, got %d", segments))
+	}
+	return func(c *managerConfig) {
+		c.MaxLogSize = maxSize
+		c.MaxLogSegments = segments
+	}
+}
+
+// WithFakeNodes registers nodes for every acquisition and runs a simulator
+// that schedules Pods onto them and reports their status. Without arguments,
+// a single node named DefaultFakeNodeName is registered.
+//
+// Default: no nodes; Pods stay Pending.
+//
+// Panics if a node name is empty. NewManager panics on duplicate names or
+// negative capacity.
+func WithFakeNodes(nodes ...FakeNode) ManagerOption {
+	if len(nodes) == 0 {
+		nodes = []FakeNode{{Name: DefaultFakeNodeName}}
+	}
+	nodes = slices.Clone(nodes)
+	for i, n := range nodes {
+		requireNonEmpty("fake node name", n.Name)
+		nodes[i].Labels = maps.Clone(n.Labels)
+		nodes[i].Capacity = n.Capacity.DeepCopy()
+	}
+	return func(c *managerConfig) {
+		c.FakeNodes = nodes
+	}
+}
+
+// WithPodRules scripts the lifetime of Pods on the nodes registered by
+// WithFakeNodes. Each Pod follows the first rule whose Selector matches it.
+//
+// Default: no rules. Requires WithFakeNodes; NewManager panics otherwise,
+// or on negative durations or an invalid selector.
+func WithPodRules(rules ...PodRule) ManagerOption {
+	rules = slices.Clone(rules)
+	for i, r := range rules {
+		rules[i].Selector = maps.Clone(r.Selector)
+	}
+	return func(c *managerConfig) {
+		c.PodRules = rules
+	}
+}
@@ -2,8 +2,12 @@ package k8senv_test
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -232,6 +236,258 @@ func TestWithEmptyStringOptionsPanic(t *testing.T) {
 			panicMsg: "k8senv: base data directory must not be empty",
 			fn:       func() { k8senv.WithBaseDataDir("") },
 		},
+		{
+			name:     "artifactsDir",
+			panics:   true,
+			panicMsg: "k8senv: artifacts directory must not be empty",
+			fn:       func() { k8senv.WithArtifactsDir("") },
+		},
+		{
+			name:     "portLockDir",
+			panics:   true,
+			panicMsg: "k8senv: port lock directory must not be empty",
+			fn:       func() { k8senv.WithPortLockDir("") },
+		},
+		{
+			name:     "admissionConfig",
+			panics:   true,
+			panicMsg: "k8senv: admission config path must not be empty",
+			fn:       func() { k8senv.WithAdmissionConfig("") },
+		},
+		{
+			name:     "admissionConfigObject nil",
+			panics:   true,
+			panicMsg: "k8senv: admission config must not be nil",
+			fn:       func() { k8senv.WithAdmissionConfigObject(nil) },
+		},
+		{
+			name:     "admissionConfigObject unmarshallable",
+			panics:   true,
+			panicMsg: "k8senv: marshal admission config: json: unsupported type: chan int",
+			fn:       func() { k8senv.WithAdmissionConfigObject(make(chan int)) },
+		},
+		{
+			name:     "auditPolicy",
+			panics:   true,
+			panicMsg: "k8senv: audit policy path must not be empty",
+			fn:       func() { k8senv.WithAuditPolicy("") },
+		},
+		{
+			name:     "auditPolicyObject nil",
+			panics:   true,
+			panicMsg: "k8senv: audit policy must not be nil",
+			fn:       func() { k8senv.WithAuditPolicyObject(nil) },
+		},
+		{
+			name:     "auditPolicyObject unmarshallable",
+			panics:   true,
+			panicMsg: "k8senv: marshal audit policy: json: unsupported type: chan int",
+			fn:       func() { k8senv.WithAuditPolicyObject(make(chan int)) },
+		},
+	})
+}
+
+func TestWithAdmissionPluginsPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "empty enabled name",
+			panics:   true,
+			panicMsg: "k8senv: admission plugin name must not be empty",
+			fn:       func() { k8senv.WithAdmissionPlugins([]string{""}, nil) },
+		},
+		{
+			name:     "empty disabled name",
+			panics:   true,
+			panicMsg: "k8senv: admission plugin name must not be empty",
+			fn:       func() { k8senv.WithAdmissionPlugins(nil, []string{""}) },
+		},
+		{
+			name:     "enabled and disabled",
+			panics:   true,
+			panicMsg: `k8senv: admission plugin "PodSecurity" is both enabled and disabled`,
+			fn:       func() { k8senv.WithAdmissionPlugins([]string{"PodSecurity"}, []string{"PodSecurity"}) },
+		},
+		{name: caseValid, fn: func() { k8senv.WithAdmissionPlugins([]string{"EventRateLimit"}, []string{"LimitRanger"}) }},
+	})
+}
+
+func TestWithEncryptionConfigPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "unknown provider",
+			panics:   true,
+			panicMsg: `k8senv: unknown encryption provider "aesgcm"`,
+			fn:       func() { k8senv.WithEncryptionConfig("aesgcm") },
+		},
+		{
+			name:     "empty resource",
+			panics:   true,
+			panicMsg: "k8senv: encryption resource name must not be empty",
+			fn:       func() { k8senv.WithEncryptionConfig(k8senv.EncryptionAESCBC, "") },
+		},
+		{name: caseValid, fn: func() { k8senv.WithEncryptionConfig(k8senv.EncryptionSecretbox, "secrets", "configmaps") }},
+	})
+}
+
+func TestWithJWTAuthenticatorPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "claim and expression",
+			panics:   true,
+			panicMsg: "k8senv: invalid JWT authenticator: jwt username mapping: claim and expression are mutually exclusive",
+			fn: func() {
+				k8senv.WithJWTAuthenticator(k8senv.JWTAuthenticator{ClaimMappings: k8senv.JWTClaimMappings{
+					Username: k8senv.JWTPrefixedClaimOrExpression{Claim: "email", Expression: "claims.email"},
+				}})
+			},
+		},
+		{
+			name:     "incomplete claim validation rule",
+			panics:   true,
+			panicMsg: "k8senv: invalid JWT authenticator: jwt claim validation rule: exactly one of claim and expression must be set",
+			fn: func() {
+				k8senv.WithJWTAuthenticator(k8senv.JWTAuthenticator{
+					ClaimValidationRules: []k8senv.JWTClaimValidationRule{{Message: "no rule"}},
+				})
+			},
+		},
+		{name: caseValid, fn: func() { k8senv.WithJWTAuthenticator(k8senv.JWTAuthenticator{}) }},
+	})
+}
+
+func TestWithAuthorizerPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	allowAll := k8senv.AuthorizerFunc(func(*k8senv.SubjectAccessReview) k8senv.SubjectAccessReviewStatus {
+		return k8senv.SubjectAccessReviewStatus{Allowed: true}
+	})
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "nil handler",
+			panics:   true,
+			panicMsg: "k8senv: authorizer handler must not be nil",
+			fn:       func() { k8senv.WithAuthorizer(nil) },
+		},
+		{
+			name:     "empty match condition",
+			panics:   true,
+			panicMsg: "k8senv: authorizer match condition must not be empty",
+			fn:       func() { k8senv.WithAuthorizer(allowAll, "") },
+		},
+		{name: caseValid, fn: func() { k8senv.WithAuthorizer(allowAll, "request.user == 'alice'") }},
+	})
+}
+
+func TestWithControllerManagerPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "empty binary",
+			panics:   true,
+			panicMsg: "k8senv: controller manager binary path must not be empty",
+			fn:       func() { k8senv.WithControllerManager("") },
+		},
+		{
+			name:     "empty controller",
+			panics:   true,
+			panicMsg: "k8senv: controller name must not be empty",
+			fn:       func() { k8senv.WithControllerManager(k8senv.DefaultControllerManagerBinary, "") },
+		},
+		{name: caseValid, fn: func() { k8senv.WithControllerManager(k8senv.DefaultControllerManagerBinary) }},
+	})
+}
+
+func TestWithMaxKeptInstancesPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "zero",
+			panics:   true,
+			panicMsg: "k8senv: max kept instances must be at least 1, got 0",
+			fn:       func() { k8senv.WithMaxKeptInstances(0) },
+		},
+		{name: caseValid, fn: func() { k8senv.WithMaxKeptInstances(2) }},
+	})
+}
+
+func TestWithAPIServerVerbosityPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     caseNegative,
+			panics:   true,
+			panicMsg: "k8senv: kube-apiserver verbosity must not be negative, got -1",
+			fn:       func() { k8senv.WithAPIServerVerbosity(-1) },
+		},
+		{name: caseZero, fn: func() { k8senv.WithAPIServerVerbosity(0) }},
+	})
+}
+
+func TestWithLogRotationPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "zero size",
+			panics:   true,
+			panicMsg: "k8senv: max log size must be greater than 0, got 0",
+			fn:       func() { k8senv.WithLogRotation(0, 1) },
+		},
+		{
+			name:     "negative segments",
+			panics:   true,
+			panicMsg: "k8senv: log segments must not be negative, got -1",
+			fn:       func() { k8senv.WithLogRotation(1<<20, -1) },
+		},
+		{name: "no segments", fn: func() { k8senv.WithLogRotation(1<<20, 0) }},
+	})
+}
+
+func TestWithKubeAPIServerVersionsPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "empty",
+			panics:   true,
+			panicMsg: "k8senv: kube-apiserver versions must not be empty",
+			fn:       func() { k8senv.WithKubeAPIServerVersions(nil) },
+		},
+		{name: caseValid, fn: func() { k8senv.WithKubeAPIServerVersions(map[string]string{"1.33": "/opt/1.33/kube-apiserver"}) }},
+	})
+}
+
+// TestWithKubeAPIServerVersionsBinDir cannot run in parallel: it sets
+// K8SENV_BIN_DIR.
+func TestWithKubeAPIServerVersionsBinDir(t *testing.T) {
+	t.Setenv(k8senv.BinDirEnv, "")
+	requirePanics(t, true, `k8senv: no kube-apiserver path for version "1.33" and K8SENV_BIN_DIR is not set`,
+		func() { k8senv.WithKubeAPIServerVersions(map[string]string{"1.33": ""}) })
+
+	t.Setenv(k8senv.BinDirEnv, "/opt/k8s")
+	snap := k8senv.ApplyOptionsForTesting(k8senv.WithKubeAPIServerVersions(map[string]string{
+		"1.33": "",
+		"1.35": "/custom/kube-apiserver",
+	}))
+	want := map[string]string{
+		"1.33": filepath.Join("/opt/k8s", "1.33", "kube-apiserver"),
+		"1.35": "/custom/kube-apiserver",
+	}
+	if !reflect.DeepEqual(snap.KubeAPIServerVersions, want) {
+		t.Errorf("KubeAPIServerVersions = %v, want %v", snap.KubeAPIServerVersions, want)
+	}
+}
+
+func TestWithFakeNodesPanicsOnInvalid(t *testing.T) {
+	t.Parallel()
+	runPanicTests(t, []panicTestCase{
+		{
+			name:     "empty name",
+			panics:   true,
+			panicMsg: "k8senv: fake node name must not be empty",
+			fn:       func() { k8senv.WithFakeNodes(k8senv.FakeNode{}) },
+		},
+		{name: caseValid, fn: func() { k8senv.WithFakeNodes(k8senv.FakeNode{Name: "a"}) }},
 	})
 }
 
@@ -250,9 +506,12 @@ func TestOptionApplicationDefaults(t *testing.T) {
 		InstanceStopTimeout:  k8senv.DefaultInstanceStopTimeout,
 		CleanupTimeout:       k8senv.DefaultCleanupTimeout,
 		ShutdownDrainTimeout: k8senv.DefaultShutdownDrainTimeout,
+		MaxKeptInstances:     k8senv.DefaultMaxKeptInstances,
+		APIServerVerbosity:   k8senv.DefaultAPIServerVerbosity,
+		PortLockDir:          filepath.Join(os.TempDir(), "k8senv", "ports"),
 	}
 
-	if got != want {
+	if !reflect.DeepEqual(got, want) {
 		t.Errorf("ApplyOptionsForTesting() =\n  %+v\nwant\n  %+v", got, want)
 	}
 }
@@ -358,13 +617,210 @@ func TestOptionApplicationOverrides(t *testing.T) {
 			got:   func(s k8senv.ConfigSnapshot) any { return s.ShutdownDrainTimeout },
 			want:  2 * time.Minute,
 		},
+		{
+			name:  "WithAdmissionPlugins_enable",
+			opt:   k8senv.WithAdmissionPlugins([]string{"EventRateLimit"}, []string{"LimitRanger"}),
+			field: "EnableAdmissionPlugins",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.EnableAdmissionPlugins },
+			want:  []string{"EventRateLimit"},
+		},
+		{
+			name:  "WithAdmissionPlugins_disable",
+			opt:   k8senv.WithAdmissionPlugins([]string{"EventRateLimit"}, []string{"LimitRanger"}),
+			field: "DisableAdmissionPlugins",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.DisableAdmissionPlugins },
+			want:  []string{"LimitRanger"},
+		},
+		{
+			name:  "WithAdmissionConfig",
+			opt:   k8senv.WithAdmissionConfig("/etc/k8senv/admission.yaml"),
+			field: "AdmissionConfigPath",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.AdmissionConfigPath },
+			want:  "/etc/k8senv/admission.yaml",
+		},
+		{
+			name:  "WithAdmissionConfigObject",
+			opt:   k8senv.WithAdmissionConfigObject(map[string]any{"kind": "AdmissionConfiguration"}),
+			field: "AdmissionConfig",
+			got:   func(s k8senv.ConfigSnapshot) any { return string(s.AdmissionConfig) },
+			want:  `{"kind":"AdmissionConfiguration"}`,
+		},
+		{
+			name:  "WithAuditPolicy",
+			opt:   k8senv.WithAuditPolicy("/etc/k8senv/audit-policy.yaml"),
+			field: "AuditPolicyPath",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.AuditPolicyPath },
+			want:  "/etc/k8senv/audit-policy.yaml",
+		},
+		{
+			name:  "WithAuditPolicyObject",
+			opt:   k8senv.WithAuditPolicyObject(map[string]any{"kind": "Policy"}),
+			field: "AuditPolicy",
+			got:   func(s k8senv.ConfigSnapshot) any { return string(s.AuditPolicy) },
+			want:  `{"kind":"Policy"}`,
+		},
+		{
+			name:  "WithEncryptionConfig_provider",
+			opt:   k8senv.WithEncryptionConfig(k8senv.EncryptionSecretbox, "configmaps"),
+			field: "EncryptionProvider",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.EncryptionProvider },
+			want:  "secretbox",
+		},
+		{
+			name:  "WithEncryptionConfig_resources",
+			opt:   k8senv.WithEncryptionConfig(k8senv.EncryptionAESCBC, "secrets", "configmaps"),
+			field: "EncryptionResources",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.EncryptionResources },
+			want:  []string{"secrets", "configmaps"},
+		},
+		{
+			name: "WithJWTAuthenticator",
+			opt: k8senv.WithJWTAuthenticator(k8senv.JWTAuthenticator{
+				Audiences: []string{"platform"},
+			}),
+			field: "JWTAuthenticator",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.JWTAuthenticator },
+			want:  &k8senv.JWTAuthenticator{Audiences: []string{"platform"}},
+		},
+		{
+			name:  "WithAuthorizer_match_conditions",
+			opt:   k8senv.WithAuthorizer(http.NotFoundHandler(), "request.user == 'alice'"),
+			field: "AuthorizerMatchConditions",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.AuthorizerMatchConditions },
+			want:  []string{"request.user == 'alice'"},
+		},
+		{
+			name:  "WithAuthorizer_handler",
+			opt:   k8senv.WithAuthorizer(http.NotFoundHandler()),
+			field: "Authorizer",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.Authorizer != nil },
+			want:  true,
+		},
+		{
+			name:  "WithClientCertAuth",
+			opt:   k8senv.WithClientCertAuth(),
+			field: "ClientCertAuth",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.ClientCertAuth },
+			want:  true,
+		},
+		{
+			name:  "WithServiceAccounts",
+			opt:   k8senv.WithServiceAccounts(),
+			field: "ServiceAccounts",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.ServiceAccounts },
+			want:  true,
+		},
+		{
+			name:  "WithInProcessControllers",
+			opt:   k8senv.WithInProcessControllers(),
+			field: "InProcessControllers",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.InProcessControllers },
+			want:  true,
+		},
+		{
+			name:  "WithKineTCP",
+			opt:   k8senv.WithKineTCP(),
+			field: "KineTCP",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.KineTCP },
+			want:  true,
+		},
+		{
+			name:  "WithNetworkNamespace",
+			opt:   k8senv.WithNetworkNamespace(),
+			field: "NetworkNamespace",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.NetworkNamespace },
+			want:  true,
+		},
+		{
+			name:  "WithKeepOnFailure",
+			opt:   k8senv.WithKeepOnFailure(),
+			field: "KeepOnFailure",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.KeepOnFailure },
+			want:  true,
+		},
+		{
+			name:  "WithArtifactsDir",
+			opt:   k8senv.WithArtifactsDir("/ci/artifacts"),
+			field: "ArtifactsDir",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.ArtifactsDir },
+			want:  "/ci/artifacts",
+		},
+		{
+			name:  "WithPortLockDir",
+			opt:   k8senv.WithPortLockDir("/run/k8senv-ports"),
+			field: "PortLockDir",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.PortLockDir },
+			want:  "/run/k8senv-ports",
+		},
+		{
+			name:  "WithProcessLogs",
+			opt:   k8senv.WithProcessLogs(slog.LevelWarn),
+			field: "ProcessLogs",
+			got: func(s k8senv.ConfigSnapshot) any {
+				return [2]any{s.ProcessLogs, s.ProcessLogLevel}
+			},
+			want: [2]any{true, slog.LevelWarn},
+		},
+		{
+			name:  "WithAPIServerVerbosity",
+			opt:   k8senv.WithAPIServerVerbosity(0),
+			field: "APIServerVerbosity",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.APIServerVerbosity },
+			want:  0,
+		},
+		{
+			name:  "WithLogRotation",
+			opt:   k8senv.WithLogRotation(10<<20, 3),
+			field: "MaxLogSize",
+			got: func(s k8senv.ConfigSnapshot) any {
+				return [2]any{s.MaxLogSize, s.MaxLogSegments}
+			},
+			want: [2]any{int64(10 << 20), 3},
+		},
+		{
+			name:  "WithMaxKeptInstances",
+			opt:   k8senv.WithMaxKeptInstances(3),
+			field: "MaxKeptInstances",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.MaxKeptInstances },
+			want:  3,
+		},
+		{
+			name:  "WithFakeNodes_default",
+			opt:   k8senv.WithFakeNodes(),
+			field: "FakeNodes",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.FakeNodes },
+			want:  []k8senv.FakeNode{{Name: k8senv.DefaultFakeNodeName}},
+		},
+		{
+			name:  "WithFakeNodes",
+			opt:   k8senv.WithFakeNodes(k8senv.FakeNode{Name: "a", Labels: map[string]string{"pool": "x"}}),
+			field: "FakeNodes",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.FakeNodes },
+			want:  []k8senv.FakeNode{{Name: "a", Labels: map[string]string{"pool": "x"}}},
+		},
+		{
+			name:  "WithPodRules",
+			opt:   k8senv.WithPodRules(k8senv.PodRule{RunFor: time.Second, ExitCode: 1}),
+			field: "PodRules",
+			got:   func(s k8senv.ConfigSnapshot) any { return s.PodRules },
+			want:  []k8senv.PodRule{{RunFor: time.Second, ExitCode: 1}},
+		},
+		{
+			name:  "WithControllerManager",
+			opt:   k8senv.WithControllerManager("/opt/kcm", "namespace", "garbagecollector"),
+			field: "ControllerManager",
+			got: func(s k8senv.ConfigSnapshot) any {
+				return []any{s.ControllerManagerBinary, s.Controllers}
+			},
+			want: []any{"/opt/kcm", []string{"namespace", "garbagecollector"}},
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 			snap := k8senv.ApplyOptionsForTesting(tc.opt)
-			if got := tc.got(snap); got != tc.want {
+			if got := tc.got(snap); !reflect.DeepEqual(got, tc.want) {
 				t.Errorf("%s = %v, want %v", tc.field, got, tc.want)
 			}
 		})
@@ -393,9 +849,14 @@ func TestOptionApplicationMultipleOptions(t *testing.T) {
 		InstanceStartTimeout: k8senv.DefaultInstanceStartTimeout,
 		InstanceStopTimeout:  k8senv.DefaultInstanceStopTimeout,
 		ShutdownDrainTimeout: k8senv.DefaultShutdownDrainTimeout,
+		MaxKeptInstances:     k8senv.DefaultMaxKeptInstances,
+		APIServerVerbosity:   k8senv.DefaultAPIServerVerbosity,
+		// The port lock directory is host-wide and does not follow
+		// WithBaseDataDir.
+		PortLockDir: filepath.Join(os.TempDir(), "k8senv", "ports"),
 	}
 
-	if got != want {
+	if !reflect.DeepEqual(got, want) {
 		t.Errorf("ApplyOptionsForTesting() =\n  %+v\nwant\n  %+v", got, want)
 	}
 }
@@ -412,3 +873,70 @@ func TestOptionApplicationLastWriteWins(t *testing.T) {
 		t.Errorf("PoolSize = %d, want 8 (last write wins)", snap.PoolSize)
 	}
 }
+
+func TestConfigDiffsHandlers(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	allowAll := k8senv.AuthorizerFunc(func(*k8senv.SubjectAccessReview) k8senv.SubjectAccessReviewStatus {
+		return k8senv.SubjectAccessReviewStatus{Allowed: true}
+	})
+
+	for name, h := range map[string]http.Handler{"HandlerFunc": handler, "AuthorizerFunc": allowAll} {
+		opts := []k8senv.ManagerOption{k8senv.WithAuthorizer(h)}
+		if diffs := k8senv.ConfigDiffsForTesting(opts, opts); len(diffs) != 0 {
+			t.Errorf("%s: same authorizer reported as conflict: %v", name, diffs)
+		}
+	}
+
+	diffs := k8senv.ConfigDiffsForTesting(
+		[]k8senv.ManagerOption{k8senv.WithAuthorizer(handler)},
+		[]k8senv.ManagerOption{k8senv.WithAuthorizer(http.NotFoundHandler())},
+	)
+	if len(diffs) != 1 || !strings.HasPrefix(diffs[0], "Authorizer:") {
+		t.Errorf("different authorizers: diffs = %v, want one Authorizer diff", diffs)
+	}
+	if diffs := k8senv.ConfigDiffsForTesting(nil, []k8senv.ManagerOption{k8senv.WithAuthorizer(handler)}); len(diffs) != 1 {
+		t.Errorf("authorizer added: diffs = %v, want one", diffs)
+	}
+}
+
+func TestAdmissionConfigOptionsOverrideEachOther(t *testing.T) {
+	t.Parallel()
+
+	snap := k8senv.ApplyOptionsForTesting(
+		k8senv.WithAdmissionConfig("/etc/k8senv/admission.yaml"),
+		k8senv.WithAdmissionConfigObject(map[string]any{"kind": "AdmissionConfiguration"}),
+	)
+	if snap.AdmissionConfigPath != "" || len(snap.AdmissionConfig) == 0 {
+		t.Errorf("path %q, object %q: want the object only", snap.AdmissionConfigPath, snap.AdmissionConfig)
+	}
+
+	snap = k8senv.ApplyOptionsForTesting(
+		k8senv.WithAdmissionConfigObject(map[string]any{"kind": "AdmissionConfiguration"}),
+		k8senv.WithAdmissionConfig("/etc/k8senv/admission.yaml"),
+	)
+	if snap.AdmissionConfigPath == "" || len(snap.AdmissionConfig) != 0 {
+		t.Errorf("path %q, object %q: want the path only", snap.AdmissionConfigPath, snap.AdmissionConfig)
+	}
+}
+
+func TestAuditPolicyOptionsOverrideEachOther(t *testing.T) {
+	t.Parallel()
+
+	snap := k8senv.ApplyOptionsForTesting(
+		k8senv.WithAuditPolicy("/etc/k8senv/audit-policy.yaml"),
+		k8senv.WithAuditPolicyObject(map[string]any{"kind": "Policy"}),
+	)
+	if snap.AuditPolicyPath != "" || len(snap.AuditPolicy) == 0 {
+		t.Errorf("path %q, object %q: want the object only", snap.AuditPolicyPath, snap.AuditPolicy)
+	}
+
+	snap = k8senv.ApplyOptionsForTesting(
+		k8senv.WithAuditPolicyObject(map[string]any{"kind": "Policy"}),
+		k8senv.WithAuditPolicy("/etc/k8senv/audit-policy.yaml"),
+	)
+	if snap.AuditPolicyPath == "" || len(snap.AuditPolicy) != 0 {
+		t.Errorf("path %q, object %q: want the path only", snap.AuditPolicyPath, snap.AuditPolicy)
+	}
+}
@@ -0,0 +1,20 @@
+package k8senv
+
+import "github.com/giantswarm/k8senv/internal/core"
+
+// PreflightReport holds the results of Manager.Preflight. Failed and Err
+// tell whether any check failed; WriteTo prints one line per check.
+type PreflightReport = core.PreflightReport
+
+// PreflightCheck is the result of one preflight check.
+type PreflightCheck = core.PreflightCheck
+
+// CheckStatus is the outcome of a preflight check.
+type CheckStatus = core.CheckStatus
+
+// Preflight check outcomes reported in PreflightCheck.Status.
+const (
+	CheckOK   = core.CheckOK
+	CheckWarn = core.CheckWarn
+	CheckFail = core.CheckFail
+)
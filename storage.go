@@ -0,0 +1,9 @@
+package k8senv
+
+import "github.com/giantswarm/k8senv/internal/core"
+
+// StorageRecord is the current kine row for one storage key, as returned by
+// Instance.RawStorage. Value holds the bytes exactly as kube-apiserver wrote
+// them: protobuf-encoded values start with "k8s\x00", JSON-encoded values
+// with "{", and values encrypted at rest with "k8s:enc:<provider>:v1:key1:".
+type StorageRecord = core.StorageRecord
@@ -0,0 +1,84 @@
+//go:build integration
+
+package k8senv_admission_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func pod(name string, sc *corev1.SecurityContext) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "registry.k8s.io/pause:3.10", SecurityContext: sc}},
+		},
+	}
+}
+
+// TestPodSecurityRestricted verifies that the PodSecurity defaults from the
+// admission configuration object are enforced.
+func TestPodSecurityRestricted(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	_, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("psa")
+	testutil.CreateNamespace(ctx, t, client, ns)
+
+	_, err := client.CoreV1().Pods(ns).Create(ctx, pod("privileged", &corev1.SecurityContext{Privileged: ptr.To(true)}), metav1.CreateOptions{})
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), `violates PodSecurity "restricted:latest"`) {
+		t.Errorf("create privileged pod: error = %v, want a restricted PodSecurity violation", err)
+	}
+
+	restricted := &corev1.SecurityContext{
+		RunAsNonRoot:             ptr.To(true),
+		AllowPrivilegeEscalation: ptr.To(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	if _, err := client.CoreV1().Pods(ns).Create(ctx, pod("restricted", restricted), metav1.CreateOptions{}); err != nil {
+		t.Errorf("create restricted pod: %v", err)
+	}
+}
+
+// TestResourceQuotaExceeded verifies that the ResourceQuota admission plugin,
+// enabled by default, rejects objects beyond a quota. Without a quota
+// controller, the quota's usage is set by hand.
+func TestResourceQuotaExceeded(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	_, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("quota")
+	testutil.CreateNamespace(ctx, t, client, ns)
+
+	limits := corev1.ResourceList{corev1.ResourceConfigMaps: resource.MustParse("1")}
+	quota, err := client.CoreV1().ResourceQuotas(ns).Create(ctx, &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmaps"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: limits},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create quota: %v", err)
+	}
+	quota.Status = corev1.ResourceQuotaStatus{Hard: limits, Used: limits}
+	if _, err := client.CoreV1().ResourceQuotas(ns).UpdateStatus(ctx, quota, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update quota status: %v", err)
+	}
+
+	_, err = client.CoreV1().ConfigMaps(ns).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "over-quota"},
+	}, metav1.CreateOptions{})
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "exceeded quota") {
+		t.Errorf("create configmap: error = %v, want exceeded quota", err)
+	}
+}
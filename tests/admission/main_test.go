@@ -0,0 +1,32 @@
+//go:build integration
+
+package k8senv_admission_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRun(m, &sharedManager, "k8senv-admission-test-*",
+		k8senv.WithAdmissionConfigObject(map[string]any{
+			"apiVersion": "apiserver.config.k8s.io/v1",
+			"kind":       "AdmissionConfiguration",
+			"plugins": []any{map[string]any{
+				"name": "PodSecurity",
+				"configuration": map[string]any{
+					"apiVersion": "pod-security.admission.config.k8s.io/v1",
+					"kind":       "PodSecurityConfiguration",
+					"defaults": map[string]any{
+						"enforce":         "restricted",
+						"enforce-version": "latest",
+					},
+				},
+			}},
+		}),
+	)
+}
@@ -0,0 +1,86 @@
+//go:build integration
+
+package k8senv_audit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestAuditEventsCapturePatch verifies that a PATCH made through the
+// instance's client is recorded, with its request body, by the time the
+// call returns.
+func TestAuditEventsCapturePatch(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("audit")
+	testutil.CreateNamespace(ctx, t, client, ns)
+
+	name := testutil.UniqueName("cm")
+	if _, err := client.CoreV1().ConfigMaps(ns).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create configmap: %v", err)
+	}
+	patch := []byte(`{"data":{"audited":"yes"}}`)
+	if _, err := client.CoreV1().ConfigMaps(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		t.Fatalf("patch configmap: %v", err)
+	}
+
+	events, err := inst.AuditEvents()
+	if err != nil {
+		t.Fatalf("AuditEvents() error: %v", err)
+	}
+	var found *k8senv.AuditEvent
+	for i, ev := range events {
+		if ev.Verb == "patch" && ev.ObjectRef != nil && ev.ObjectRef.Namespace == ns && ev.ObjectRef.Name == name {
+			found = &events[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("no patch event for configmap %s/%s among %d events", ns, name, len(events))
+	}
+	if found.Stage != "ResponseComplete" || found.Level != "Request" {
+		t.Errorf("patch event stage %q, level %q, want ResponseComplete, Request", found.Stage, found.Level)
+	}
+	if !strings.Contains(string(found.RequestObject), `"audited":"yes"`) {
+		t.Errorf("patch event request object = %s, want the patch body", found.RequestObject)
+	}
+	if found.ResponseStatus == nil || found.ResponseStatus.Code != 200 {
+		t.Errorf("patch event response status = %+v, want 200", found.ResponseStatus)
+	}
+}
+
+// TestAuditEventsScopedToLease verifies that a new lease starts with no
+// events from the previous one.
+func TestAuditEventsScopedToLease(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+
+	events, err := inst.AuditEvents()
+	if err != nil {
+		t.Fatalf("AuditEvents() error: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("fresh lease has %d audit events, want 0", len(events))
+	}
+
+	ns := testutil.UniqueName("audit")
+	testutil.CreateNamespace(ctx, t, client, ns)
+	// Namespaces are outside the policy's ConfigMap rule.
+	if events, err = inst.AuditEvents(); err != nil || len(events) != 0 {
+		t.Errorf("AuditEvents() = %d events, %v; want none for a namespace create", len(events), err)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build integration
+
+package k8senv_audit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+// auditPolicy records ConfigMap requests with their request bodies and
+// nothing else, which keeps each lease's event list short. kube-system is
+// excluded because kube-apiserver maintains ConfigMaps there on its own.
+const auditPolicy = `apiVersion: audit.k8s.io/v1
+kind: Policy
+omitStages: ["RequestReceived"]
+rules:
+- level: None
+  namespaces: ["kube-system"]
+- level: Request
+  resources:
+  - group: ""
+    resources: ["configmaps"]
+- level: None
+`
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRunWithHook(m, &sharedManager, "k8senv-audit-test-*",
+		func(tmpDir string) ([]k8senv.ManagerOption, error) {
+			path := filepath.Join(tmpDir, "audit-policy.yaml")
+			if err := os.WriteFile(path, []byte(auditPolicy), 0o600); err != nil {
+				return nil, err
+			}
+			return []k8senv.ManagerOption{k8senv.WithAuditPolicy(path)}, nil
+		},
+	)
+}
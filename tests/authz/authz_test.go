@@ -0,0 +1,60 @@
+//go:build integration
+
+package k8senv_authz_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TestAuthorizerDenies verifies that a request denied by the webhook fails
+// with 403 and the webhook's reason, while requests it has no opinion on
+// fall through to AlwaysAllow.
+func TestAuthorizerDenies(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, admin, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("authz")
+	testutil.CreateNamespace(ctx, t, admin, ns)
+
+	cfg, err := inst.ServiceAccountConfig(ctx, ns, "reader")
+	if err != nil {
+		t.Fatalf("ServiceAccountConfig() error: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	_, err = client.CoreV1().Secrets(ns).List(ctx, metav1.ListOptions{})
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "secrets are off limits") {
+		t.Errorf("list secrets as ServiceAccount: error = %v, want Forbidden with the webhook's reason", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		t.Errorf("list configmaps as ServiceAccount: %v", err)
+	}
+}
+
+// TestAuthorizerMatchConditions verifies that requests outside the match
+// conditions, and those of the system:masters admin, never reach the
+// webhook.
+func TestAuthorizerMatchConditions(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	_, admin, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("authz")
+	testutil.CreateNamespace(ctx, t, admin, ns)
+
+	if _, err := admin.CoreV1().Secrets(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		t.Errorf("list secrets as admin: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build integration
+
+package k8senv_authz_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+// denySecrets denies every request for Secrets and has no opinion on the
+// rest, which AlwaysAllow then allows.
+func denySecrets(r *k8senv.SubjectAccessReview) k8senv.SubjectAccessReviewStatus {
+	if attrs := r.Spec.ResourceAttributes; attrs != nil && attrs.Resource == "secrets" {
+		return k8senv.SubjectAccessReviewStatus{Denied: true, Reason: "secrets are off limits"}
+	}
+	return k8senv.SubjectAccessReviewStatus{}
+}
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRun(m, &sharedManager, "k8senv-authz-test-*",
+		k8senv.WithAuthorizer(k8senv.AuthorizerFunc(denySecrets),
+			"request.user.startsWith('system:serviceaccount:')"),
+	)
+}
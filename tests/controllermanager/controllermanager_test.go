@@ -0,0 +1,24 @@
+//go:build integration
+
+package k8senv_controllermanager_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNamespaceDeletionCompletes verifies that the namespace controller
+// finalizes deleted namespaces.
+func TestNamespaceDeletionCompletes(t *testing.T) {
+	t.Parallel()
+	testutil.NamespaceDeletionCompletes(t.Context(), t, sharedManager, "kcm-ns")
+}
+
+// TestGarbageCollection verifies that the garbage collector deletes an
+// owned object after its owner is deleted.
+func TestGarbageCollection(t *testing.T) {
+	t.Parallel()
+	testutil.OwnerDeletionCollectsDependents(t.Context(), t, sharedManager, "kcm-gc", metav1.DeletePropagationBackground)
+}
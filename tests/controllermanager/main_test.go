@@ -0,0 +1,29 @@
+//go:build integration
+
+package k8senv_controllermanager_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRunWithHook(m, &sharedManager, "k8senv-controllermanager-test-*",
+		func(string) ([]k8senv.ManagerOption, error) {
+			// kube-controller-manager is optional for the rest of the
+			// suite; there is nothing to test without it.
+			if _, err := exec.LookPath(k8senv.DefaultControllerManagerBinary); err != nil {
+				fmt.Fprintf(os.Stderr, "skipping controller manager tests: %v\n", err)
+				os.Exit(0)
+			}
+			return []k8senv.ManagerOption{k8senv.WithControllerManager(k8senv.DefaultControllerManagerBinary)}, nil
+		},
+	)
+}
@@ -0,0 +1,84 @@
+//go:build integration
+
+package k8senv_encryption_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// encryptedPrefix starts every value stored by the aescbc provider with
+// k8senv's single key.
+const encryptedPrefix = "k8s:enc:aescbc:v1:key1:"
+
+// secretValue is written to a Secret and a ConfigMap; it must not appear in
+// the stored Secret.
+const secretValue = "k8senv-plaintext-canary"
+
+// recordFor returns the value stored under key, failing the test if it is
+// missing.
+func recordFor(t *testing.T, records []k8senv.StorageRecord, key string) []byte {
+	t.Helper()
+	for _, r := range records {
+		if r.Key == key {
+			return r.Value
+		}
+	}
+	t.Fatalf("no storage record for %s", key)
+	return nil
+}
+
+// TestSecretsEncryptedAtRest verifies that Secrets are stored encrypted with
+// the aescbc provider while other resources stay plain, and that the API
+// still returns the Secret decrypted.
+func TestSecretsEncryptedAtRest(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("enc")
+	testutil.CreateNamespace(ctx, t, client, ns)
+
+	data := map[string]string{"value": secretValue}
+	if _, err := client.CoreV1().Secrets(ns).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret"},
+		StringData: data,
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create secret: %v", err)
+	}
+	if _, err := client.CoreV1().ConfigMaps(ns).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmap"},
+		Data:       data,
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create configmap: %v", err)
+	}
+
+	records, err := inst.RawStorage(ctx)
+	if err != nil {
+		t.Fatalf("RawStorage() error: %v", err)
+	}
+	secret := recordFor(t, records, "/registry/secrets/"+ns+"/secret")
+	if !bytes.HasPrefix(secret, []byte(encryptedPrefix)) {
+		t.Errorf("stored secret starts with %q, want %q", secret[:min(len(secret), len(encryptedPrefix))], encryptedPrefix)
+	}
+	if bytes.Contains(secret, []byte(secretValue)) {
+		t.Error("stored secret contains the plaintext value")
+	}
+	if cm := recordFor(t, records, "/registry/configmaps/"+ns+"/configmap"); !bytes.Contains(cm, []byte(secretValue)) {
+		t.Error("stored configmap does not contain the plaintext value; only secrets should be encrypted")
+	}
+
+	got, err := client.CoreV1().Secrets(ns).Get(ctx, "secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get secret: %v", err)
+	}
+	if string(got.Data["value"]) != secretValue {
+		t.Errorf("secret value = %q, want %q", got.Data["value"], secretValue)
+	}
+}
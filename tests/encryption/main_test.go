@@ -0,0 +1,18 @@
+//go:build integration
+
+package k8senv_encryption_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRun(m, &sharedManager, "k8senv-encryption-test-*",
+		k8senv.WithEncryptionConfig(k8senv.EncryptionAESCBC),
+	)
+}
@@ -0,0 +1,41 @@
+//go:build integration
+
+package k8senv_inprocesscontrollers_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNamespaceDeletionCompletes verifies that the in-process namespace
+// controller empties and finalizes Terminating namespaces.
+func TestNamespaceDeletionCompletes(t *testing.T) {
+	t.Parallel()
+	testutil.NamespaceDeletionCompletes(t.Context(), t, sharedManager, "ipc-ns")
+}
+
+// TestNamespaceDeletionAcrossLeases verifies that the controllers are
+// restarted for every lease, so namespaces are finalized after an instance
+// has been released and acquired again.
+func TestNamespaceDeletionAcrossLeases(t *testing.T) {
+	t.Parallel()
+	for range 3 {
+		testutil.NamespaceDeletionCompletes(t.Context(), t, sharedManager, "ipc-lease")
+	}
+}
+
+// TestBackgroundPropagation verifies that the garbage collector deletes a
+// dependent after its owner is deleted with background propagation.
+func TestBackgroundPropagation(t *testing.T) {
+	t.Parallel()
+	testutil.OwnerDeletionCollectsDependents(t.Context(), t, sharedManager, "ipc-bg", metav1.DeletePropagationBackground)
+}
+
+// TestForegroundPropagation verifies that the garbage collector deletes the
+// dependent and then removes the owner's foregroundDeletion finalizer.
+func TestForegroundPropagation(t *testing.T) {
+	t.Parallel()
+	testutil.OwnerDeletionCollectsDependents(t.Context(), t, sharedManager, "ipc-fg", metav1.DeletePropagationForeground)
+}
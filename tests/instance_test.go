@@ -5,6 +5,7 @@ package k8senv_test
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"slices"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // TestBasicUsage shows a simple example of using k8senv.
@@ -274,3 +276,114 @@ func TestMultipleInstancesWithAPIOnly(t *testing.T) {
 		}
 	}
 }
+
+// TestKubeconfig verifies that the exported kubeconfig connects to the
+// instance, that the merged kubeconfig has a context for it, and that both
+// accessors fail after Release.
+func TestKubeconfig(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	inst, err := sharedManager.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	data, err := inst.Kubeconfig()
+	if err != nil {
+		t.Fatalf("Kubeconfig() error: %v", err)
+	}
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		t.Fatalf("parse kubeconfig: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err != nil {
+		t.Fatalf("list namespaces with exported kubeconfig: %v", err)
+	}
+
+	merged := filepath.Join(t.TempDir(), "merged.yaml")
+	if err := sharedManager.WriteMergedKubeconfig(merged); err != nil {
+		t.Fatalf("WriteMergedKubeconfig() error: %v", err)
+	}
+	mergedCfg, err := clientcmd.LoadFromFile(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mergedCfg.Contexts[inst.ID()]; !ok {
+		t.Errorf("merged kubeconfig has no context %q", inst.ID())
+	}
+
+	if err := inst.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := inst.Kubeconfig(); !errors.Is(err, k8senv.ErrInstanceReleased) {
+		t.Errorf("Kubeconfig() after Release error = %v, want ErrInstanceReleased", err)
+	}
+	if _, err := inst.KubeconfigPath(); !errors.Is(err, k8senv.ErrInstanceReleased) {
+		t.Errorf("KubeconfigPath() after Release error = %v, want ErrInstanceReleased", err)
+	}
+}
+
+// TestAcquireForTest verifies that AcquireForTest returns a usable instance
+// and that an explicit Release before the registered cleanup is allowed.
+func TestAcquireForTest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	inst := sharedManager.AcquireForTest(t)
+	cfg, err := inst.Config()
+	if err != nil {
+		t.Fatalf("Config() error: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err != nil {
+		t.Fatalf("list namespaces: %v", err)
+	}
+	if err := inst.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+}
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	inst, err := sharedManager.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := inst.Logs(); err != nil {
+		t.Fatalf("Logs() error: %v", err)
+	}
+	if err := inst.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := inst.Logs(); !errors.Is(err, k8senv.ErrInstanceReleased) {
+		t.Errorf("Logs() after Release error = %v, want ErrInstanceReleased", err)
+	}
+}
+
+// TestServerVersion verifies that the version kube-apiserver serves matches
+// the version detected from the binary during Initialize.
+func TestServerVersion(t *testing.T) {
+	t.Parallel()
+
+	versions, err := sharedManager.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error: %v", err)
+	}
+	inst := sharedManager.AcquireForTest(t)
+	info, err := inst.ServerVersion(t.Context())
+	if err != nil {
+		t.Fatalf("ServerVersion() error: %v", err)
+	}
+	if info.GitVersion != versions.KubeAPIServer {
+		t.Errorf("ServerVersion().GitVersion = %q, want %q", info.GitVersion, versions.KubeAPIServer)
+	}
+}
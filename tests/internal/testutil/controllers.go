@@ -0,0 +1,117 @@
+//go:build integration
+
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/k8senv"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// controllerTimeout bounds how long the helpers below wait for a controller
+// to act. Controllers react within a second or two; the margin covers slow
+// CI hosts.
+const controllerTimeout = 30 * time.Second
+
+// WaitForDeletion polls until get reports NotFound, failing the test after
+// controllerTimeout. what names the object in the failure message.
+func WaitForDeletion(ctx context.Context, t *testing.T, what string, get func(context.Context) error) {
+	t.Helper()
+
+	err := wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, controllerTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			err := get(ctx)
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+	if err != nil {
+		t.Fatalf("%s was not deleted: %v", what, err)
+	}
+}
+
+// createConfigMap creates a ConfigMap, optionally owned by owner, and fails
+// the test on error.
+func createConfigMap(ctx context.Context, t *testing.T, client kubernetes.Interface, ns, name string, owner *v1.ConfigMap) *v1.ConfigMap {
+	t.Helper()
+
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+	if owner != nil {
+		cm.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       owner.Name,
+			UID:        owner.UID,
+		}}
+	}
+	created, err := client.CoreV1().ConfigMaps(ns).Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create configmap %s/%s: %v", ns, name, err)
+	}
+	return created
+}
+
+// NamespaceDeletionCompletes verifies that deleting a namespace with
+// content removes the content and then the namespace, instead of leaving it
+// Terminating. The label is used for unique name prefixes.
+func NamespaceDeletionCompletes(ctx context.Context, t *testing.T, mgr k8senv.Manager, label string) {
+	t.Helper()
+
+	_, client, release := AcquireWithGuardedRelease(ctx, t, mgr)
+	defer release()
+
+	ns := UniqueName(label)
+	CreateNamespace(ctx, t, client, ns)
+	createConfigMap(ctx, t, client, ns, "content", nil)
+
+	if err := client.CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete namespace %s: %v", ns, err)
+	}
+	WaitForDeletion(ctx, t, "namespace "+ns, func(ctx context.Context) error {
+		_, err := client.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+		return err
+	})
+}
+
+// OwnerDeletionCollectsDependents verifies that deleting an owner with the
+// given propagation policy garbage-collects its dependent, and that with
+// foreground propagation the owner is removed only after the dependent. The
+// label is used for unique name prefixes.
+func OwnerDeletionCollectsDependents(
+	ctx context.Context,
+	t *testing.T,
+	mgr k8senv.Manager,
+	label string,
+	propagation metav1.DeletionPropagation,
+) {
+	t.Helper()
+
+	_, client, release := AcquireWithGuardedRelease(ctx, t, mgr)
+	defer release()
+
+	ns := UniqueName(label)
+	CreateNamespace(ctx, t, client, ns)
+	owner := createConfigMap(ctx, t, client, ns, "owner", nil)
+	createConfigMap(ctx, t, client, ns, "dependent", owner)
+
+	cms := client.CoreV1().ConfigMaps(ns)
+	if err := cms.Delete(ctx, owner.Name, metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+		t.Fatalf("delete owner: %v", err)
+	}
+	WaitForDeletion(ctx, t, "dependent configmap", func(ctx context.Context) error {
+		_, err := cms.Get(ctx, "dependent", metav1.GetOptions{})
+		return err
+	})
+	WaitForDeletion(ctx, t, "owner configmap", func(ctx context.Context) error {
+		_, err := cms.Get(ctx, owner.Name, metav1.GetOptions{})
+		return err
+	})
+}
@@ -0,0 +1,81 @@
+//go:build integration
+
+package k8senv_jwt_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TestJWTClaimMappings verifies that kube-apiserver authenticates a token
+// from ConfigForJWTClaims with the configured username and groups mappings.
+func TestJWTClaimMappings(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, _, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+
+	cfg, err := inst.ConfigForJWTClaims(map[string]any{
+		"sub":            "alice",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"roles":          []string{"admin", "dev"},
+	})
+	if err != nil {
+		t.Fatalf("ConfigForJWTClaims() error: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	review, err := client.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create SelfSubjectReview: %v", err)
+	}
+	user := review.Status.UserInfo
+	if user.Username != "oidc:alice@example.com" {
+		t.Errorf("username = %q, want %q", user.Username, "oidc:alice@example.com")
+	}
+	for _, group := range []string{"role:admin", "role:dev", "system:authenticated"} {
+		if !slices.Contains(user.Groups, group) {
+			t.Errorf("groups %v do not contain %q", user.Groups, group)
+		}
+	}
+}
+
+// TestJWTClaimValidationRule verifies that a token failing a claim
+// validation rule is rejected as unauthorized.
+func TestJWTClaimValidationRule(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, _, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+
+	cfg, err := inst.ConfigForJWTClaims(map[string]any{
+		"sub":            "mallory",
+		"email":          "mallory@example.com",
+		"email_verified": false,
+		"roles":          []string{},
+	})
+	if err != nil {
+		t.Fatalf("ConfigForJWTClaims() error: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	_, err = client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if !apierrors.IsUnauthorized(err) {
+		t.Errorf("list namespaces with unverified email: error = %v, want Unauthorized", err)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build integration
+
+package k8senv_jwt_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRun(m, &sharedManager, "k8senv-jwt-test-*",
+		k8senv.WithJWTAuthenticator(k8senv.JWTAuthenticator{
+			ClaimMappings: k8senv.JWTClaimMappings{
+				Username: k8senv.JWTPrefixedClaimOrExpression{Claim: "email", Prefix: "oidc:"},
+				Groups:   k8senv.JWTPrefixedClaimOrExpression{Expression: "claims.roles.map(r, 'role:' + r)"},
+			},
+			ClaimValidationRules: []k8senv.JWTClaimValidationRule{
+				{Expression: "claims.email_verified == true", Message: "email not verified"},
+			},
+		}),
+	)
+}
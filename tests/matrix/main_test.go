@@ -0,0 +1,52 @@
+//go:build integration
+
+package k8senv_matrix_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var (
+	sharedManager k8senv.Manager
+
+	// apiServerVersion is the version the kube-apiserver in PATH reports,
+	// used as its label in the version matrix.
+	apiServerVersion string
+)
+
+// aliasLabels map the same kube-apiserver binary under further labels, so the
+// matrix has several pools without needing several binaries. They sort after
+// any released Kubernetes version.
+var aliasLabels = []string{"1.98", "1.99"}
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRunWithHook(m, &sharedManager, "k8senv-matrix-test-*",
+		func(string) ([]k8senv.ManagerOption, error) {
+			path, err := exec.LookPath(k8senv.DefaultKubeAPIServerBinary)
+			if err != nil {
+				return nil, err
+			}
+			out, err := exec.Command(path, "--version").Output() //nolint:gosec // G204: path is from LookPath
+			if err != nil {
+				return nil, fmt.Errorf("kube-apiserver --version: %w", err)
+			}
+			fields := strings.Fields(string(out))
+			if len(fields) == 0 {
+				return nil, errors.New("kube-apiserver --version: empty output")
+			}
+			apiServerVersion = fields[len(fields)-1]
+			versions := map[string]string{apiServerVersion: path}
+			for _, label := range aliasLabels {
+				versions[label] = path
+			}
+			return []k8senv.ManagerOption{k8senv.WithKubeAPIServerVersions(versions)}, nil
+		},
+	)
+}
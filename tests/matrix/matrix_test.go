@@ -0,0 +1,92 @@
+//go:build integration
+
+package k8senv_matrix_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+)
+
+// matrixLabels returns the labels of the version matrix in the order
+// KubeAPIServerVersions and ForEachVersion use.
+func matrixLabels() []string {
+	return append([]string{apiServerVersion}, aliasLabels...)
+}
+
+// poolLabel returns the version label of the pool inst belongs to. Each
+// version's pool lives under <base data dir>/<label>, and the kubeconfig is
+// in the instance data directory below it.
+func poolLabel(t *testing.T, inst k8senv.Instance) string {
+	t.Helper()
+	path, err := inst.KubeconfigPath()
+	if err != nil {
+		t.Fatalf("KubeconfigPath() error: %v", err)
+	}
+	return filepath.Base(filepath.Dir(filepath.Dir(path)))
+}
+
+func TestKubeAPIServerVersions(t *testing.T) {
+	t.Parallel()
+
+	if got, want := sharedManager.KubeAPIServerVersions(), matrixLabels(); !slices.Equal(got, want) {
+		t.Errorf("KubeAPIServerVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestForEachVersion(t *testing.T) {
+	t.Parallel()
+
+	var ran, pools, ids []string
+	sharedManager.ForEachVersion(t, func(t *testing.T, inst k8senv.Instance) {
+		ran = append(ran, t.Name())
+		pools = append(pools, poolLabel(t, inst))
+		ids = append(ids, inst.ID())
+		info, err := inst.ServerVersion(t.Context())
+		if err != nil {
+			t.Fatalf("ServerVersion() error: %v", err)
+		}
+		if info.GitVersion != apiServerVersion {
+			t.Errorf("ServerVersion().GitVersion = %q, want %q", info.GitVersion, apiServerVersion)
+		}
+	})
+
+	labels := matrixLabels()
+	want := make([]string, 0, len(labels))
+	for _, label := range labels {
+		want = append(want, t.Name()+"/"+label)
+	}
+	if !slices.Equal(ran, want) {
+		t.Errorf("ForEachVersion ran %v, want %v", ran, want)
+	}
+	if !slices.Equal(pools, labels) {
+		t.Errorf("ForEachVersion instances came from pools %v, want %v", pools, labels)
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(ids)))) != len(ids) {
+		t.Errorf("ForEachVersion reused an instance across versions: %v", ids)
+	}
+}
+
+func TestAcquireVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, label := range matrixLabels() {
+		inst, err := sharedManager.AcquireVersion(t.Context(), label)
+		if err != nil {
+			t.Fatalf("AcquireVersion(%q) error: %v", label, err)
+		}
+		if pool := poolLabel(t, inst); pool != label {
+			t.Errorf("AcquireVersion(%q) instance from pool %q", label, pool)
+		}
+		if err := inst.Release(); err != nil {
+			t.Errorf("release: %v", err)
+		}
+	}
+
+	if _, err := sharedManager.AcquireVersion(t.Context(), "0.1"); !errors.Is(err, k8senv.ErrUnknownVersion) {
+		t.Errorf("AcquireVersion(\"0.1\") error = %v, want ErrUnknownVersion", err)
+	}
+}
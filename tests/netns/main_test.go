@@ -0,0 +1,32 @@
+//go:build integration && linux
+
+package k8senv_netns_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/internal/netns"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+)
+
+var sharedManager k8senv.Manager
+
+func TestMain(m *testing.M) {
+	testutil.SetupAndRunWithHook(m, &sharedManager, "k8senv-netns-test-*",
+		func(string) ([]k8senv.ManagerOption, error) {
+			// Hosts such as Ubuntu 24.04 restrict unprivileged user
+			// namespaces; there is nothing to test on them.
+			if err := netns.Probe(context.Background()); err != nil {
+				fmt.Fprintf(os.Stderr, "skipping network namespace tests: %v\n", err)
+				os.Exit(0)
+			}
+			return []k8senv.ManagerOption{k8senv.WithNetworkNamespace()}, nil
+		},
+		// Two instances must run at once to share the namespace port.
+		k8senv.WithPoolSize(2),
+	)
+}
@@ -0,0 +1,61 @@
+//go:build integration && linux
+
+package k8senv_netns_test
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespacePort is the secure port kube-apiserver binds inside its network
+// namespace.
+const namespacePort = 6443
+
+func TestInstancesShareNamespacePort(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	seen := make(map[string]bool)
+	for range 2 {
+		inst, client := testutil.AcquireWithClient(ctx, t, sharedManager)
+		t.Cleanup(func() {
+			if err := inst.Release(); err != nil {
+				t.Errorf("release: %v", err)
+			}
+		})
+		cfg, err := inst.Config()
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(cfg.Host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Port() == strconv.Itoa(namespacePort) {
+			t.Errorf("client connects to %s, want the forwarder's port", cfg.Host)
+		}
+		if seen[u.Port()] {
+			t.Errorf("two instances share forwarder port %s", u.Port())
+		}
+		seen[u.Port()] = true
+
+		// The endpoint reconciler advertises the port kube-apiserver binds,
+		// which is the same fixed port in every namespace.
+		slice, err := client.DiscoveryV1().EndpointSlices("default").Get(ctx, "kubernetes", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get kubernetes EndpointSlice: %v", err)
+		}
+		if len(slice.Ports) == 0 || slice.Ports[0].Port == nil {
+			t.Fatalf("kubernetes EndpointSlice has no ports: %+v", slice.Ports)
+		}
+		if got := *slice.Ports[0].Port; got != namespacePort {
+			t.Errorf("kubernetes EndpointSlice port = %d, want %d", got, namespacePort)
+		}
+
+		testutil.CreateNamespace(ctx, t, client, testutil.UniqueName("netns"))
+	}
+}
@@ -0,0 +1,86 @@
+//go:build integration
+
+package k8senv_nodesim_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// TestPodBecomesReady verifies that a Pod is scheduled onto the fake node
+// and reported Running and Ready.
+func TestPodBecomesReady(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	_, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+
+	ns := testutil.UniqueName("nodesim-pod")
+	testutil.CreateNamespace(ctx, t, client, ns)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: ns},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "app:latest"}}},
+	}
+	if _, err := client.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+
+	var got *v1.Pod
+	err := wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, 30*time.Second, true,
+		func(ctx context.Context) (bool, error) {
+			var err error
+			got, err = client.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return got.Status.Phase == v1.PodRunning && isReady(got), nil
+		})
+	if err != nil {
+		t.Fatalf("pod did not become Running and Ready: %v (status %+v)", err, got.Status)
+	}
+	if got.Spec.NodeName != k8senv.DefaultFakeNodeName {
+		t.Errorf("pod node = %q, want %q", got.Spec.NodeName, k8senv.DefaultFakeNodeName)
+	}
+}
+
+// TestNodeLeaseRemovedOnRelease verifies that the node heartbeat Lease,
+// which lives in the preserved kube-node-lease namespace, does not outlive
+// the lease of the instance. It is not parallel so that no other test
+// acquires the instance, restarting the simulator, before the check.
+func TestNodeLeaseRemovedOnRelease(t *testing.T) {
+	ctx := t.Context()
+
+	_, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	leases := client.CoordinationV1().Leases(v1.NamespaceNodeLease)
+	if _, err := leases.Get(ctx, k8senv.DefaultFakeNodeName, metav1.GetOptions{}); err != nil {
+		release()
+		t.Fatalf("get node lease while acquired: %v", err)
+	}
+
+	release()
+
+	// The client still reaches kube-apiserver, which keeps running while
+	// the instance sits in the pool.
+	if _, err := leases.Get(ctx, k8senv.DefaultFakeNodeName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("get node lease after release: error = %v, want NotFound", err)
+	}
+}
+
+// isReady reports whether the Pod's Ready condition is true.
+func isReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+//go:build integration
+
+package k8senv_serviceaccount_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/giantswarm/k8senv"
+	"github.com/giantswarm/k8senv/tests/internal/testutil"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TestServiceAccountConfigToken verifies that the token from
+// ServiceAccountConfig is accepted by kube-apiserver as the ServiceAccount.
+func TestServiceAccountConfigToken(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, _, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("sa")
+
+	cfg, err := inst.ServiceAccountConfig(ctx, ns, "controller")
+	if err != nil {
+		t.Fatalf("ServiceAccountConfig() error: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	review, err := client.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create SelfSubjectReview: %v", err)
+	}
+	if want := "system:serviceaccount:" + ns + ":controller"; review.Status.UserInfo.Username != want {
+		t.Errorf("username = %q, want %q", review.Status.UserInfo.Username, want)
+	}
+	if _, err := client.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		t.Errorf("list configmaps as ServiceAccount: %v", err)
+	}
+
+	if _, err := inst.ServiceAccountConfig(ctx, "default", "controller"); !errors.Is(err, k8senv.ErrSystemNamespace) {
+		t.Errorf("ServiceAccountConfig(\"default\") error = %v, want ErrSystemNamespace", err)
+	}
+}
+
+// TestServiceAccountAdmission verifies that, with WithServiceAccounts, a Pod
+// is admitted with its ServiceAccount's projected token volume.
+func TestServiceAccountAdmission(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	inst, client, release := testutil.AcquireWithGuardedRelease(ctx, t, sharedManager)
+	defer release()
+	ns := testutil.UniqueName("sa")
+	if _, err := inst.ServiceAccountConfig(ctx, ns, "app"); err != nil {
+		t.Fatalf("ServiceAccountConfig() error: %v", err)
+	}
+
+	pod, err := client.CoreV1().Pods(ns).Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "app",
+			Containers:         []corev1.Container{{Name: "app", Image: "registry.k8s.io/pause:3.10"}},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+	for _, v := range pod.Spec.Volumes {
+		if v.Projected != nil {
+			for _, src := range v.Projected.Sources {
+				if src.ServiceAccountToken != nil {
+					return
+				}
+			}
+		}
+	}
+	t.Errorf("pod volumes %+v have no projected service account token", pod.Spec.Volumes)
+}
@@ -0,0 +1,9 @@
+package k8senv
+
+import "github.com/giantswarm/k8senv/internal/core"
+
+// Versions holds the kube-apiserver and kine versions a Manager runs, as
+// returned by Manager.Versions. Both are detected once during Initialize
+// from the binaries' --version output; Kine is empty if kine did not report
+// one.
+type Versions = core.Versions